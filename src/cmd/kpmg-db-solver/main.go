@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
 	"github.com/jaypaulb/kpmg-db-solver/internal/commands"
 	"github.com/jaypaulb/kpmg-db-solver/internal/config"
 	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/report"
 )
 
 var (
@@ -17,12 +24,47 @@ var (
 )
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(interruptContext()); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// interruptContext returns a context that is cancelled on the first Ctrl+C (or SIGTERM), so
+// long-running commands can stop cleanly and save their progress. A second Ctrl+C falls back
+// to the default behaviour and terminates immediately.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		fmt.Println("\n⏹️  Interrupt received - finishing in-flight work and saving progress (press Ctrl+C again to quit immediately)")
+		cancel()
+	}()
+
+	return ctx
+}
+
+// exitOnError prints a failed command's error and exits; interruptions exit with status 130
+func exitOnError(action string, err error) {
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("⏹️  %s interrupted: %v\n", action, err)
+		fmt.Println("💾 Partial results were saved to the output folder")
+		os.Exit(130)
+	}
+
+	fmt.Printf("❌ %s failed: %v\n", action, err)
+	os.Exit(1)
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "kpmg-db-solver",
 	Short: "KPMG DB Solver - Canvus Asset Recovery Tool",
@@ -41,10 +83,60 @@ Key features:
 	Version: fmt.Sprintf("%s (built %s with %s)", version, buildTime, goVersion),
 }
 
+var (
+	discoverOptions    commands.DiscoverOptions
+	restoreOptions     commands.RestoreOptions
+	reportOptions      commands.ReportOptions
+	harvestOptions     commands.HarvestOptions
+	recoverOptions     commands.RecoverOptions
+	reconstructOptions commands.ReconstructOptions
+)
+
 func init() {
+	for _, cmd := range []*cobra.Command{discoverCmd, runCmd} {
+		cmd.Flags().BoolVar(&discoverOptions.Resume, "resume", false, "continue an interrupted discovery from the journal in the output folder (failed canvases are retried first)")
+		cmd.Flags().BoolVar(&discoverOptions.Full, "full", false, "read every canvas instead of serving unchanged canvases from the asset cache")
+		cmd.Flags().StringVar(&discoverOptions.BackupSince, "backup-since", "", "only search backups taken on or after this date (YYYY-MM-DD)")
+		cmd.Flags().StringVar(&discoverOptions.BackupUntil, "backup-until", "", "only search backups taken on or before this date (YYYY-MM-DD)")
+		cmd.Flags().BoolVar(&discoverOptions.VerifyAssets, "verify-assets", false, "hash every existing asset with the detected algorithm to find corrupt files")
+		cmd.Flags().BoolVar(&discoverOptions.NoVerify, "no-verify", false, "recommend backup candidates without checking size, file signature and content hash")
+		cmd.Flags().StringSliceVar(&discoverOptions.BackupVersions, "backup-version", nil, "only search backups from these Canvus versions, e.g. 3.3 or 3.3.0 (repeatable)")
+	}
+
+	restoreCmd.Flags().StringVar(&restoreOptions.From, "from", "", "discovery manifest from a previous run (default: <output_folder>/"+manifest.Filename+")")
+	restoreCmd.Flags().BoolVar(&restoreOptions.DryRun, "dry-run", false, "show the restore plan without copying any files")
+	restoreCmd.Flags().BoolVarP(&restoreOptions.Yes, "yes", "y", false, "do not ask for confirmation before copying")
+	restoreCmd.Flags().StringVar(&restoreOptions.Undo, "undo", "", "delete the files a restore journal records as restored, if still unchanged")
+	restoreCmd.Flags().StringVar(&restoreOptions.Run, "run", "", "with --undo, only undo this restore run (e.g. 20250907-101500)")
+	restoreCmd.Flags().BoolVar(&restoreOptions.EmitScript, "emit-script", false, "write the plan as a PowerShell script and robocopy jobs with a hash manifest instead of copying")
+	restoreCmd.Flags().BoolVar(&restoreOptions.AllowIncomplete, "allow-incomplete", false, "restore from the partial results of an interrupted run (e.g. --from "+manifest.CheckpointFilename+")")
+
+	reportCmd.Flags().StringVar(&reportOptions.From, "from", "", "discovery manifest from a previous run (default: <output_folder>/"+manifest.Filename+")")
+	reportCmd.Flags().StringSliceVar(&reportOptions.Report.Canvases, "canvas", nil, "only include these canvases (name or ID, repeatable)")
+	reportCmd.Flags().StringSliceVar(&reportOptions.Report.WidgetTypes, "widget-type", nil, "only include these widget types, e.g. Image,Pdf,Video,CanvasBackground")
+	reportCmd.Flags().StringVar(&reportOptions.Report.BackupStatus, "backup-status", "", "only include assets whose backup status is found or not-found")
+	reportCmd.Flags().StringSliceVar(&reportOptions.Report.Classes, "class", nil, "only include these classes: missing-everywhere, missing-on-disk-served, on-disk-server-error, probe-failed, not-validated, on-disk-served")
+	reportCmd.Flags().StringVar(&reportOptions.Report.SortBy, "sort", report.SortByCanvas, "sort order: canvas, hash, type or widget")
+
+	harvestCmd.Flags().StringVar(&harvestOptions.From, "from", "", "discovery manifest from a previous run (default: <output_folder>/"+manifest.Filename+")")
+	harvestCmd.Flags().StringSliceVar(&harvestOptions.Directories, "dir", nil, "folder to search in addition to harvest.directories (repeatable)")
+
+	recoverCmd.Flags().StringVar(&recoverOptions.From, "from", "", "discovery manifest from a previous run (default: <output_folder>/"+manifest.Filename+")")
+	recoverCmd.Flags().StringVar(&recoverOptions.Server, "server", "", "secondary Canvus Server URL (default: replica.url)")
+	recoverCmd.Flags().StringVar(&recoverOptions.Username, "username", "", "secondary Canvus Server username (default: replica.username)")
+	recoverCmd.Flags().BoolVar(&recoverOptions.InsecureTLS, "insecure-tls", false, "skip TLS certificate verification on the secondary server")
+	recoverCmd.Flags().BoolVar(&recoverOptions.DryRun, "dry-run", false, "show which missing assets the server holds without downloading them")
+	recoverCmd.Flags().BoolVarP(&recoverOptions.Yes, "yes", "y", false, "do not ask for confirmation before downloading")
+
+	reconstructCmd.Flags().StringVar(&reconstructOptions.From, "from", "", "discovery manifest from a previous run (default: <output_folder>/"+manifest.Filename+")")
+	reconstructCmd.Flags().StringVar(&reconstructOptions.ReviewFolder, "review-dir", "", "folder for the rebuilt images (default: <output_folder>/"+canvus.ReviewFolderName+")")
+
 	rootCmd.AddCommand(discoverCmd)
 	rootCmd.AddCommand(restoreCmd)
 	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(harvestCmd)
+	rootCmd.AddCommand(recoverCmd)
+	rootCmd.AddCommand(reconstructCmd)
 	rootCmd.AddCommand(runCmd)
 }
 
@@ -53,40 +145,109 @@ var discoverCmd = &cobra.Command{
 	Short: "Discover missing assets from Canvus Server",
 	Long:  `Scan the Canvus Server and identify missing asset files by comparing API data with filesystem contents.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runDiscoverCommand()
+		runDiscoverCommand(cmd.Context())
 	},
 }
 
 var restoreCmd = &cobra.Command{
 	Use:   "restore",
 	Short: "Restore missing assets from backup locations",
-	Long:  `Search backup locations for missing assets and restore them to the active assets folder.`,
+	Long: `Restore missing assets found by a previous discovery to the active assets folder.
+
+The restore reads the discovery manifest saved by discover or run, builds a
+plan (hash -> chosen backup file -> target path) and copies each file. Use
+--dry-run to review the plan without writing anything.
+
+Every restored file is recorded with its checksum in the restore journal
+(<output_folder>/restore_journal.jsonl). restore --undo <journal> deletes
+those files again, but only the ones that are still unchanged; anything
+modified since is left in place and reported.
+
+Without write access to the assets folder, use --emit-script to export the
+plan to <output_folder>/restore_script for an administrator: an idempotent
+PowerShell script, robocopy job files and the hash manifest both are checked
+against.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runRestoreCommand()
+		runRestoreCommand(cmd.Context())
 	},
 }
 
 var reportCmd = &cobra.Command{
 	Use:   "report",
 	Short: "Generate detailed reports of missing assets",
-	Long:  `Generate comprehensive reports showing missing assets grouped by canvas with detailed information.`,
+	Long: `Regenerate the missing asset reports from a saved discovery manifest.
+
+This works offline: no connection to the Canvus Server is made. Use the filter
+and sort flags to produce re-cuts of the same run, e.g.
+
+  kpmg-db-solver report --widget-type Video --backup-status not-found --sort hash`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runReportCommand()
 	},
 }
 
+var harvestCmd = &cobra.Command{
+	Use:   "harvest",
+	Short: "Find missing assets by content in other folders",
+	Long: `Search folders such as user document shares and client caches for the original
+uploads of assets that no backup could supply.
+
+Every file is hashed with the asset hash algorithm detected during discovery and
+matched against the missing hashes, whatever its name. Matches are added to the
+discovery manifest as restore candidates, so restore copies them back as
+{hash}.{ext}. No connection to the Canvus Server is made.
+
+  kpmg-db-solver harvest --dir "\\fileserver\users" --dir "D:\Uploads"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runHarvestCommand(cmd.Context())
+	},
+}
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "Download missing assets from a secondary Canvus Server",
+	Long: `Recover assets that no backup could supply from a secondary Canvus Server,
+such as a staging server restored from an older snapshot.
+
+Each missing hash is located on the secondary server through a canvas that uses
+it (same canvas ID, or same canvas name) and streamed into the assets folder as
+{hash}.{ext}. A provenance record for every recovered file is appended to
+recovery_provenance.jsonl in the output folder. The password is taken from
+replica.password or prompted for.
+
+  kpmg-db-solver recover --server https://canvus-staging:443 --username admin@example.com`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runRecoverCommand(cmd.Context())
+	},
+}
+
+var reconstructCmd = &cobra.Command{
+	Use:   "reconstruct",
+	Short: "Rebuild lost images and PDFs from server-side mipmaps (lossy)",
+	Long: `Degraded recovery for images and PDFs whose original file is gone everywhere
+but whose mipmaps the Canvus Server still holds.
+
+The highest-resolution mipmap level of the image, or of every page of a PDF, is
+saved as WebP into a separate review folder. The assets folder is never touched.
+The results are LOSSY and are marked as such in the reports; review them before
+re-uploading.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runReconstructCommand(cmd.Context())
+	},
+}
+
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run complete workflow (discover, search, restore, report)",
 	Long:  `Execute the complete KPMG DB Solver workflow: discover missing assets, search backups, restore found assets, and generate reports.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runRunCommand()
+		runRunCommand(cmd.Context())
 	},
 }
 
 // Command implementations
 
-func runDiscoverCommand() {
+func runDiscoverCommand(ctx context.Context) {
 	fmt.Println("🔍 Asset Discovery")
 	fmt.Println("==================")
 	fmt.Println()
@@ -99,64 +260,104 @@ func runDiscoverCommand() {
 	}
 
 	// Create and execute discover command
-	discoverCmd := commands.NewDiscoverCommand(cfg)
-	err = discoverCmd.Execute()
+	discoverCmd := commands.NewDiscoverCommand(cfg, discoverOptions)
+	exitOnError("Discovery", discoverCmd.Execute(ctx))
+}
+
+func runRestoreCommand(ctx context.Context) {
+	fmt.Println("🔄 Asset Restoration")
+	fmt.Println("====================")
+	fmt.Println()
+
+	// Undoing a restore never talks to the server
+	load := loadOrPromptConfig
+	if restoreOptions.Undo != "" {
+		load = loadConfigOffline
+	}
+	cfg, err := load()
 	if err != nil {
-		fmt.Printf("❌ Discovery failed: %v\n", err)
+		fmt.Printf("❌ Configuration error: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Create and execute restore command
+	restoreCmd := commands.NewRestoreCommand(cfg, restoreOptions)
+	exitOnError("Restore", restoreCmd.Execute(ctx))
 }
 
-func runRestoreCommand() {
-	fmt.Println("🔄 Asset Restoration")
+func runReportCommand() {
+	fmt.Println("📊 Report Generation")
 	fmt.Println("====================")
 	fmt.Println()
 
-	// Load or prompt for configuration
-	cfg, err := loadOrPromptConfig()
+	// Reports are generated offline, so never prompt for server credentials
+	cfg, err := loadConfigOffline()
 	if err != nil {
 		fmt.Printf("❌ Configuration error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("📁 Assets Folder: %s\n", cfg.Paths.AssetsFolder)
-	fmt.Printf("📁 Backup Root: %s\n", cfg.Paths.BackupRootFolder)
+	// Create and execute report command
+	reportCmd := commands.NewReportCommand(cfg, reportOptions)
+	err = reportCmd.Execute()
+	if err != nil {
+		fmt.Printf("❌ Report generation failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runHarvestCommand(ctx context.Context) {
+	fmt.Println("🌾 Asset Harvest")
+	fmt.Println("================")
 	fmt.Println()
 
-	fmt.Println("🚧 Asset restoration implementation in progress...")
-	fmt.Println("This will:")
-	fmt.Println("  1. Read missing asset list")
-	fmt.Println("  2. Search backup locations (newest first)")
-	fmt.Println("  3. Copy missing assets to active folder")
-	fmt.Println("  4. Verify restoration success")
-	fmt.Println("  5. Generate restoration report")
+	// Harvesting works from the manifest, so never prompt for server credentials
+	cfg, err := loadConfigOffline()
+	if err != nil {
+		fmt.Printf("❌ Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create and execute harvest command
+	harvestCmd := commands.NewHarvestCommand(cfg, harvestOptions)
+	exitOnError("Harvest", harvestCmd.Execute(ctx))
 }
 
-func runReportCommand() {
-	fmt.Println("📊 Report Generation")
-	fmt.Println("====================")
+func runRecoverCommand(ctx context.Context) {
+	fmt.Println("🌐 Asset Recovery from Secondary Server")
+	fmt.Println("=======================================")
 	fmt.Println()
 
-	// Load or prompt for configuration
-	cfg, err := loadOrPromptConfig()
+	// Only the secondary server is contacted, so never prompt for the main server credentials
+	cfg, err := loadConfigOffline()
 	if err != nil {
 		fmt.Printf("❌ Configuration error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("📁 Output Folder: %s\n", cfg.Paths.OutputFolder)
+	// Create and execute recover command
+	recoverCmd := commands.NewRecoverCommand(cfg, recoverOptions)
+	exitOnError("Recovery", recoverCmd.Execute(ctx))
+}
+
+func runReconstructCommand(ctx context.Context) {
+	fmt.Println("🖼️  Degraded Recovery from Mipmaps")
+	fmt.Println("=================================")
 	fmt.Println()
 
-	fmt.Println("🚧 Report generation implementation in progress...")
-	fmt.Println("This will:")
-	fmt.Println("  1. Read asset discovery results")
-	fmt.Println("  2. Generate detailed canvas-grouped report")
-	fmt.Println("  3. Create CSV export for tracking")
-	fmt.Println("  4. Include restoration status")
-	fmt.Println("  5. Output error summaries")
+	// Load or prompt for configuration
+	cfg, err := loadOrPromptConfig()
+	if err != nil {
+		fmt.Printf("❌ Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create and execute reconstruct command
+	reconstructCmd := commands.NewReconstructCommand(cfg, reconstructOptions)
+	exitOnError("Reconstruction", reconstructCmd.Execute(ctx))
 }
 
-func runRunCommand() {
+func runRunCommand(ctx context.Context) {
 	fmt.Println("🚀 Complete Workflow")
 	fmt.Println("===================")
 
@@ -168,12 +369,8 @@ func runRunCommand() {
 	}
 
 	// Create and execute run command
-	runCmd := commands.NewRunCommand(cfg)
-	err = runCmd.Execute(nil, nil)
-	if err != nil {
-		fmt.Printf("❌ Workflow failed: %v\n", err)
-		os.Exit(1)
-	}
+	runCmd := commands.NewRunCommand(cfg, discoverOptions)
+	exitOnError("Workflow", runCmd.Execute(ctx))
 }
 
 func loadOrPromptConfig() (*config.Config, error) {
@@ -196,6 +393,7 @@ func loadOrPromptConfig() (*config.Config, error) {
 
 	// Prompt for configuration
 	prompts := config.NewInteractivePrompts()
+	defer prompts.Close()
 	cfg, err = prompts.PromptForConfig()
 	if err != nil {
 		return nil, err
@@ -214,6 +412,21 @@ func loadOrPromptConfig() (*config.Config, error) {
 	return cfg, nil
 }
 
+// loadConfigOffline loads configuration for commands that do not talk to the Canvus Server.
+// Missing settings fall back to defaults instead of prompting.
+func loadConfigOffline() (*config.Config, error) {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := initLogging(cfg); err != nil {
+		fmt.Printf("⚠️  Warning: Failed to initialize logging: %v\n", err)
+	}
+
+	return cfg, nil
+}
+
 // initLogging initializes the logging system based on configuration
 func initLogging(cfg *config.Config) error {
 	level := logging.ParseLogLevel(cfg.Logging.Level)
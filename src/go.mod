@@ -3,13 +3,13 @@ module github.com/jaypaulb/kpmg-db-solver
 replace canvus-go-api => ./pkg/canvus
 
 require (
+	canvus-go-api v0.0.0-00010101000000-000000000000
 	github.com/spf13/cobra v1.9.1
 	github.com/spf13/viper v1.18.2
 	golang.org/x/term v0.19.0
 )
 
 require (
-	canvus-go-api v0.0.0-00010101000000-000000000000 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
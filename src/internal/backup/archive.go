@@ -0,0 +1,302 @@
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArchiveSeparator separates an archive path from the member inside it, as in backup.zip!assets/ab/hash.jpg
+const ArchiveSeparator = "!"
+
+// archiveExtensions are the compressed backup formats the searcher looks inside (longest suffix first)
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip", ".tar"}
+
+// ArchivePath returns the display path of an archive member
+func ArchivePath(archive, member string) string {
+	return archive + ArchiveSeparator + member
+}
+
+// archiveBaseName strips a supported archive extension from a file name
+func archiveBaseName(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)], true
+		}
+	}
+	return "", false
+}
+
+// archiveAssetPath returns the path of a member relative to the backup's assets folder,
+// e.g. 1757261054_..._mt-canvus_backup/assets/ab/hash.jpg -> ab/hash.jpg (in OS form).
+// Members outside an assets folder are not assets.
+func archiveAssetPath(member string) (string, bool) {
+	parts := strings.Split(strings.Trim(path.Clean(strings.ReplaceAll(member, `\`, "/")), "/"), "/")
+	for i, part := range parts {
+		if part == "assets" && i < len(parts)-1 {
+			return filepath.Join(parts[i+1:]...), true
+		}
+	}
+	return "", false
+}
+
+// archiveMember is one regular file inside an archive
+type archiveMember struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// walkArchive calls fn for every regular file in a zip, tar or tar.gz archive
+func walkArchive(ctx context.Context, archivePath string, fn func(member archiveMember) error) error {
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to open zip archive %s: %w", archivePath, err)
+		}
+		defer reader.Close()
+
+		for _, file := range reader.File {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			if err := fn(archiveMember{Name: file.Name, Size: int64(file.UncompressedSize64), ModTime: file.Modified}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, tarReader, err := openTar(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive %s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(archiveMember{Name: header.Name, Size: header.Size, ModTime: header.ModTime}); err != nil {
+			return err
+		}
+	}
+}
+
+// openTar opens a tar or gzip-compressed tar archive; closing the returned closer closes the archive
+func openTar(archivePath string) (io.Closer, *tar.Reader, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+
+	lower := strings.ToLower(archivePath)
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to decompress archive %s: %w", archivePath, err)
+		}
+		return closers{gz, file}, tar.NewReader(gz), nil
+	}
+
+	return file, tar.NewReader(file), nil
+}
+
+// closers closes several closers in order, returning the first error
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var first error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// readCloser pairs a reader with the closer that releases it
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// openArchiveMember opens a single member of an archive for reading without extracting the rest.
+// Zip members are read directly; tar archives are streamed up to the member.
+func openArchiveMember(archivePath, member string) (io.ReadCloser, error) {
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zip archive %s: %w", archivePath, err)
+		}
+		for _, file := range reader.File {
+			if file.Name != member {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				reader.Close()
+				return nil, fmt.Errorf("failed to open %s: %w", ArchivePath(archivePath, member), err)
+			}
+			return readCloser{rc, closers{rc, reader}}, nil
+		}
+		reader.Close()
+		return nil, fmt.Errorf("member %s not found in %s", member, archivePath)
+	}
+
+	file, tarReader, err := openTar(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read tar archive %s: %w", archivePath, err)
+		}
+		if header.Typeflag == tar.TypeReg && header.Name == member {
+			return readCloser{tarReader, file}, nil
+		}
+	}
+	file.Close()
+	return nil, fmt.Errorf("member %s not found in %s", member, archivePath)
+}
+
+// readArchiveMembers calls fn with the content of each listed member of an archive, or the error
+// opening it. A tar archive is streamed once for all of them instead of once per member. Members
+// that are not in the archive are skipped.
+func readArchiveMembers(ctx context.Context, archivePath string, members []string, fn func(member string, content io.Reader, err error)) error {
+	wanted := make(map[string]bool, len(members))
+	for _, member := range members {
+		wanted[member] = true
+	}
+
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to open zip archive %s: %w", archivePath, err)
+		}
+		defer reader.Close()
+
+		for _, file := range reader.File {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !wanted[file.Name] {
+				continue
+			}
+			delete(wanted, file.Name)
+
+			rc, err := file.Open()
+			if err != nil {
+				fn(file.Name, nil, fmt.Errorf("failed to open %s: %w", ArchivePath(archivePath, file.Name), err))
+				continue
+			}
+			fn(file.Name, rc, nil)
+			rc.Close()
+		}
+		return nil
+	}
+
+	file, tarReader, err := openTar(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for len(wanted) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive %s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg || !wanted[header.Name] {
+			continue
+		}
+		delete(wanted, header.Name)
+		fn(header.Name, tarReader, nil)
+	}
+	return nil
+}
+
+// OpenBackupFile opens a backup file for reading, whether it is a plain file or an archive member
+func OpenBackupFile(file BackupFile) (io.ReadCloser, error) {
+	if file.Archive != "" {
+		return openArchiveMember(file.Archive, file.Member)
+	}
+
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source file: %w", err)
+	}
+	return f, nil
+}
+
+// buildArchiveIndex indexes every asset inside a compressed backup generation
+func buildArchiveIndex(ctx context.Context, generation, archivePath string) (*GenerationIndex, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat archive %s: %w", archivePath, err)
+	}
+
+	idx := newGenerationIndex(generation, archivePath)
+	idx.Archive = true
+	idx.ArchiveSize = info.Size()
+	idx.ArchiveModTime = info.ModTime()
+
+	err = walkArchive(ctx, archivePath, func(member archiveMember) error {
+		relPath, ok := archiveAssetPath(member.Name)
+		if !ok {
+			return nil
+		}
+
+		// The hash is the filename without extension
+		name := filepath.Base(relPath)
+		hash := strings.TrimSuffix(name, filepath.Ext(name))
+		idx.Files[hash] = append(idx.Files[hash], IndexedFile{
+			RelativePath: relPath,
+			Member:       member.Name,
+			Size:         member.Size,
+			ModifiedTime: member.ModTime,
+		})
+		idx.FileCount++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idx.IndexedAt = time.Now()
+	return idx, nil
+}
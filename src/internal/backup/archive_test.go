@@ -0,0 +1,119 @@
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeZip creates a zip archive with the given members
+func writeZip(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for name, content := range members {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTarGz creates a gzip-compressed tar archive with the given members
+func writeTarGz(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	writer := tar.NewWriter(gz)
+	for name, content := range members {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchAndRestoreFromArchives(t *testing.T) {
+	root := t.TempDir()
+	zipPath := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup.zip")
+	tarPath := filepath.Join(root, "1757347454_2025_09_08_3.3.0_mt-canvus_backup.tar.gz")
+	writeZip(t, zipPath, map[string]string{
+		"1757261054_2025_09_07_3.3.0_mt-canvus_backup/assets/ab/aaa.jpg": "zip copy",
+		"1757261054_2025_09_07_3.3.0_mt-canvus_backup/db/dump.sql":       "not an asset",
+	})
+	writeTarGz(t, tarPath, map[string]string{
+		"assets/bbb.png":    "tar copy",
+		"assets/cd/ccc.pdf": "other",
+	})
+
+	searcher := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{})
+	searcher.SetIndex(NewIndex(filepath.Join(t.TempDir(), IndexFolderName)))
+
+	result, err := searcher.SearchForAssets(context.Background(), []string{"aaa", "bbb", "dump"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	if result.Generations != 2 || len(result.MissingHashes) != 1 {
+		t.Fatalf("expected both archives searched and only dump missing, got %d generations, missing %v", result.Generations, result.MissingHashes)
+	}
+
+	aaa := result.FoundFiles["aaa"][0]
+	if aaa.Path != zipPath+"!1757261054_2025_09_07_3.3.0_mt-canvus_backup/assets/ab/aaa.jpg" || aaa.RelativePath != filepath.Join("ab", "aaa.jpg") || aaa.Version != "3.3.0" {
+		t.Errorf("unexpected zip member %+v", aaa)
+	}
+
+	// A second search uses the index of the unchanged archives
+	result, err = searcher.SearchForAssets(context.Background(), []string{"aaa", "bbb"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	if result.Reindexed != 0 {
+		t.Errorf("expected archives to be served from the index, got %d reindexed", result.Reindexed)
+	}
+
+	assets := t.TempDir()
+	restorer := NewRestorer(assets)
+	restoreResult, err := restorer.ApplyPlan(context.Background(), restorer.PlanRestore(result))
+	if err != nil {
+		t.Fatalf("ApplyPlan: %v", err)
+	}
+	if len(restoreResult.RestoredFiles) != 2 {
+		t.Fatalf("expected 2 restored files, got %+v", restoreResult)
+	}
+	for path, want := range map[string]string{filepath.Join(assets, "ab", "aaa.jpg"): "zip copy", filepath.Join(assets, "bbb.png"): "tar copy"} {
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %q, %v; want %q", path, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package backup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultGenerationPatterns match the backup folders written by Canvus Server,
+// e.g. 1757261054_2025_09_07_3.3.0_mt-canvus_backup. The last pattern accepts any other
+// *_mt-canvus_backup folder so renamed backups are still searched (ranked after dated ones).
+var DefaultGenerationPatterns = []string{
+	`^(?P<epoch>\d{9,})_(?P<date>\d{4}[_-]\d{2}[_-]\d{2})_(?P<version>[^_]+)_mt-canvus_backup$`,
+	`_mt-canvus_backup$`,
+}
+
+// generationGroups are the named groups a generation pattern may capture
+var generationGroups = map[string]bool{"epoch": true, "date": true, "version": true}
+
+// BackupGeneration is one backup folder, parsed from its {timestamp}_{date}_{version}_mt-canvus_backup name
+type BackupGeneration struct {
+	Name       string    `json:"name"`              // Backup folder name
+	Epoch      int64     `json:"epoch,omitempty"`   // Unix time the backup was taken
+	Date       time.Time `json:"date"`              // Backup date (zero if the name has none)
+	Version    string    `json:"version,omitempty"` // Canvus Server version that wrote the backup
+	AssetsPath string    `json:"assets_path"`       // Full path of the generation's assets folder, or of its archive
+	Archive    bool      `json:"archive,omitempty"` // The generation is a zip, tar or tar.gz archive
+}
+
+// BackupTime returns when the backup was taken: the epoch if known, else the date (zero if neither)
+func (g BackupGeneration) BackupTime() time.Time {
+	if g.Epoch > 0 {
+		return time.Unix(g.Epoch, 0)
+	}
+	return g.Date
+}
+
+// GenerationParser recognises backup folder names using a list of regular expressions.
+// The first matching pattern wins; its optional epoch, date and version groups fill the generation.
+type GenerationParser struct {
+	patterns []*regexp.Regexp
+}
+
+// NewGenerationParser compiles the given patterns (DefaultGenerationPatterns if none)
+func NewGenerationParser(patterns []string) (*GenerationParser, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultGenerationPatterns
+	}
+
+	parser := &GenerationParser{patterns: make([]*regexp.Regexp, 0, len(patterns))}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup folder pattern %q: %w", pattern, err)
+		}
+		for _, group := range re.SubexpNames() {
+			if group != "" && !generationGroups[group] {
+				return nil, fmt.Errorf("backup folder pattern %q has unknown group %q (use epoch, date or version)", pattern, group)
+			}
+		}
+		parser.patterns = append(parser.patterns, re)
+	}
+
+	return parser, nil
+}
+
+// Parse returns the generation described by a folder name, or false if no pattern matches
+func (p *GenerationParser) Parse(name string) (BackupGeneration, bool) {
+	for _, re := range p.patterns {
+		match := re.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		generation := BackupGeneration{Name: name}
+		for i, group := range re.SubexpNames() {
+			value := match[i]
+			switch group {
+			case "epoch":
+				if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+					generation.Epoch = epoch
+				}
+			case "date":
+				generation.Date = parseGenerationDate(value)
+			case "version":
+				generation.Version = value
+			}
+		}
+		return generation, true
+	}
+
+	return BackupGeneration{}, false
+}
+
+// parseGenerationDate reads a year-month-day date with any (or no) separators, e.g. 2025_09_07
+func parseGenerationDate(value string) time.Time {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+
+	date, err := time.ParseInLocation("20060102", digits, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// GenerationFilter limits the search to generations taken in a date range or by given server versions
+type GenerationFilter struct {
+	Since    time.Time // Only generations backed up at or after this time (zero = no lower bound)
+	Until    time.Time // Only generations backed up before this time (zero = no upper bound)
+	Versions []string  // Only generations from these versions; "3.3" matches 3.3.0, 3.3.1, ...
+}
+
+// ParseGenerationFilter builds a filter from YYYY-MM-DD dates (both inclusive) and version prefixes
+func ParseGenerationFilter(since, until string, versions []string) (GenerationFilter, error) {
+	var filter GenerationFilter
+
+	if since != "" {
+		date, err := time.ParseInLocation("2006-01-02", since, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid backup since date %q (expected YYYY-MM-DD): %w", since, err)
+		}
+		filter.Since = date
+	}
+	if until != "" {
+		date, err := time.ParseInLocation("2006-01-02", until, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid backup until date %q (expected YYYY-MM-DD): %w", until, err)
+		}
+		filter.Until = date.AddDate(0, 0, 1) // Include the whole day
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return filter, fmt.Errorf("backup since date %s is after until date %s", since, until)
+	}
+
+	for _, version := range versions {
+		if version = strings.TrimSpace(version); version != "" {
+			filter.Versions = append(filter.Versions, version)
+		}
+	}
+
+	return filter, nil
+}
+
+// IsZero reports whether the filter accepts every generation
+func (f GenerationFilter) IsZero() bool {
+	return f.Since.IsZero() && f.Until.IsZero() && len(f.Versions) == 0
+}
+
+// Matches reports whether a generation passes the filter, and if not, why
+func (f GenerationFilter) Matches(generation BackupGeneration) (bool, string) {
+	backupTime := generation.BackupTime()
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		if backupTime.IsZero() {
+			return false, "backup time unknown"
+		}
+		if !f.Since.IsZero() && backupTime.Before(f.Since) {
+			return false, "backed up before " + f.Since.Format("2006-01-02")
+		}
+		if !f.Until.IsZero() && !backupTime.Before(f.Until) {
+			return false, "backed up after " + f.Until.AddDate(0, 0, -1).Format("2006-01-02")
+		}
+	}
+
+	if len(f.Versions) > 0 {
+		for _, version := range f.Versions {
+			if generation.Version == version || strings.HasPrefix(generation.Version, version+".") {
+				return true, ""
+			}
+		}
+		if generation.Version == "" {
+			return false, "version unknown"
+		}
+		return false, "version " + generation.Version
+	}
+
+	return true, ""
+}
@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseGeneration(t *testing.T) {
+	parser, err := NewGenerationParser(nil)
+	if err != nil {
+		t.Fatalf("NewGenerationParser: %v", err)
+	}
+
+	generation, ok := parser.Parse("1757261054_2025_09_07_3.3.0_mt-canvus_backup")
+	if !ok {
+		t.Fatal("expected the standard backup folder name to parse")
+	}
+	if generation.Epoch != 1757261054 || generation.Version != "3.3.0" || generation.Date.Format("2006-01-02") != "2025-09-07" {
+		t.Errorf("unexpected generation %+v", generation)
+	}
+	if !generation.BackupTime().Equal(time.Unix(1757261054, 0)) {
+		t.Errorf("expected the epoch as backup time, got %v", generation.BackupTime())
+	}
+
+	renamed, ok := parser.Parse("old copy_mt-canvus_backup")
+	if !ok || !renamed.BackupTime().IsZero() || renamed.Version != "" {
+		t.Errorf("expected a renamed backup to be accepted without time or version, got %+v, %v", renamed, ok)
+	}
+
+	if _, ok := parser.Parse("assets"); ok {
+		t.Error("expected an unrelated folder to be rejected")
+	}
+}
+
+func TestCustomGenerationPattern(t *testing.T) {
+	parser, err := NewGenerationParser([]string{`^backup-(?P<date>\d{8})-v(?P<version>[\d.]+)$`})
+	if err != nil {
+		t.Fatalf("NewGenerationParser: %v", err)
+	}
+
+	generation, ok := parser.Parse("backup-20250907-v3.4.1")
+	if !ok || generation.Version != "3.4.1" || generation.BackupTime().Format("2006-01-02") != "2025-09-07" {
+		t.Errorf("unexpected generation %+v, %v", generation, ok)
+	}
+
+	if _, err := NewGenerationParser([]string{`(?P<when>\d+)_mt-canvus_backup`}); err == nil {
+		t.Error("expected an unknown group name to be rejected")
+	}
+}
+
+func TestGenerationFilter(t *testing.T) {
+	filter, err := ParseGenerationFilter("2025-09-01", "2025-09-07", []string{"3.3"})
+	if err != nil {
+		t.Fatalf("ParseGenerationFilter: %v", err)
+	}
+
+	day := func(date string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02 15:04", date, time.Local)
+		return d
+	}
+	tests := []struct {
+		generation BackupGeneration
+		want       bool
+	}{
+		{BackupGeneration{Date: day("2025-09-07 00:00"), Version: "3.3.0"}, true},
+		{BackupGeneration{Epoch: day("2025-09-07 23:30").Unix(), Version: "3.3.1"}, true},
+		{BackupGeneration{Date: day("2025-09-08 00:00"), Version: "3.3.0"}, false},
+		{BackupGeneration{Date: day("2025-08-31 00:00"), Version: "3.3.0"}, false},
+		{BackupGeneration{Date: day("2025-09-03 00:00"), Version: "3.30.0"}, false},
+		{BackupGeneration{Version: "3.3.0"}, false},
+	}
+	for i, tt := range tests {
+		if got, reason := filter.Matches(tt.generation); got != tt.want {
+			t.Errorf("case %d: Matches(%+v) = %v (%s), want %v", i, tt.generation, got, reason, tt.want)
+		}
+	}
+
+	if _, err := ParseGenerationFilter("2025-09-07", "2025-09-01", nil); err == nil {
+		t.Error("expected since after until to be rejected")
+	}
+}
+
+func TestSearchRanksByBackupTimeNotModifiedTime(t *testing.T) {
+	root := t.TempDir()
+	older := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets")
+	newer := filepath.Join(root, "1757347454_2025_09_08_3.4.0_mt-canvus_backup", "assets")
+	writeFile(t, filepath.Join(older, "aaa.jpg"), "a")
+	writeFile(t, filepath.Join(newer, "aaa.jpg"), "a")
+	// The newest file on disk is in the older backup, as happens after a robocopy
+	touch(t, filepath.Join(newer, "aaa.jpg"), time.Now().Add(-48*time.Hour))
+
+	searcher := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{})
+	result, err := searcher.SearchForAssets(context.Background(), []string{"aaa"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	searcher.SortBackupFiles(result)
+
+	best := searcher.GetBestBackupFile(result, "aaa")
+	if best == nil || best.Version != "3.4.0" || best.Generation != "1757347454_2025_09_08_3.4.0_mt-canvus_backup" {
+		t.Errorf("expected the file from the newest backup first, got %+v", best)
+	}
+
+	filter, _ := ParseGenerationFilter("", "", []string{"3.3"})
+	result, err = NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{Filter: filter}).SearchForAssets(context.Background(), []string{"aaa"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	if result.Filtered != 1 || len(result.FoundFiles["aaa"]) != 1 || result.FoundFiles["aaa"][0].Version != "3.3.0" {
+		t.Errorf("expected only the 3.3 backup to be searched, got filtered=%d files=%+v", result.Filtered, result.FoundFiles["aaa"])
+	}
+}
+
+func TestSearchPrefersHigherPriorityRoot(t *testing.T) {
+	local := t.TempDir()
+	nas := t.TempDir()
+	writeFile(t, filepath.Join(local, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets", "aaa.jpg"), "a")
+	writeFile(t, filepath.Join(nas, "1757347454_2025_09_08_3.3.0_mt-canvus_backup", "assets", "aaa.jpg"), "a")
+
+	searcher := NewSearcher([]BackupRoot{
+		{Path: nas, Label: "nas", Priority: 2},
+		{Path: local, Label: "local", Priority: 1},
+		{Path: filepath.Join(local, "offline"), Label: "archive", Priority: 3},
+	}, SearchOptions{})
+	searcher.SetIndex(NewIndex(filepath.Join(t.TempDir(), IndexFolderName)))
+
+	result, err := searcher.SearchForAssets(context.Background(), []string{"aaa"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	searcher.SortBackupFiles(result)
+
+	if result.TotalSearched != 2 {
+		t.Errorf("expected the two reachable roots to be searched, got %d", result.TotalSearched)
+	}
+	files := result.FoundFiles["aaa"]
+	if len(files) != 2 || files[0].Root != "local" || files[1].Root != "nas" {
+		t.Errorf("expected the local copy first despite the newer NAS backup, got %+v", files)
+	}
+}
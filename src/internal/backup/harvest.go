@@ -0,0 +1,230 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+)
+
+// HarvestOptions controls a harvest
+type HarvestOptions struct {
+	Algorithm   digest.Algorithm // Digest used for asset file names; must be known to match contents
+	Workers     int              // Files hashed at the same time (default 1)
+	MaxFileSize int64            // Files larger than this are skipped (0 = no limit)
+}
+
+// HarvestResult contains the files whose content matched a missing asset hash
+type HarvestResult struct {
+	Directories []string                `json:"directories"`
+	Algorithm   string                  `json:"algorithm"`
+	Matches     map[string][]BackupFile `json:"matches"`      // Hash -> files whose content hashes to it
+	FilesHashed int                     `json:"files_hashed"` // Files read and hashed
+	BytesHashed int64                   `json:"bytes_hashed"` // Bytes read and hashed
+	Skipped     int                     `json:"skipped"`      // Files skipped as too large or unreadable
+	Duration    time.Duration           `json:"duration"`
+	Interrupted bool                    `json:"interrupted"` // Not every file was hashed
+}
+
+// Harvester finds missing assets by content in folders that do not follow the {hash}.{ext} naming,
+// such as user document shares and client caches holding the original uploads
+type Harvester struct {
+	directories []string
+	options     HarvestOptions
+	logger      *logging.Logger
+}
+
+// NewHarvester creates a harvester over the given directories
+func NewHarvester(directories []string, opts HarvestOptions) *Harvester {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	return &Harvester{
+		directories: directories,
+		options:     opts,
+		logger:      logging.GetLogger(),
+	}
+}
+
+// Harvest hashes every file in the harvester's directories and returns those whose content matches
+// one of the missing hashes. If ctx is cancelled the matches found so far are returned with the
+// context error; they are still valid restore candidates.
+func (h *Harvester) Harvest(ctx context.Context, missingHashes []string) (*HarvestResult, error) {
+	start := time.Now()
+	result := &HarvestResult{
+		Directories: h.directories,
+		Algorithm:   h.options.Algorithm.String(),
+		Matches:     make(map[string][]BackupFile),
+	}
+
+	// Asset hashes are matched case-insensitively against the hex digest
+	wanted := make(map[string]string, len(missingHashes))
+	for _, hash := range missingHashes {
+		if len(hash) == h.options.Algorithm.Length {
+			wanted[strings.ToLower(hash)] = hash
+		}
+	}
+	if len(wanted) == 0 {
+		h.logger.Info("No missing hashes of the %s length to harvest", result.Algorithm)
+		return result, nil
+	}
+
+	h.logger.Info("🌾 Harvesting %d missing assets from %d directories (%s)...", len(wanted), len(h.directories), result.Algorithm)
+
+	type candidate struct {
+		label string
+		path  string
+		info  os.FileInfo
+	}
+
+	queue := make(chan candidate)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < h.options.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range queue {
+				if ctx.Err() != nil {
+					continue // Drain the queue without reading
+				}
+				sum, err := h.sumFile(c.path)
+
+				mu.Lock()
+				if err != nil {
+					result.Skipped++
+					h.logger.Verbose("Cannot read %s: %v", c.path, err)
+					mu.Unlock()
+					continue
+				}
+				result.FilesHashed++
+				result.BytesHashed += c.info.Size()
+				if hash, found := wanted[sum[:h.options.Algorithm.Length]]; found {
+					result.Matches[hash] = append(result.Matches[hash], harvestedFile(hash, c.label, c.path, c.info))
+					h.logger.Verbose("🌾 %s matches %s", c.path, hash)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var walkErr error
+	for _, directory := range h.directories {
+		label := directory
+		err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				// Log error but continue harvesting
+				h.logger.Verbose("Error accessing %s: %v", path, err)
+				return nil
+			}
+			if !info.Mode().IsRegular() || info.Size() == 0 {
+				return nil
+			}
+			if h.options.MaxFileSize > 0 && info.Size() > h.options.MaxFileSize {
+				mu.Lock()
+				result.Skipped++
+				mu.Unlock()
+				return nil
+			}
+
+			select {
+			case queue <- candidate{label: label, path: path, info: info}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			walkErr = err
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	// Several copies of one upload are all valid; list them in a stable order
+	for hash, files := range result.Matches {
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+		result.Matches[hash] = files
+	}
+	result.Duration = time.Since(start)
+
+	if ctx.Err() != nil {
+		result.Interrupted = true
+		return result, fmt.Errorf("harvest interrupted: %w", ctx.Err())
+	}
+	if walkErr != nil {
+		return result, fmt.Errorf("harvest failed: %w", walkErr)
+	}
+
+	h.logger.Info("✅ Harvest completed: %d files hashed (%.2f MB) in %v, %d missing assets matched",
+		result.FilesHashed, float64(result.BytesHashed)/(1024*1024), result.Duration.Round(time.Millisecond), len(result.Matches))
+	return result, nil
+}
+
+// sumFile returns the lower-case hex digest of a file
+func (h *Harvester) sumFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return h.options.Algorithm.Sum(file)
+}
+
+// harvestedFile describes a file matched by content. It is restored as {hash}{ext} at the top of the
+// assets folder, keeping the extension of the original file.
+func harvestedFile(hash, label, path string, info os.FileInfo) BackupFile {
+	ext := strings.ToLower(filepath.Ext(path))
+	return BackupFile{
+		Path:         path,
+		Hash:         hash,
+		Extension:    ext,
+		ModifiedTime: info.ModTime(),
+		Size:         info.Size(),
+		RelativePath: hash + ext,
+		Root:         label,
+		Source:       SourceHarvest,
+		Verified:     true,
+		HashVerified: true,
+	}
+}
+
+// MergeInto adds the harvested files to a backup search result as restore candidates, after any
+// backup copies so those stay preferred. Hashes that had no backup copy are no longer missing.
+func (r *HarvestResult) MergeInto(searchResult *SearchResult) {
+	if searchResult.FoundFiles == nil {
+		searchResult.FoundFiles = make(map[string][]BackupFile)
+	}
+
+	for hash, files := range r.Matches {
+		known := make(map[string]bool)
+		for _, file := range searchResult.FoundFiles[hash] {
+			known[file.Path] = true
+		}
+		for _, file := range files {
+			if !known[file.Path] { // Harvesting again must not list a file twice
+				searchResult.FoundFiles[hash] = append(searchResult.FoundFiles[hash], file)
+			}
+		}
+	}
+
+	missing := make([]string, 0, len(searchResult.MissingHashes))
+	for _, hash := range searchResult.MissingHashes {
+		if _, found := r.Matches[hash]; !found {
+			missing = append(missing, hash)
+		}
+	}
+	searchResult.MissingHashes = missing
+}
@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+)
+
+func TestHarvestMatchesFilesByContent(t *testing.T) {
+	sum := sha256.Sum256([]byte("quarterly report"))
+	report := hex.EncodeToString(sum[:])
+	sum = sha256.Sum256([]byte("team photo"))
+	photo := strings.ToUpper(hex.EncodeToString(sum[:])) // Matching ignores case
+	sum = sha256.Sum256([]byte("never uploaded anywhere"))
+	lost := hex.EncodeToString(sum[:])
+
+	share := t.TempDir()
+	cache := t.TempDir()
+	writeFile(t, filepath.Join(share, "alice", "Q3 Report.PDF"), "quarterly report")
+	writeFile(t, filepath.Join(share, "bob", "Team.jpg"), "team photo")
+	writeFile(t, filepath.Join(share, "bob", "notes.txt"), "unrelated")
+	writeFile(t, filepath.Join(cache, "0001.tmp"), "quarterly report")
+
+	algorithm, err := digest.Parse("sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	harvester := NewHarvester([]string{share, cache}, HarvestOptions{Algorithm: algorithm, Workers: 2, MaxFileSize: 12})
+
+	result, err := harvester.Harvest(context.Background(), []string{report, photo, lost})
+	if err != nil {
+		t.Fatalf("Harvest: %v", err)
+	}
+
+	// "quarterly report" is larger than MaxFileSize, so only photo can match
+	if len(result.Matches) != 1 || len(result.Matches[photo]) != 1 || result.Skipped != 2 {
+		t.Fatalf("expected only the photo to match with 2 files skipped, got %+v", result)
+	}
+
+	harvester = NewHarvester([]string{share, cache}, HarvestOptions{Algorithm: algorithm})
+	result, err = harvester.Harvest(context.Background(), []string{report, photo, lost})
+	if err != nil {
+		t.Fatalf("Harvest: %v", err)
+	}
+	if result.FilesHashed != 4 || len(result.Matches) != 2 {
+		t.Fatalf("expected 4 files hashed and 2 assets matched, got %+v", result)
+	}
+
+	copies := result.Matches[report]
+	if len(copies) != 2 || copies[0].Path != filepath.Join(share, "alice", "Q3 Report.PDF") {
+		t.Fatalf("expected both copies of the report in path order, got %+v", copies)
+	}
+	if copies[0].RelativePath != report+".pdf" || copies[0].Source != SourceHarvest || !copies[0].HashVerified || copies[0].Root != share {
+		t.Errorf("unexpected harvested file %+v", copies[0])
+	}
+
+	searchResult := &SearchResult{FoundFiles: map[string][]BackupFile{}, MissingHashes: []string{lost, photo, report}}
+	result.MergeInto(searchResult)
+	result.MergeInto(searchResult)
+	if len(searchResult.FoundFiles[report]) != 2 || len(searchResult.FoundFiles[photo]) != 1 {
+		t.Errorf("expected harvested files to be merged once, got %+v", searchResult.FoundFiles)
+	}
+	if len(searchResult.MissingHashes) != 1 || searchResult.MissingHashes[0] != lost {
+		t.Errorf("expected only %s to stay missing, got %v", lost, searchResult.MissingHashes)
+	}
+}
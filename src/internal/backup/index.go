@@ -0,0 +1,299 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IndexFolderName is the default folder, inside the output folder, holding the backup index
+const IndexFolderName = "backup_index"
+
+// indexVersion is bumped whenever the index layout changes; older indexes are rebuilt
+const indexVersion = 1
+
+// IndexedFile is one file of a backup generation as stored in the index
+type IndexedFile struct {
+	RelativePath string    `json:"relative_path"`    // Relative to the generation's assets folder
+	Member       string    `json:"member,omitempty"` // Name inside the archive, for compressed generations
+	Size         int64     `json:"size"`
+	ModifiedTime time.Time `json:"modified_time"`
+}
+
+// GenerationIndex lists every file of one backup generation by hash, together with the
+// modification times of its directories so a changed generation can be detected without a full walk.
+// A compressed generation is tracked by the size and modification time of its archive instead.
+type GenerationIndex struct {
+	Version        int                      `json:"version"`
+	Generation     string                   `json:"generation"`  // Backup folder (or archive) name
+	AssetsPath     string                   `json:"assets_path"` // Full path of the generation's assets folder, or of its archive
+	Directories    map[string]time.Time     `json:"directories"` // Relative directory path -> modification time
+	Files          map[string][]IndexedFile `json:"files"`       // Hash -> files with that hash
+	FileCount      int                      `json:"file_count"`
+	IndexedAt      time.Time                `json:"indexed_at"`
+	Archive        bool                     `json:"archive,omitempty"`
+	ArchiveSize    int64                    `json:"archive_size,omitempty"`
+	ArchiveModTime time.Time                `json:"archive_mod_time,omitempty"`
+}
+
+// newGenerationIndex creates an empty index for a generation
+func newGenerationIndex(generation, assetsPath string) *GenerationIndex {
+	return &GenerationIndex{
+		Version:     indexVersion,
+		Generation:  generation,
+		AssetsPath:  assetsPath,
+		Directories: make(map[string]time.Time),
+		Files:       make(map[string][]IndexedFile),
+	}
+}
+
+// indexTopLevel indexes the files directly inside a generation's assets folder and returns its
+// subfolders, which indexSubtree indexes separately so large generations can be walked concurrently
+func indexTopLevel(generation, assetsPath string) (*GenerationIndex, []string, error) {
+	idx := newGenerationIndex(generation, assetsPath)
+
+	info, err := os.Stat(assetsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat assets folder %s: %w", assetsPath, err)
+	}
+	idx.Directories["."] = info.ModTime()
+
+	entries, err := os.ReadDir(assetsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read assets folder %s: %w", assetsPath, err)
+	}
+
+	subtrees := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			subtrees = append(subtrees, entry.Name())
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed since the folder was listed
+		}
+		idx.add(entry.Name(), info)
+	}
+
+	return idx, subtrees, nil
+}
+
+// indexSubtree walks one subfolder of a generation's assets folder; paths stay relative to the assets folder.
+// Files and folders that cannot be read are skipped and returned as walk errors, so the partial index
+// can still be searched but must not be stored as complete.
+func indexSubtree(ctx context.Context, assetsPath, subtree string) (*GenerationIndex, []error, error) {
+	idx := newGenerationIndex("", assetsPath)
+	walkErrors := make([]error, 0)
+
+	err := filepath.Walk(filepath.Join(assetsPath, subtree), func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// Keep indexing the rest of the subtree
+			walkErrors = append(walkErrors, fmt.Errorf("%s: %w", path, err))
+			return nil
+		}
+
+		relPath, err := filepath.Rel(assetsPath, path)
+		if err != nil {
+			// If we can't calculate relative path, use just the filename
+			relPath = info.Name()
+		}
+
+		if info.IsDir() {
+			idx.Directories[relPath] = info.ModTime()
+			return nil
+		}
+
+		idx.add(relPath, info)
+		return nil
+	})
+	if err != nil {
+		return nil, walkErrors, err
+	}
+
+	return idx, walkErrors, nil
+}
+
+// add records a file; the hash is the filename without extension
+func (idx *GenerationIndex) add(relPath string, info os.FileInfo) {
+	hash := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
+	idx.Files[hash] = append(idx.Files[hash], IndexedFile{
+		RelativePath: relPath,
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+	})
+	idx.FileCount++
+}
+
+// merge adds the directories and files of a partial index
+func (idx *GenerationIndex) merge(part *GenerationIndex) {
+	for dir, modTime := range part.Directories {
+		idx.Directories[dir] = modTime
+	}
+	for hash, files := range part.Files {
+		idx.Files[hash] = append(idx.Files[hash], files...)
+	}
+	idx.FileCount += part.FileCount
+}
+
+// IsFresh reports whether the generation on disk still matches the index.
+// Adding, removing or renaming a file changes the modification time of its directory,
+// so only the directories need to be checked.
+func (idx *GenerationIndex) IsFresh(assetsPath string) bool {
+	if idx.Version != indexVersion || idx.AssetsPath != assetsPath {
+		return false
+	}
+
+	if idx.Archive {
+		info, err := os.Stat(assetsPath)
+		return err == nil && info.Size() == idx.ArchiveSize && info.ModTime().Equal(idx.ArchiveModTime)
+	}
+
+	if len(idx.Directories) == 0 {
+		return false
+	}
+
+	for relPath, modTime := range idx.Directories {
+		info, err := os.Stat(filepath.Join(assetsPath, relPath))
+		if err != nil || !info.IsDir() || !info.ModTime().Equal(modTime) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Lookup returns the backup files of the generation with the given hash
+func (idx *GenerationIndex) Lookup(hash string) []BackupFile {
+	indexed := idx.Files[hash]
+	if len(indexed) == 0 {
+		return nil
+	}
+
+	files := make([]BackupFile, 0, len(indexed))
+	for _, f := range indexed {
+		file := BackupFile{
+			Path:         filepath.Join(idx.AssetsPath, f.RelativePath),
+			Hash:         hash,
+			Extension:    filepath.Ext(f.RelativePath),
+			ModifiedTime: f.ModifiedTime,
+			Size:         f.Size,
+			RelativePath: f.RelativePath,
+		}
+		if idx.Archive {
+			file.Path = ArchivePath(idx.AssetsPath, f.Member)
+			file.Archive = idx.AssetsPath
+			file.Member = f.Member
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// Index stores one GenerationIndex file per backup generation in a folder
+type Index struct {
+	folder string
+}
+
+// NewIndex creates an index stored in folder
+func NewIndex(folder string) *Index {
+	return &Index{folder: folder}
+}
+
+// Folder returns the folder the index is stored in
+func (i *Index) Folder() string {
+	return i.folder
+}
+
+// ForRoot returns the index of one backup root, stored in a subfolder keyed on the root's full path.
+// Labels are only for display and may change or repeat folder names, so they are not used.
+func (i *Index) ForRoot(rootPath string) *Index {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, filepath.Base(filepath.Clean(rootPath)))
+	return NewIndex(filepath.Join(i.folder, name+"-"+pathHash(rootPath)))
+}
+
+// path returns the index file of a generation
+func (i *Index) path(generation string) string {
+	return filepath.Join(i.folder, generation+".json")
+}
+
+// Load reads the stored index of a generation. A missing or unreadable index yields nil.
+func (i *Index) Load(generation string) (*GenerationIndex, error) {
+	data, err := os.ReadFile(i.path(generation))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup index %s: %w", i.path(generation), err)
+	}
+
+	var idx GenerationIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to decode backup index %s: %w", i.path(generation), err)
+	}
+	return &idx, nil
+}
+
+// Save writes the index of a generation via a temporary file so a crash never leaves a half-written index
+func (i *Index) Save(idx *GenerationIndex) error {
+	if err := os.MkdirAll(i.folder, 0755); err != nil {
+		return fmt.Errorf("failed to create backup index folder: %w", err)
+	}
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup index for %s: %w", idx.Generation, err)
+	}
+
+	path := i.path(idx.Generation)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup index %s: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move backup index into place: %w", err)
+	}
+
+	return nil
+}
+
+// Prune removes the index files of generations that no longer exist
+func (i *Index) Prune(generations []string) error {
+	current := make(map[string]bool, len(generations))
+	for _, generation := range generations {
+		current[generation+".json"] = true
+	}
+
+	entries, err := os.ReadDir(i.folder)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read backup index folder: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || current[entry.Name()] || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(i.folder, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove stale backup index %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile creates a file and its parent folders
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// touch sets a file's modification time
+func touch(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchReindexesOnlyChangedGenerations(t *testing.T) {
+	root := t.TempDir()
+	oldAssets := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets")
+	newAssets := filepath.Join(root, "1757347454_2025_09_08_3.3.0_mt-canvus_backup", "assets")
+	writeFile(t, filepath.Join(oldAssets, "ab", "aaa.jpg"), "a")
+	writeFile(t, filepath.Join(newAssets, "ab", "aaa.jpg"), "a")
+	writeFile(t, filepath.Join(newAssets, "bbb.png"), "b")
+
+	searcher := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{})
+	searcher.SetIndex(NewIndex(filepath.Join(t.TempDir(), IndexFolderName)))
+
+	result, err := searcher.SearchForAssets(context.Background(), []string{"aaa", "bbb", "ccc"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	if result.Generations != 2 || result.Reindexed != 2 {
+		t.Errorf("first search: expected 2 generations indexed, got %d searched, %d reindexed", result.Generations, result.Reindexed)
+	}
+	if len(result.FoundFiles["aaa"]) != 2 || len(result.FoundFiles["bbb"]) != 1 || len(result.MissingHashes) != 1 {
+		t.Errorf("first search: unexpected results %+v", result)
+	}
+
+	result, err = searcher.SearchForAssets(context.Background(), []string{"aaa", "bbb"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	if result.Reindexed != 0 {
+		t.Errorf("second search: expected both generations from the index, got %d reindexed", result.Reindexed)
+	}
+	if got := result.FoundFiles["aaa"]; len(got) != 2 || got[0].RelativePath != filepath.Join("ab", "aaa.jpg") || got[0].Extension != ".jpg" {
+		t.Errorf("second search: unexpected files for aaa: %+v", got)
+	}
+
+	// Adding a file touches its directory, which marks the generation as changed
+	writeFile(t, filepath.Join(oldAssets, "ab", "ccc.pdf"), "c")
+	touch(t, filepath.Join(oldAssets, "ab"), time.Now().Add(time.Minute))
+
+	result, err = searcher.SearchForAssets(context.Background(), []string{"ccc"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	if result.Reindexed != 1 || len(result.FoundFiles["ccc"]) != 1 {
+		t.Errorf("third search: expected only the changed generation reindexed and ccc found, got %d reindexed, %+v", result.Reindexed, result.FoundFiles)
+	}
+}
+
+func TestIndexPruneRemovesVanishedGenerations(t *testing.T) {
+	index := NewIndex(t.TempDir())
+	for _, generation := range []string{"kept_mt-canvus_backup", "gone_mt-canvus_backup"} {
+		if err := index.Save(newGenerationIndex(generation, "/backups/"+generation+"/assets")); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	if err := index.Prune([]string{"kept_mt-canvus_backup"}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	if idx, err := index.Load("kept_mt-canvus_backup"); err != nil || idx == nil {
+		t.Errorf("expected kept generation to stay indexed, got %v, %v", idx, err)
+	}
+	if idx, err := index.Load("gone_mt-canvus_backup"); err != nil || idx != nil {
+		t.Errorf("expected vanished generation to be pruned, got %v, %v", idx, err)
+	}
+}
+
+func TestRootsWithTheSameFolderNameKeepSeparateIndexes(t *testing.T) {
+	base := t.TempDir()
+	generation := "1757261054_2025_09_07_3.3.0_mt-canvus_backup"
+	local := filepath.Join(base, "local", "backups")
+	nas := filepath.Join(base, "nas", "backups")
+	writeFile(t, filepath.Join(local, generation, "assets", "aaa.jpg"), "a")
+	writeFile(t, filepath.Join(nas, generation, "assets", "bbb.png"), "b")
+
+	roots := LabelRoots([]BackupRoot{{Path: local, Priority: 1}, {Path: nas, Priority: 2}})
+	if roots[0].Label == roots[1].Label {
+		t.Fatalf("expected distinct default labels, both are %q", roots[0].Label)
+	}
+
+	searcher := NewSearcher([]BackupRoot{{Path: local, Priority: 1}, {Path: nas, Priority: 2}}, SearchOptions{})
+	searcher.SetIndex(NewIndex(filepath.Join(t.TempDir(), IndexFolderName)))
+
+	for _, search := range []string{"first", "second"} {
+		result, err := searcher.SearchForAssets(context.Background(), []string{"aaa", "bbb"})
+		if err != nil {
+			t.Fatalf("SearchForAssets: %v", err)
+		}
+		if search == "second" && result.Reindexed != 0 {
+			t.Errorf("second search: expected both roots from their own index, got %d reindexed", result.Reindexed)
+		}
+		aaa, bbb := result.FoundFiles["aaa"], result.FoundFiles["bbb"]
+		if len(aaa) != 1 || aaa[0].Root != roots[0].Label || len(bbb) != 1 || bbb[0].Root != roots[1].Label {
+			t.Errorf("%s search: expected aaa in %s and bbb in %s, got %+v", search, roots[0].Label, roots[1].Label, result.FoundFiles)
+		}
+	}
+}
+
+func TestLabelRoots(t *testing.T) {
+	roots := LabelRoots([]BackupRoot{
+		{Path: "/mnt/nas"},
+		{Path: "/data/archive", Label: "old"},
+		{Path: "/var/backups"},
+		{Path: "/srv/backups"},
+		{Path: "/srv/old"},
+	})
+
+	if roots[0].Label != "nas" || roots[1].Label != "old" {
+		t.Errorf("expected the folder name or the configured label, got %q and %q", roots[0].Label, roots[1].Label)
+	}
+	// Folder names shared with another root, or with another root's label, get a path hash
+	for _, i := range []int{2, 3, 4} {
+		if len(roots[i].Label) != len(filepath.Base(roots[i].Path))+9 {
+			t.Errorf("expected %s to be labelled with a path hash, got %q", roots[i].Path, roots[i].Label)
+		}
+	}
+	if roots[2].Label == roots[3].Label {
+		t.Errorf("expected distinct labels, both are %q", roots[2].Label)
+	}
+	if again := LabelRoots([]BackupRoot{{Path: "/var/backups"}, {Path: "/srv/backups"}}); again[0].Label != roots[2].Label {
+		t.Errorf("expected a stable label, got %q then %q", roots[2].Label, again[0].Label)
+	}
+}
+
+func TestPartlyReadGenerationIsNotSavedToIndex(t *testing.T) {
+	assets := filepath.Join(t.TempDir(), "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets")
+	writeFile(t, filepath.Join(assets, "ab", "aaa.jpg"), "a")
+	generation := BackupGeneration{Name: "1757261054_2025_09_07_3.3.0_mt-canvus_backup", AssetsPath: assets}
+	index := NewIndex(t.TempDir())
+	searcher := NewSearcher(nil, SearchOptions{})
+
+	// A folder that could not be read would be missing from the index for good
+	idx, _, err := indexTopLevel(generation.Name, assets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &generationJob{target: searchTarget{generation: generation, index: index}, unread: 1}
+	searcher.finishJob(job, idx, nil)
+	if job.idx == nil {
+		t.Error("expected the partial index to be searched this run")
+	}
+	if stored, err := index.Load(generation.Name); err != nil || stored != nil {
+		t.Errorf("expected no stored index, got %+v (%v)", stored, err)
+	}
+
+	job = &generationJob{target: searchTarget{generation: generation, index: index}}
+	searcher.finishJob(job, idx, nil)
+	if stored, err := index.Load(generation.Name); err != nil || stored == nil {
+		t.Errorf("expected a fully read generation to be stored, got %v", err)
+	}
+}
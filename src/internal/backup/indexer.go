@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// GenerationTiming records how long one backup generation took to search
+type GenerationTiming struct {
+	Root       string        `json:"root"`
+	Generation string        `json:"generation"`
+	Files      int           `json:"files"`      // Files in the generation's index
+	FromIndex  bool          `json:"from_index"` // Served from the stored index without walking the generation
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// searchTarget is one generation to search, with the index folder of its root
+type searchTarget struct {
+	root       BackupRoot
+	generation BackupGeneration
+	index      *Index // nil if the searcher keeps no index
+}
+
+// generationJob tracks the indexing of one generation, which may be split into several subtree tasks
+type generationJob struct {
+	target    searchTarget
+	start     time.Time
+	idx       *GenerationIndex   // Complete index, set once every part is done (nil if cancelled or failed)
+	parts     []*GenerationIndex // Subtree indexes, merged in folder order so the result is deterministic
+	pending   int
+	fromIndex bool
+	unread    int // Files and folders that could not be read while walking
+	err       error
+	duration  time.Duration
+	mu        sync.Mutex
+}
+
+// indexGenerations returns the index of every target, in target order, with nil for generations that
+// failed or were not finished before ctx was cancelled. Stored indexes are reused for unchanged
+// generations; the rest are walked by at most s.workers concurrent tasks, one per archive or per
+// top-level subfolder of a generation's assets folder.
+func (s *Searcher) indexGenerations(ctx context.Context, targets []searchTarget) ([]*GenerationIndex, []*generationJob) {
+	semaphore := make(chan struct{}, s.workers)
+	var wg sync.WaitGroup
+
+	// run starts a task once a worker slot is free; tasks still waiting when ctx is cancelled are dropped
+	var run func(task func())
+	run = func(task func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-semaphore }()
+			if ctx.Err() != nil {
+				return
+			}
+			task()
+		}()
+	}
+
+	jobs := make([]*generationJob, len(targets))
+	for i, target := range targets {
+		job := &generationJob{target: target}
+		jobs[i] = job
+		run(func() { s.startJob(ctx, job, run) })
+	}
+	wg.Wait()
+
+	indexes := make([]*GenerationIndex, len(jobs))
+	for i, job := range jobs {
+		if job.err == nil && job.idx != nil {
+			indexes[i] = job.idx
+		}
+	}
+	return indexes, jobs
+}
+
+// startJob reuses a fresh stored index, or indexes an archive, or splits a generation's assets
+// folder into subtree tasks
+func (s *Searcher) startJob(ctx context.Context, job *generationJob, run func(task func())) {
+	job.start = time.Now()
+	generation := job.target.generation
+
+	if index := job.target.index; index != nil {
+		idx, err := index.Load(generation.Name)
+		if err != nil {
+			s.logger.Warn("Ignoring backup index for %s: %v", generation.Name, err)
+		} else if idx != nil && idx.IsFresh(generation.AssetsPath) {
+			s.logger.Verbose("Using backup index for %s (%d files, indexed %s)",
+				generation.Name, idx.FileCount, idx.IndexedAt.Format("2006-01-02 15:04:05"))
+			job.fromIndex = true
+			s.finishJob(job, idx, nil)
+			return
+		}
+	}
+
+	s.logger.Info("📇 Indexing backup generation %s...", generation.Name)
+
+	if generation.Archive {
+		idx, err := buildArchiveIndex(ctx, generation.Name, generation.AssetsPath)
+		s.finishJob(job, idx, err)
+		return
+	}
+
+	top, subtrees, err := indexTopLevel(generation.Name, generation.AssetsPath)
+	if err != nil || len(subtrees) == 0 {
+		s.finishJob(job, top, err)
+		return
+	}
+
+	job.parts = make([]*GenerationIndex, len(subtrees))
+	job.pending = len(subtrees)
+	for i, subtree := range subtrees {
+		run(func() {
+			part, walkErrors, err := indexSubtree(ctx, generation.AssetsPath, subtree)
+			for _, walkErr := range walkErrors {
+				s.logger.Warn("Skipping unreadable backup path: %v", walkErr)
+			}
+
+			job.mu.Lock()
+			job.parts[i] = part
+			job.unread += len(walkErrors)
+			if err != nil && job.err == nil {
+				job.err = err
+			}
+			job.pending--
+			done := job.pending == 0
+			job.mu.Unlock()
+
+			if done {
+				if job.err == nil {
+					for _, part := range job.parts {
+						top.merge(part)
+					}
+				}
+				s.finishJob(job, top, job.err)
+			}
+		})
+	}
+}
+
+// finishJob records the outcome of a generation and saves a newly built index
+func (s *Searcher) finishJob(job *generationJob, idx *GenerationIndex, err error) {
+	job.duration = time.Since(job.start)
+	if err != nil {
+		job.err = err
+		return
+	}
+
+	job.idx = idx
+	if job.fromIndex {
+		return
+	}
+	idx.IndexedAt = time.Now()
+
+	s.logger.Verbose("Indexed %d files of %s in %v", idx.FileCount, idx.Generation, job.duration.Round(time.Millisecond))
+
+	// A generation that could not be indexed, or only partly, is simply walked again next time.
+	// Folders that could not be read are missing from the index, so it could never notice them change.
+	if job.unread > 0 {
+		s.logger.Warn("%d files or folders of %s could not be read - not saving its backup index", job.unread, idx.Generation)
+		return
+	}
+	if index := job.target.index; index != nil {
+		if err := index.Save(idx); err != nil {
+			s.logger.Warn("Failed to save backup index for %s: %v", idx.Generation, err)
+		}
+	}
+}
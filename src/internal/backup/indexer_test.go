@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParallelSearchMatchesSequentialSearch(t *testing.T) {
+	root := t.TempDir()
+	hashes := make([]string, 0)
+	for g := 0; g < 4; g++ {
+		assets := filepath.Join(root, fmt.Sprintf("17572610%02d_2025_09_%02d_3.3.0_mt-canvus_backup", g, g+1), "assets")
+		for d := 0; d < 5; d++ {
+			for f := 0; f < 3; f++ {
+				hash := fmt.Sprintf("h%d%d", d, f)
+				writeFile(t, filepath.Join(assets, fmt.Sprintf("d%d", d), "nested", hash+".jpg"), hash)
+				if g == 0 {
+					hashes = append(hashes, hash)
+				}
+			}
+		}
+		writeFile(t, filepath.Join(assets, fmt.Sprintf("top%d.png", g)), "top")
+	}
+	hashes = append(hashes, "top2", "absent")
+
+	search := func(workers int) *SearchResult {
+		searcher := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{MaxConcurrentFiles: workers})
+		result, err := searcher.SearchForAssets(context.Background(), hashes)
+		if err != nil {
+			t.Fatalf("SearchForAssets with %d workers: %v", workers, err)
+		}
+		searcher.SortBackupFiles(result)
+		return result
+	}
+
+	sequential := search(1)
+	parallel := search(8)
+
+	if !reflect.DeepEqual(sequential.FoundFiles, parallel.FoundFiles) || !reflect.DeepEqual(sequential.MissingHashes, parallel.MissingHashes) {
+		t.Errorf("parallel search differs from sequential search")
+	}
+	if len(parallel.FoundFiles["h12"]) != 4 || len(parallel.FoundFiles["top2"]) != 1 || !reflect.DeepEqual(parallel.MissingHashes, []string{"absent"}) {
+		t.Errorf("unexpected results: %d copies of h12, %d of top2, missing %v",
+			len(parallel.FoundFiles["h12"]), len(parallel.FoundFiles["top2"]), parallel.MissingHashes)
+	}
+
+	if len(parallel.Timings) != 4 {
+		t.Fatalf("expected a timing per generation, got %d", len(parallel.Timings))
+	}
+	for i, timing := range parallel.Timings {
+		if timing.Files != 16 || timing.FromIndex {
+			t.Errorf("timing %d: unexpected %+v", i, timing)
+		}
+		if i > 0 && timing.Generation > parallel.Timings[i-1].Generation {
+			t.Errorf("expected timings in search order (newest first), got %s after %s", timing.Generation, parallel.Timings[i-1].Generation)
+		}
+	}
+}
+
+func TestParallelSearchStopsWhenCancelled(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets", "ab", "aaa.jpg"), "a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{MaxConcurrentFiles: 4}).SearchForAssets(ctx, []string{"aaa"})
+	if err == nil {
+		t.Fatal("expected an interruption error")
+	}
+	if result == nil || result.Generations != 0 {
+		t.Errorf("expected an empty partial result, got %+v", result)
+	}
+}
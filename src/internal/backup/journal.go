@@ -0,0 +1,215 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+)
+
+// RestoreJournalFilename is the default restore journal file name in the output folder
+const RestoreJournalFilename = "restore_journal.jsonl"
+
+// TempSuffix is appended to a target path while its copy is in progress. The file is renamed to
+// the target only once it is complete and verified.
+const TempSuffix = ".restoring"
+
+// RestoreStep is a stage of restoring a single file
+type RestoreStep string
+
+const (
+	StepStarted  RestoreStep = "started"  // Copy to the temporary file began
+	StepCopied   RestoreStep = "copied"   // Temporary file written and synced
+	StepVerified RestoreStep = "verified" // Temporary file checked by size and checksum
+	StepDone     RestoreStep = "done"     // Temporary file renamed to the target
+	StepFailed   RestoreStep = "failed"   // Copy abandoned; the temporary file was removed
+	StepUndone   RestoreStep = "undone"   // Restored file deleted again by an undo
+)
+
+// RestoreJournalEntry records one step of restoring one file. Completed files carry the size and
+// SHA-256 of the content written, so later runs can tell whether the file was changed since.
+type RestoreJournalEntry struct {
+	Run      string      `json:"run"` // Restore run that wrote the file, e.g. 20250907-101500
+	Hash     string      `json:"hash"`
+	Source   string      `json:"source"`
+	Target   string      `json:"target"`
+	TempPath string      `json:"temp_path"`
+	Step     RestoreStep `json:"step"`
+	Bytes    int64       `json:"bytes,omitempty"`
+	SHA256   string      `json:"sha256,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Time     time.Time   `json:"time"`
+}
+
+// RestoreJournal is an append-only, one-JSON-object-per-line record of every step of every restore.
+// Each step is written before the next one starts, so after a crash the journal shows exactly which
+// copies were in flight. It is never truncated: it also records which files restores created.
+type RestoreJournal struct {
+	path    string
+	run     string // ID recorded on the entries of this run
+	file    *os.File
+	mu      sync.Mutex
+	entries map[string]RestoreJournalEntry // Target -> latest entry
+}
+
+// OpenRestoreJournal opens the journal at path, loading the entries of previous runs and appending new ones
+func OpenRestoreJournal(path string) (*RestoreJournal, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create journal directory: %w", err)
+		}
+	}
+
+	j, err := LoadRestoreJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	j.run = time.Now().Format("20060102-150405")
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open restore journal %s: %w", path, err)
+	}
+	j.file = file
+
+	// End a line cut short by a killed run, so the next entry starts on its own line
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if reader, err := os.Open(path); err == nil {
+			_, readErr := reader.ReadAt(last, info.Size()-1)
+			reader.Close()
+			if readErr == nil && last[0] != '\n' {
+				if _, err := file.Write([]byte{'\n'}); err != nil {
+					file.Close()
+					return nil, fmt.Errorf("failed to write restore journal %s: %w", path, err)
+				}
+			}
+		}
+	}
+
+	return j, nil
+}
+
+// LoadRestoreJournal reads the journal at path without opening it for writing, so it can be inspected
+// even when it is read-only. Nothing can be recorded to a loaded journal.
+func LoadRestoreJournal(path string) (*RestoreJournal, error) {
+	j := &RestoreJournal{
+		path:    path,
+		entries: make(map[string]RestoreJournalEntry),
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// load reads the entries of an existing journal; later entries for a target replace earlier ones
+func (j *RestoreJournal) load() error {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open restore journal %s: %w", j.path, err)
+	}
+	defer file.Close()
+
+	logger := logging.GetLogger()
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry RestoreJournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// The last line may be cut short if the previous run was killed mid-write
+			logger.Verbose("Skipping unreadable restore journal line %d: %v", line, err)
+			continue
+		}
+		j.entries[entry.Target] = entry
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read restore journal %s: %w", j.path, err)
+	}
+	return nil
+}
+
+// Record appends a step to the journal and syncs it to disk before returning.
+// Entries without a run are recorded for the current run.
+func (j *RestoreJournal) Record(entry RestoreJournalEntry) error {
+	if entry.Run == "" {
+		entry.Run = j.run
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode restore journal entry for %s: %w", entry.Target, err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return fmt.Errorf("restore journal %s was only loaded for reading", j.path)
+	}
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write restore journal %s: %w", j.path, err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync restore journal %s: %w", j.path, err)
+	}
+	j.entries[entry.Target] = entry
+	return nil
+}
+
+// Latest returns the last step recorded for a target, if any
+func (j *RestoreJournal) Latest(target string) (RestoreJournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry, ok := j.entries[target]
+	return entry, ok
+}
+
+// Entries returns the last step recorded for every target, sorted by target
+func (j *RestoreJournal) Entries() []RestoreJournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]RestoreJournalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Target < entries[b].Target })
+	return entries
+}
+
+// Run returns the ID recorded on the entries of this run
+func (j *RestoreJournal) Run() string {
+	return j.run
+}
+
+// Path returns the journal file path
+func (j *RestoreJournal) Path() string {
+	return j.path
+}
+
+// Close closes the journal file
+func (j *RestoreJournal) Close() error {
+	if j.file == nil {
+		return nil
+	}
+	return j.file.Close()
+}
@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreResumesFromJournal(t *testing.T) {
+	root := t.TempDir()
+	backupAssets := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets")
+	writeFile(t, filepath.Join(backupAssets, "aaaaaaaa.jpg"), "complete image")
+	writeFile(t, filepath.Join(backupAssets, "bbbbbbbb.png"), "renamed before the crash")
+	result, err := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{}).SearchForAssets(context.Background(), []string{"aaaaaaaa", "bbbbbbbb"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+
+	// A previous restore crashed mid-copy of aaaaaaaa and after renaming bbbbbbbb
+	assets := t.TempDir()
+	journalPath := filepath.Join(t.TempDir(), RestoreJournalFilename)
+	journal, err := OpenRestoreJournal(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := filepath.Join(assets, "aaaaaaaa.jpg")
+	writeFile(t, partial+TempSuffix, "compl")
+	journal.Record(RestoreJournalEntry{Hash: "aaaaaaaa", Target: partial, TempPath: partial + TempSuffix, Step: StepStarted})
+	renamed := filepath.Join(assets, "bbbbbbbb.png")
+	writeFile(t, renamed, "renamed before the crash")
+	sum, _ := sumFile(renamed)
+	journal.Record(RestoreJournalEntry{Hash: "bbbbbbbb", Target: renamed, TempPath: renamed + TempSuffix, Step: StepVerified, SHA256: sum})
+	journal.file.WriteString(`{"hash":"cut short`) // Killed mid-write
+	journal.Close()
+
+	journal, err = OpenRestoreJournal(journalPath)
+	if err != nil {
+		t.Fatalf("OpenRestoreJournal: %v", err)
+	}
+	defer journal.Close()
+	restorer := NewRestorer(assets)
+	restorer.SetJournal(journal)
+
+	restored, err := restorer.RestoreAssets(context.Background(), result)
+	if err != nil {
+		t.Fatalf("RestoreAssets: %v", err)
+	}
+	if len(restored.RestoredFiles) != 1 || restored.RestoredFiles[0] != "aaaaaaaa" || len(restored.SkippedFiles) != 1 {
+		t.Fatalf("expected aaaaaaaa copied again and bbbbbbbb kept, got %+v", restored)
+	}
+	if got, err := os.ReadFile(partial); err != nil || string(got) != "complete image" {
+		t.Errorf("%s: got %q, %v", partial, got, err)
+	}
+	if _, err := os.Stat(partial + TempSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected no temporary file left, got %v", err)
+	}
+
+	for _, target := range []string{partial, renamed} {
+		entry, ok := journal.Latest(target)
+		if !ok || entry.Step != StepDone || entry.SHA256 == "" {
+			t.Errorf("%s: expected a completed journal entry with checksum, got %+v", target, entry)
+		}
+	}
+}
+
+func TestRestoreRejectsShortCopy(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "cccccccc.pdf")
+	writeFile(t, source, "%PDF truncated")
+
+	assets := t.TempDir()
+	restorer := NewRestorer(assets)
+	plan := restorer.PlanRestore(&SearchResult{FoundFiles: map[string][]BackupFile{
+		"cccccccc": {{Path: source, Hash: "cccccccc", RelativePath: "cccccccc.pdf", Size: 1 << 20}},
+	}})
+
+	restored, err := restorer.ApplyPlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyPlan: %v", err)
+	}
+	if len(restored.FailedFiles) != 1 {
+		t.Fatalf("expected the short copy to fail, got %+v", restored)
+	}
+	entries, _ := os.ReadDir(assets)
+	if len(entries) != 0 {
+		t.Errorf("expected nothing left in the assets folder, got %v", entries)
+	}
+}
@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ProvenanceFilename is the provenance log in the output folder
+const ProvenanceFilename = "recovery_provenance.jsonl"
+
+// Provenance records where a recovered file came from, so it can be traced after it is back in
+// the assets folder
+type Provenance struct {
+	Hash        string    `json:"hash"`
+	File        string    `json:"file"`                // Path written in the assets folder
+	Source      string    `json:"source"`              // Recovery source, e.g. replica
+	Origin      string    `json:"origin"`              // Backup root or server the file came from
+	Location    string    `json:"location"`            // Path of the file within its origin
+	CanvasID    string    `json:"canvas_id,omitempty"` // Canvas through which a server served the file
+	Bytes       int64     `json:"bytes"`
+	Algorithm   string    `json:"algorithm,omitempty"` // Digest the content was checked with
+	Verified    bool      `json:"verified"`            // Content hashes to the asset hash
+	RecoveredAt time.Time `json:"recovered_at"`
+	RecoveredBy string    `json:"recovered_by,omitempty"` // Account used to download the file
+}
+
+// NewProvenance describes a file restored from a plan entry
+func NewProvenance(entry PlanEntry, status FileStatus, algorithm string) Provenance {
+	record := Provenance{
+		Hash:        entry.Hash,
+		File:        status.TargetPath,
+		Source:      entry.Source.Source,
+		Origin:      entry.Source.Root,
+		Location:    entry.Source.Path,
+		CanvasID:    entry.Source.CanvasID,
+		Bytes:       status.Bytes,
+		Verified:    status.Verified,
+		RecoveredAt: time.Now(),
+	}
+	if status.Verified {
+		record.Algorithm = algorithm
+	}
+	return record
+}
+
+// AppendProvenance adds records to a provenance log, one JSON object per line. Earlier records are
+// kept, so the log covers every recovery run.
+func AppendProvenance(path string, records []Provenance) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open provenance log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write provenance log %s: %w", path, err)
+		}
+	}
+	return file.Sync()
+}
+
+// LoadProvenance reads every record of a provenance log
+func LoadProvenance(path string) ([]Provenance, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open provenance log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	records := make([]Provenance, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Provenance
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid provenance record at %s:%d: %w", path, line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read provenance log %s: %w", path, err)
+	}
+	return records, nil
+}
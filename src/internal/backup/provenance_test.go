@@ -0,0 +1,37 @@
+package backup
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProvenanceLogKeepsEveryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ProvenanceFilename)
+	entry := PlanEntry{
+		Hash:   "aaa",
+		Source: BackupFile{Path: "https://staging/assets/aaa", Root: "https://staging", Source: "replica", CanvasID: "c1"},
+	}
+
+	first := NewProvenance(entry, FileStatus{Hash: "aaa", TargetPath: "/assets/aaa.jpg", Bytes: 42, Verified: true}, "sha256")
+	second := NewProvenance(entry, FileStatus{Hash: "aaa", TargetPath: "/assets/aaa.jpg", Bytes: 42}, "sha256")
+	if err := AppendProvenance(path, []Provenance{first}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendProvenance(path, []Provenance{second}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := LoadProvenance(path)
+	if err != nil {
+		t.Fatalf("LoadProvenance: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %+v", records)
+	}
+	if r := records[0]; r.Origin != "https://staging" || r.CanvasID != "c1" || r.Source != "replica" || r.Algorithm != "sha256" || !r.Verified {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if records[1].Algorithm != "" {
+		t.Errorf("expected no algorithm on an unverified record, got %q", records[1].Algorithm)
+	}
+}
@@ -1,114 +1,396 @@
 package backup
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
 	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
 )
 
 // Restorer handles copying backup files to the assets folder
 type Restorer struct {
 	assetsFolder string
+	algorithm    *digest.Algorithm // Digest used for asset file names; nil if unknown
+	sources      map[string]RecoverySource
+	journal      *RestoreJournal // nil = steps are not recorded
 	logger       *logging.Logger
 }
 
+// checksum is the digest recorded in the restore journal for every file written
+var checksum = digest.Algorithm{Name: "sha256", New: sha256.New, Length: sha256.Size * 2}
+
 // NewRestorer creates a new backup restorer
 func NewRestorer(assetsFolder string) *Restorer {
 	return &Restorer{
 		assetsFolder: assetsFolder,
+		sources:      make(map[string]RecoverySource),
 		logger:       logging.GetLogger(),
 	}
 }
 
+// SetHashAlgorithm makes the restorer check that every restored file hashes to its asset hash.
+// A restored file that does not is removed again and reported as failed.
+func (r *Restorer) SetHashAlgorithm(algorithm *digest.Algorithm) {
+	r.algorithm = algorithm
+}
+
+// SetJournal makes the restorer record every step of every copy in journal. Copies left unfinished
+// by an interrupted restore are cleaned up when the next plan is applied, and copied again.
+func (r *Restorer) SetJournal(journal *RestoreJournal) {
+	r.journal = journal
+}
+
+// RegisterSource makes the restorer open the candidates of a recovery source through it.
+// Backup, archive and harvested files can always be opened; other sources (such as a Canvus
+// Server) must be registered before a plan using them is applied.
+func (r *Restorer) RegisterSource(source RecoverySource) {
+	r.sources[source.Name()] = source
+}
+
+// open streams the content of a plan entry's source file
+func (r *Restorer) open(ctx context.Context, file BackupFile) (io.ReadCloser, error) {
+	if source, ok := r.sources[file.Source]; ok {
+		return source.Open(ctx, file)
+	}
+
+	switch file.Source {
+	case "", SourceBackup, SourceArchive, SourceHarvest: // Manifests written before sources were recorded have none
+		return OpenBackupFile(file)
+	default:
+		return nil, fmt.Errorf("recovery source %s is not available to open %s", file.Source, file.Path)
+	}
+}
+
 // RestoreResult contains the results of a restoration operation
 type RestoreResult struct {
-	RestoredFiles []string // List of successfully restored files
-	FailedFiles   []string // List of files that failed to restore
-	TotalBytes    int64    // Total bytes restored
-	Errors        []string // List of error messages
+	RestoredFiles []string     // List of successfully restored files
+	FailedFiles   []string     // List of files that failed to restore
+	SkippedFiles  []string     // List of files that already existed in the assets folder
+	TotalBytes    int64        // Total bytes restored
+	Errors        []string     // List of error messages
+	Files         []FileStatus // Per-file outcome, in plan order
+	Interrupted   bool         // The restore was cancelled before every entry was processed
+}
+
+// RestoreStatus describes the outcome of restoring a single file
+type RestoreStatus string
+
+const (
+	StatusRestored RestoreStatus = "restored"
+	StatusSkipped  RestoreStatus = "skipped"
+	StatusFailed   RestoreStatus = "failed"
+)
+
+// FileStatus records what happened to a single plan entry during a restore
+type FileStatus struct {
+	Hash       string        `json:"hash"`
+	SourcePath string        `json:"source_path"`
+	TargetPath string        `json:"target_path"`
+	Status     RestoreStatus `json:"status"`
+	Bytes      int64         `json:"bytes"`
+	Verified   bool          `json:"verified,omitempty"` // Restored content hashes to the asset hash
+	Error      string        `json:"error,omitempty"`
+}
+
+// PlanAction describes what a restore will do with a plan entry
+type PlanAction string
+
+const (
+	ActionCopy PlanAction = "copy"
+	ActionSkip PlanAction = "skip" // Target already exists
+)
+
+// PlanEntry maps a missing asset hash to the backup file chosen to restore it
+type PlanEntry struct {
+	Hash       string     `json:"hash"`
+	Source     BackupFile `json:"source"`
+	TargetPath string     `json:"target_path"`
+	Candidates int        `json:"candidates"` // Number of backup copies that were available
+	Action     PlanAction `json:"action"`
+}
+
+// RestorePlan is the list of copy operations a restore will perform
+type RestorePlan struct {
+	AssetsFolder string      `json:"assets_folder"`
+	CreatedAt    time.Time   `json:"created_at"`
+	Entries      []PlanEntry `json:"entries"`
+	Unresolved   []string    `json:"unresolved"` // Hashes with no backup candidate
+}
+
+// TotalBytes returns the number of bytes the plan will copy
+func (p *RestorePlan) TotalBytes() int64 {
+	var total int64
+	for _, entry := range p.Entries {
+		if entry.Action == ActionCopy {
+			total += entry.Source.Size
+		}
+	}
+	return total
+}
+
+// CountByAction returns the number of entries with the given action
+func (p *RestorePlan) CountByAction(action PlanAction) int {
+	count := 0
+	for _, entry := range p.Entries {
+		if entry.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// CountBySource returns the number of copy entries whose file was found by the named recovery source
+func (p *RestorePlan) CountBySource(source string) int {
+	count := 0
+	for _, entry := range p.Entries {
+		if entry.Action == ActionCopy && entry.Source.Source == source {
+			count++
+		}
+	}
+	return count
+}
+
+// PlanRestore builds a restore plan from a backup search result without touching the filesystem
+// The search result must already be sorted so the preferred backup file is first for each hash
+func (r *Restorer) PlanRestore(searchResult *SearchResult) *RestorePlan {
+	plan := &RestorePlan{
+		AssetsFolder: r.assetsFolder,
+		CreatedAt:    time.Now(),
+		Entries:      make([]PlanEntry, 0, len(searchResult.FoundFiles)),
+		Unresolved:   make([]string, 0, len(searchResult.MissingHashes)),
+	}
+
+	hashes := make([]string, 0, len(searchResult.FoundFiles))
+	for hash := range searchResult.FoundFiles {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	for _, hash := range hashes {
+		backupFiles := searchResult.FoundFiles[hash]
+		if len(backupFiles) == 0 {
+			plan.Unresolved = append(plan.Unresolved, hash)
+			continue
+		}
+
+		// Use the preferred backup file (first in the sorted list)
+		backupFile := backupFiles[0]
+		targetPath := r.getAssetPath(backupFile.RelativePath)
+
+		action := ActionCopy
+		if r.pathExists(targetPath) {
+			action = ActionSkip
+		}
+
+		plan.Entries = append(plan.Entries, PlanEntry{
+			Hash:       hash,
+			Source:     backupFile,
+			TargetPath: targetPath,
+			Candidates: len(backupFiles),
+			Action:     action,
+		})
+	}
+
+	plan.Unresolved = append(plan.Unresolved, searchResult.MissingHashes...)
+	sort.Strings(plan.Unresolved)
+
+	return plan
 }
 
 // RestoreAssets copies backup files to the assets folder, preserving folder structure
-func (r *Restorer) RestoreAssets(searchResult *SearchResult) (*RestoreResult, error) {
+func (r *Restorer) RestoreAssets(ctx context.Context, searchResult *SearchResult) (*RestoreResult, error) {
+	return r.ApplyPlan(ctx, r.PlanRestore(searchResult))
+}
+
+// ApplyPlan performs the copy operations described by a restore plan.
+// If ctx is cancelled the file being copied is finished, the remaining entries are left
+// untouched and the partial result is returned together with the context error.
+func (r *Restorer) ApplyPlan(ctx context.Context, plan *RestorePlan) (*RestoreResult, error) {
 	result := &RestoreResult{
 		RestoredFiles: make([]string, 0),
 		FailedFiles:   make([]string, 0),
+		SkippedFiles:  make([]string, 0),
 		TotalBytes:    0,
 		Errors:        make([]string, 0),
+		Files:         make([]FileStatus, 0, len(plan.Entries)),
 	}
 
-	if len(searchResult.FoundFiles) == 0 {
+	if len(plan.Entries) == 0 {
 		r.logger.Info("No backup files to restore")
 		return result, nil
 	}
 
-	r.logger.Info("🔄 Restoring %d assets to: %s (preserving folder structure)", len(searchResult.FoundFiles), r.assetsFolder)
+	r.logger.Info("🔄 Restoring %d assets to: %s (preserving folder structure)", len(plan.Entries), r.assetsFolder)
 
 	// Ensure assets folder exists
 	if err := os.MkdirAll(r.assetsFolder, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create assets folder: %w", err)
 	}
 
-	// Restore each found asset
-	for hash, backupFiles := range searchResult.FoundFiles {
-		if len(backupFiles) == 0 {
-			continue
+	// Clean up after a restore that was interrupted mid-copy
+	r.resumeJournal()
+
+	// Restore each planned asset; a file being copied is finished even if ctx is cancelled
+	copyCtx := context.WithoutCancel(ctx)
+	for _, entry := range plan.Entries {
+		if ctx.Err() != nil {
+			result.Interrupted = true
+			break
 		}
 
-		// Use the newest backup file (first in the sorted list)
-		backupFile := backupFiles[0]
+		status := r.restoreSingleFile(copyCtx, entry)
+		result.Files = append(result.Files, status)
 
-		err := r.restoreSingleFile(backupFile, result)
-		if err != nil {
-			r.logger.Error("Failed to restore %s: %v", hash, err)
-			result.FailedFiles = append(result.FailedFiles, hash)
-			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", hash, err))
-		} else {
-			targetPath := r.getAssetPath(backupFile.RelativePath)
-			r.logger.Verbose("✅ Restored: %s -> %s", backupFile.Path, targetPath)
+		switch status.Status {
+		case StatusRestored:
+			result.RestoredFiles = append(result.RestoredFiles, entry.Hash)
+			result.TotalBytes += status.Bytes
+			r.logger.Verbose("✅ Restored: %s -> %s", status.SourcePath, status.TargetPath)
+		case StatusSkipped:
+			result.SkippedFiles = append(result.SkippedFiles, entry.Hash)
+			r.logger.Verbose("⏭️  Asset already exists, skipping: %s", status.TargetPath)
+		case StatusFailed:
+			r.logger.Error("Failed to restore %s: %s", entry.Hash, status.Error)
+			result.FailedFiles = append(result.FailedFiles, entry.Hash)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", entry.Hash, status.Error))
 		}
 	}
 
 	r.logger.Info("✅ Restoration completed:")
 	r.logger.Info("   ✅ Files restored: %d", len(result.RestoredFiles))
+	r.logger.Info("   ⏭️  Files skipped: %d", len(result.SkippedFiles))
 	r.logger.Info("   ❌ Files failed: %d", len(result.FailedFiles))
 	r.logger.Info("   📊 Total bytes: %d", result.TotalBytes)
 
+	if result.Interrupted {
+		r.logger.Warn("Restore interrupted after %d of %d entries", len(result.Files), len(plan.Entries))
+		return result, fmt.Errorf("restore interrupted: %w", ctx.Err())
+	}
+
 	return result, nil
 }
 
-// restoreSingleFile copies a single backup file to the assets folder, preserving folder structure
-func (r *Restorer) restoreSingleFile(backupFile BackupFile, result *RestoreResult) error {
-	// Determine the target path (preserving relative folder structure)
-	targetPath := r.getAssetPath(backupFile.RelativePath)
+// restoreSingleFile copies a single backup file to a temporary file next to its planned target,
+// verifies it and renames it into place, so the target never holds a partial or unverified copy
+func (r *Restorer) restoreSingleFile(ctx context.Context, entry PlanEntry) FileStatus {
+	status := FileStatus{
+		Hash:       entry.Hash,
+		SourcePath: entry.Source.Path,
+		TargetPath: entry.TargetPath,
+	}
 
-	// Check if target file already exists
-	if r.pathExists(targetPath) {
-		r.logger.Verbose("Asset already exists, skipping: %s", targetPath)
-		result.RestoredFiles = append(result.RestoredFiles, backupFile.Hash)
-		return nil
+	// Check if target file already exists (it may have appeared since the plan was built)
+	if r.pathExists(entry.TargetPath) {
+		status.Status = StatusSkipped
+		return status
 	}
 
 	// Ensure the target directory exists
-	targetDir := filepath.Dir(targetPath)
+	targetDir := filepath.Dir(entry.TargetPath)
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("failed to create target directory %s: %w", targetDir, err)
+		status.Status = StatusFailed
+		status.Error = fmt.Sprintf("failed to create target directory %s: %v", targetDir, err)
+		return status
+	}
+
+	step := RestoreJournalEntry{
+		Hash:     entry.Hash,
+		Source:   entry.Source.Path,
+		Target:   entry.TargetPath,
+		TempPath: entry.TargetPath + TempSuffix,
+	}
+	fail := func(format string, args ...interface{}) FileStatus {
+		os.Remove(step.TempPath)
+		status.Status = StatusFailed
+		status.Error = fmt.Sprintf(format, args...)
+		step.Error = status.Error
+		r.record(step, StepFailed)
+		return status
 	}
 
-	// Copy the file
-	err := r.copyFile(backupFile.Path, targetPath)
+	// Copy the file (or extract it from its archive) to the temporary file
+	r.record(step, StepStarted)
+	written, sum, err := r.copyFile(ctx, entry.Source, step.TempPath)
 	if err != nil {
-		return fmt.Errorf("failed to copy file: %w", err)
+		return fail("failed to copy file: %v", err)
 	}
+	step.Bytes, step.SHA256 = written, sum
+	r.record(step, StepCopied)
 
-	// Update result
-	result.RestoredFiles = append(result.RestoredFiles, backupFile.Hash)
-	result.TotalBytes += backupFile.Size
+	// Check the copy so a bad one is never put in the assets folder
+	verified, err := r.verifyCopy(entry, step.TempPath, written, sum)
+	if err != nil {
+		return fail("%v", err)
+	}
+	r.record(step, StepVerified)
 
-	return nil
+	if r.pathExists(entry.TargetPath) {
+		os.Remove(step.TempPath)
+		step.Error = "target appeared during the copy"
+		r.record(step, StepFailed)
+		status.Status = StatusSkipped
+		return status
+	}
+	if err := os.Rename(step.TempPath, entry.TargetPath); err != nil {
+		return fail("failed to move verified copy into place: %v", err)
+	}
+	step.Error = ""
+	r.record(step, StepDone)
+
+	status.Status = StatusRestored
+	status.Bytes = written
+	status.Verified = verified
+	return status
+}
+
+// verifyCopy checks a finished temporary file: its size must match what was written and the
+// source size, and its content must still have the checksum computed while writing. When the
+// asset hash algorithm is known the content must also hash to the asset hash, which is reported.
+func (r *Restorer) verifyCopy(entry PlanEntry, tempPath string, written int64, sum string) (bool, error) {
+	if entry.Source.Size > 0 && written != entry.Source.Size {
+		return false, fmt.Errorf("copied %d bytes but the source has %d", written, entry.Source.Size)
+	}
+
+	info, err := os.Stat(tempPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to verify restored file: %w", err)
+	}
+	if info.Size() != written {
+		return false, fmt.Errorf("restored file has %d bytes, %d were written", info.Size(), written)
+	}
+
+	algorithms := []digest.Algorithm{checksum}
+	if r.algorithm != nil {
+		algorithms = append(algorithms, *r.algorithm)
+	}
+	file, err := os.Open(tempPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to verify restored file: %w", err)
+	}
+	sums, err := digest.MultiSum(file, algorithms)
+	file.Close()
+	if err != nil {
+		return false, fmt.Errorf("failed to verify restored file: %w", err)
+	}
+
+	if sums[0] != sum {
+		return false, fmt.Errorf("restored file changed after it was written")
+	}
+	if r.algorithm == nil {
+		return false, nil
+	}
+	if !r.algorithm.Matches(sums[1], entry.Hash) {
+		return false, fmt.Errorf("restored content does not hash to %s (%s)", entry.Hash, r.algorithm)
+	}
+	return true, nil
 }
 
 // getAssetPath returns the full path for an asset file, preserving folder structure
@@ -116,35 +398,96 @@ func (r *Restorer) getAssetPath(relativePath string) string {
 	return filepath.Join(r.assetsFolder, relativePath)
 }
 
-// copyFile copies a file from source to destination
-func (r *Restorer) copyFile(src, dst string) error {
+// copyFile copies a candidate to destination and returns the number of bytes written and the
+// checksum of the content. Archive members are extracted on their own, without unpacking the
+// rest of the archive.
+func (r *Restorer) copyFile(ctx context.Context, src BackupFile, dst string) (int64, string, error) {
 	// Open source file
-	srcFile, err := os.Open(src)
+	srcFile, err := r.open(ctx, src)
 	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
+		return 0, "", err
 	}
 	defer srcFile.Close()
 
-	// Create destination file
-	dstFile, err := os.Create(dst)
+	// Create destination file, replacing what an interrupted copy left behind
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
+		return 0, "", fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer dstFile.Close()
 
-	// Copy the file
-	_, err = io.Copy(dstFile, srcFile)
+	// Copy the file, hashing it on the way
+	hasher := checksum.New()
+	written, err := io.Copy(io.MultiWriter(dstFile, hasher), srcFile)
 	if err != nil {
-		return fmt.Errorf("failed to copy file content: %w", err)
+		return written, "", fmt.Errorf("failed to copy file content: %w", err)
 	}
 
 	// Ensure the file is written to disk
 	err = dstFile.Sync()
 	if err != nil {
-		return fmt.Errorf("failed to sync file: %w", err)
+		return written, "", fmt.Errorf("failed to sync file: %w", err)
+	}
+
+	return written, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// record journals a step of a copy; a journal that cannot be written only costs resumability
+func (r *Restorer) record(entry RestoreJournalEntry, step RestoreStep) {
+	if r.journal == nil {
+		return
+	}
+	entry.Step = step
+	entry.Time = time.Time{}
+	if err := r.journal.Record(entry); err != nil {
+		r.logger.Warn("Failed to record restore step: %v", err)
+	}
+}
+
+// resumeJournal cleans up copies a previous restore left unfinished. A copy that was verified but
+// not journaled as renamed is accepted if the target holds exactly the verified content; every
+// other unfinished copy has its temporary file removed and is copied again.
+func (r *Restorer) resumeJournal() {
+	if r.journal == nil {
+		return
 	}
 
-	return nil
+	completed, cleaned := 0, 0
+	for _, entry := range r.journal.Entries() {
+		switch entry.Step {
+		case StepStarted, StepCopied, StepVerified:
+		default:
+			continue
+		}
+
+		if entry.Step == StepVerified && entry.SHA256 != "" {
+			if sum, err := sumFile(entry.Target); err == nil && sum == entry.SHA256 {
+				r.record(entry, StepDone)
+				completed++
+				continue
+			}
+		}
+		if err := os.Remove(entry.TempPath); err != nil && !os.IsNotExist(err) {
+			r.logger.Warn("Failed to remove unfinished copy %s: %v", entry.TempPath, err)
+		}
+		entry.Error = "interrupted; temporary file removed"
+		r.record(entry, StepFailed)
+		cleaned++
+	}
+
+	if completed > 0 || cleaned > 0 {
+		r.logger.Info("♻️  Resuming from restore journal: %d interrupted copies cleaned up and retried, %d completed before the interruption", cleaned, completed)
+	}
+}
+
+// sumFile returns the journal checksum of a file
+func sumFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return checksum.Sum(file)
 }
 
 // pathExists checks if a path exists
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+)
+
+func TestPlanAndApplyRestore(t *testing.T) {
+	backupAssets := t.TempDir()
+	for name, content := range map[string]string{"aaaaaaaa.jpg": "missing image", "bbbbbbbb.png": "already restored"} {
+		if err := os.WriteFile(filepath.Join(backupAssets, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assets := t.TempDir()
+	if err := os.WriteFile(filepath.Join(assets, "bbbbbbbb.png"), []byte("already restored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restorer := NewRestorer(assets)
+	plan := restorer.PlanRestore(&SearchResult{
+		FoundFiles: map[string][]BackupFile{
+			"aaaaaaaa": {{Path: filepath.Join(backupAssets, "aaaaaaaa.jpg"), Hash: "aaaaaaaa", RelativePath: "aaaaaaaa.jpg", Size: 13}},
+			"bbbbbbbb": {{Path: filepath.Join(backupAssets, "bbbbbbbb.png"), Hash: "bbbbbbbb", RelativePath: "bbbbbbbb.png", Size: 16}},
+		},
+		MissingHashes: []string{"cccccccc"},
+	})
+
+	if len(plan.Entries) != 2 || plan.Entries[0].Hash != "aaaaaaaa" || plan.Entries[1].Hash != "bbbbbbbb" {
+		t.Fatalf("expected plan entries sorted by hash, got %+v", plan.Entries)
+	}
+	if plan.Entries[0].Action != ActionCopy || plan.Entries[0].TargetPath != filepath.Join(assets, "aaaaaaaa.jpg") {
+		t.Errorf("expected aaaaaaaa to be copied into the assets folder, got %+v", plan.Entries[0])
+	}
+	if plan.Entries[1].Action != ActionSkip {
+		t.Errorf("expected bbbbbbbb to be skipped, got %+v", plan.Entries[1])
+	}
+	if len(plan.Unresolved) != 1 || plan.Unresolved[0] != "cccccccc" {
+		t.Errorf("expected cccccccc unresolved, got %v", plan.Unresolved)
+	}
+	if plan.TotalBytes() != 13 {
+		t.Errorf("expected 13 bytes to copy, got %d", plan.TotalBytes())
+	}
+	if entries, _ := os.ReadDir(assets); len(entries) != 1 {
+		t.Errorf("planning must not touch the assets folder, got %v", entries)
+	}
+
+	result, err := restorer.ApplyPlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyPlan: %v", err)
+	}
+	if len(result.RestoredFiles) != 1 || len(result.SkippedFiles) != 1 || len(result.FailedFiles) != 0 {
+		t.Fatalf("expected one file restored and one skipped, got %+v", result)
+	}
+	if len(result.Files) != 2 || result.Files[0].Status != StatusRestored || result.Files[1].Status != StatusSkipped {
+		t.Errorf("expected per-file status in plan order, got %+v", result.Files)
+	}
+	if got, err := os.ReadFile(filepath.Join(assets, "aaaaaaaa.jpg")); err != nil || string(got) != "missing image" {
+		t.Errorf("restored file: got %q, %v", got, err)
+	}
+}
+
+func TestRestoreChecksContentHash(t *testing.T) {
+	sum := sha256.Sum256([]byte("intact"))
+	good := hex.EncodeToString(sum[:])
+	sum = sha256.Sum256([]byte("original"))
+	bad := hex.EncodeToString(sum[:])
+
+	root := t.TempDir()
+	assets := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets")
+	writeFile(t, filepath.Join(assets, good+".png"), "intact")
+	writeFile(t, filepath.Join(assets, bad+".png"), "damaged")
+
+	result, err := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{}).SearchForAssets(context.Background(), []string{good, bad})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+
+	algorithm, err := digest.Parse("sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := t.TempDir()
+	restorer := NewRestorer(target)
+	restorer.SetHashAlgorithm(&algorithm)
+
+	restored, err := restorer.RestoreAssets(context.Background(), result)
+	if err != nil {
+		t.Fatalf("RestoreAssets: %v", err)
+	}
+	if len(restored.RestoredFiles) != 1 || restored.RestoredFiles[0] != good {
+		t.Errorf("expected only %s to be restored, got %v", good, restored.RestoredFiles)
+	}
+	if len(restored.FailedFiles) != 1 || restored.FailedFiles[0] != bad {
+		t.Errorf("expected %s to fail verification, got %v", bad, restored.FailedFiles)
+	}
+	for _, file := range restored.Files {
+		if file.Hash == good && !file.Verified {
+			t.Errorf("expected %s to be marked verified", good)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(target, bad+".png")); !os.IsNotExist(err) {
+		t.Errorf("expected the unverified copy to be removed, got %v", err)
+	}
+}
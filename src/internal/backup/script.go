@@ -0,0 +1,339 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+)
+
+// Files written by EmitScript
+const (
+	ScriptFolderName   = "restore_script"
+	ScriptFilename     = "restore.ps1"
+	HashManifestName   = "restore_hashes.csv"
+	robocopyJobPattern = "robocopy_%03d.rcj"
+)
+
+// ScriptEntry is one file the emitted script copies, with the content it must end up with
+type ScriptEntry struct {
+	Hash   string
+	Source string
+	Target string
+	Bytes  int64
+	SHA256 string
+}
+
+// RestoreScript describes a restore plan exported for someone else to run
+type RestoreScript struct {
+	Folder     string
+	Script     string            // PowerShell script that copies and verifies every entry
+	Manifest   string            // CSV of target, source, size and SHA-256 both the script and robocopy are checked against
+	JobFiles   []string          // Robocopy job files, one per source and target folder pair
+	Entries    []ScriptEntry     // Files the script restores
+	ScriptOnly []string          // Hashes robocopy cannot copy because the source has another file name
+	Excluded   map[string]string // Hash -> why the file could not be exported
+}
+
+// EmitScript exports the copies of a plan as a PowerShell script and robocopy job files, for an
+// account that can write to the assets folder. Every source is read once here, so the hash
+// manifest records the exact content to expect; sources that fail the checks a restore would make
+// are left out. Only plain files can be exported: archive members and server downloads need the
+// restore command itself.
+func (r *Restorer) EmitScript(ctx context.Context, plan *RestorePlan, folder string) (*RestoreScript, error) {
+	script := &RestoreScript{
+		Folder:   folder,
+		Script:   filepath.Join(folder, ScriptFilename),
+		Manifest: filepath.Join(folder, HashManifestName),
+		Excluded: make(map[string]string),
+	}
+
+	for _, entry := range plan.Entries {
+		if entry.Action != ActionCopy {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("script export interrupted: %w", err)
+		}
+
+		switch {
+		case entry.Source.Archive != "":
+			script.Excluded[entry.Hash] = fmt.Sprintf("inside archive %s", entry.Source.Archive)
+			continue
+		case entry.Source.Source != "" && entry.Source.Source != SourceBackup && entry.Source.Source != SourceHarvest:
+			script.Excluded[entry.Hash] = fmt.Sprintf("only available from %s", entry.Source.Source)
+			continue
+		}
+
+		sum, size, err := r.checkSource(entry)
+		if err != nil {
+			script.Excluded[entry.Hash] = err.Error()
+			continue
+		}
+		script.Entries = append(script.Entries, ScriptEntry{
+			Hash:   entry.Hash,
+			Source: entry.Source.Path,
+			Target: entry.TargetPath,
+			Bytes:  size,
+			SHA256: sum,
+		})
+	}
+
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create script folder: %w", err)
+	}
+
+	manifest, err := script.writeManifest()
+	if err != nil {
+		return nil, err
+	}
+	if err := script.writeScript(plan.AssetsFolder, manifest); err != nil {
+		return nil, err
+	}
+	if err := script.writeJobFiles(); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// checkSource reads a source file and returns its SHA-256 and size, checking them the way a
+// restore checks its copies
+func (r *Restorer) checkSource(entry PlanEntry) (string, int64, error) {
+	file, err := os.Open(entry.Source.Path)
+	if err != nil {
+		return "", 0, fmt.Errorf("cannot read source: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, fmt.Errorf("cannot read source: %v", err)
+	}
+	if entry.Source.Size > 0 && info.Size() != entry.Source.Size {
+		return "", 0, fmt.Errorf("source has %d bytes, %d when it was found", info.Size(), entry.Source.Size)
+	}
+
+	algorithms := []digest.Algorithm{checksum}
+	if r.algorithm != nil {
+		algorithms = append(algorithms, *r.algorithm)
+	}
+	sums, err := digest.MultiSum(file, algorithms)
+	if err != nil {
+		return "", 0, fmt.Errorf("cannot read source: %v", err)
+	}
+	if r.algorithm != nil && !r.algorithm.Matches(sums[1], entry.Hash) {
+		return "", 0, fmt.Errorf("source content does not hash to %s (%s)", entry.Hash, r.algorithm)
+	}
+	return sums[0], info.Size(), nil
+}
+
+// writeManifest writes the hash manifest and returns its SHA-256, which the script pins
+func (s *RestoreScript) writeManifest() (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff") // Lets Windows PowerShell read non-ASCII paths as UTF-8
+
+	writer := csv.NewWriter(&buf)
+	writer.UseCRLF = true
+	writer.Write([]string{"Hash", "Source", "Target", "Bytes", "SHA256"})
+	for _, entry := range s.Entries {
+		writer.Write([]string{entry.Hash, entry.Source, entry.Target, fmt.Sprintf("%d", entry.Bytes), entry.SHA256})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to encode hash manifest: %w", err)
+	}
+
+	if err := os.WriteFile(s.Manifest, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write hash manifest %s: %w", s.Manifest, err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return strings.ToUpper(hex.EncodeToString(sum[:])), nil
+}
+
+// writeScript writes the PowerShell script, pinned to the manifest it was generated with
+func (s *RestoreScript) writeScript(assetsFolder, manifestSum string) error {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	fmt.Fprintf(&buf, restoreScriptHeader, len(s.Entries), assetsFolder, time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "$manifestPath = Join-Path $PSScriptRoot %s\r\n", psQuote(HashManifestName))
+	fmt.Fprintf(&buf, "$manifestSha256 = %s\r\n", psQuote(manifestSum))
+	buf.WriteString(restoreScriptBody)
+
+	script := strings.ReplaceAll(buf.String(), "\r\n", "\n")
+	script = strings.ReplaceAll(script, "\n", "\r\n")
+	if err := os.WriteFile(s.Script, []byte(script), 0644); err != nil {
+		return fmt.Errorf("failed to write restore script %s: %w", s.Script, err)
+	}
+	return nil
+}
+
+// writeJobFiles writes a robocopy job per source and target folder pair. Robocopy copies files
+// under their own name, so entries whose source is named differently are left to the script.
+// Job files of an earlier export are removed first.
+func (s *RestoreScript) writeJobFiles() error {
+	stale, _ := filepath.Glob(filepath.Join(s.Folder, "robocopy_*.rcj"))
+	for _, path := range stale {
+		os.Remove(path)
+	}
+
+	type folders struct{ source, target string }
+	jobs := make(map[folders][]string)
+	for _, entry := range s.Entries {
+		name := filepath.Base(entry.Source)
+		if name != filepath.Base(entry.Target) {
+			s.ScriptOnly = append(s.ScriptOnly, entry.Hash)
+			continue
+		}
+		key := folders{filepath.Dir(entry.Source), filepath.Dir(entry.Target)}
+		jobs[key] = append(jobs[key], name)
+	}
+
+	keys := make([]folders, 0, len(jobs))
+	for key := range jobs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		return keys[i].target < keys[j].target
+	})
+
+	for i, key := range keys {
+		var buf strings.Builder
+		fmt.Fprintf(&buf, ":: Robocopy job generated by kpmg-db-solver restore --emit-script\r\n")
+		fmt.Fprintf(&buf, ":: Copies %d missing assets. Existing files are never overwritten.\r\n", len(jobs[key]))
+		fmt.Fprintf(&buf, ":: Run with: robocopy /JOB:%s\r\n", fmt.Sprintf(robocopyJobPattern, i+1))
+		fmt.Fprintf(&buf, ":: Then check the copies against %s with: .\\%s -VerifyOnly\r\n", HashManifestName, ScriptFilename)
+		fmt.Fprintf(&buf, "/SD:%s\t:: Source Directory\r\n", withSeparator(key.source))
+		fmt.Fprintf(&buf, "/DD:%s\t:: Destination Directory\r\n", withSeparator(key.target))
+		fmt.Fprintf(&buf, "/IF\t\t:: Include only these files\r\n")
+		for _, name := range jobs[key] {
+			fmt.Fprintf(&buf, "\t%s\r\n", name)
+		}
+		buf.WriteString("/COPY:DAT\t:: Copy data, attributes and timestamps\r\n")
+		buf.WriteString("/XC\t\t:: Never overwrite changed files\r\n")
+		buf.WriteString("/XN\t\t:: Never overwrite older files\r\n")
+		buf.WriteString("/XO\t\t:: Never overwrite newer files\r\n")
+		buf.WriteString("/R:2\t\t:: Retry twice\r\n")
+		buf.WriteString("/W:5\t\t:: Wait 5 seconds between retries\r\n")
+
+		path := filepath.Join(s.Folder, fmt.Sprintf(robocopyJobPattern, i+1))
+		if err := os.WriteFile(path, []byte(buf.String()), 0644); err != nil {
+			return fmt.Errorf("failed to write robocopy job %s: %w", path, err)
+		}
+		s.JobFiles = append(s.JobFiles, path)
+	}
+	return nil
+}
+
+// withSeparator ends a folder path with a separator, as robocopy writes them in job files
+func withSeparator(dir string) string {
+	if strings.HasSuffix(dir, string(filepath.Separator)) {
+		return dir
+	}
+	return dir + string(filepath.Separator)
+}
+
+// psQuote quotes a string as a PowerShell literal
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// restoreScriptHeader documents the script; it takes the entry count, assets folder and generation time
+const restoreScriptHeader = `<#
+.SYNOPSIS
+    Restores %d missing Canvus assets into %s.
+
+.DESCRIPTION
+    Generated by kpmg-db-solver restore --emit-script on %s.
+
+    Every file listed in restore_hashes.csv is copied to <target>.restoring, checked
+    against the size and SHA-256 in the manifest and only then renamed into place.
+    Targets that already hold the expected content are skipped, so the script can be
+    run again after an interruption. Targets with any other content are never
+    overwritten; they are reported and the script exits with code 1.
+
+    The script refuses to run if restore_hashes.csv was changed after it was generated.
+
+.PARAMETER VerifyOnly
+    Copy nothing; only check every target against the manifest, e.g. after running
+    the robocopy jobs.
+
+.EXAMPLE
+    .\restore.ps1 -WhatIf
+    .\restore.ps1
+    .\restore.ps1 -VerifyOnly
+#>
+[CmdletBinding(SupportsShouldProcess = $true)]
+param(
+    [switch]$VerifyOnly
+)
+
+$ErrorActionPreference = 'Stop'
+`
+
+// restoreScriptBody copies and verifies the manifest entries
+const restoreScriptBody = `
+if ((Get-FileHash -LiteralPath $manifestPath -Algorithm SHA256).Hash -ne $manifestSha256) {
+    throw "$manifestPath was changed after this script was generated; export the plan again"
+}
+
+$restored = 0
+$present = 0
+$problems = 0
+foreach ($entry in Import-Csv -LiteralPath $manifestPath -Encoding UTF8) {
+    $target = $entry.Target
+    if (Test-Path -LiteralPath $target) {
+        if ((Get-FileHash -LiteralPath $target -Algorithm SHA256).Hash -eq $entry.SHA256) {
+            $present++
+            continue
+        }
+        Write-Warning "$target exists with other content; left unchanged"
+        $problems++
+        continue
+    }
+    if ($VerifyOnly) {
+        Write-Warning "$target is missing"
+        $problems++
+        continue
+    }
+    if (-not $PSCmdlet.ShouldProcess($target, "Copy from $($entry.Source)")) {
+        continue
+    }
+
+    $temp = $target + '.restoring'
+    try {
+        New-Item -ItemType Directory -Path (Split-Path -Parent $target) -Force | Out-Null
+        Copy-Item -LiteralPath $entry.Source -Destination $temp -Force
+        $length = (Get-Item -LiteralPath $temp).Length
+        if ($length -ne [int64]$entry.Bytes) {
+            throw "copied $length bytes, expected $($entry.Bytes)"
+        }
+        if ((Get-FileHash -LiteralPath $temp -Algorithm SHA256).Hash -ne $entry.SHA256) {
+            throw "copy does not match SHA-256 $($entry.SHA256)"
+        }
+        Move-Item -LiteralPath $temp -Destination $target
+        Write-Host "Restored $target"
+        $restored++
+    } catch {
+        Remove-Item -LiteralPath $temp -Force -ErrorAction SilentlyContinue
+        Write-Warning "Failed to restore ${target}: $_"
+        $problems++
+    }
+}
+
+Write-Host "Restored: $restored  Already present: $present  Problems: $problems"
+if ($problems -gt 0) {
+    exit 1
+}
+`
@@ -0,0 +1,96 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+)
+
+func TestEmitScriptExportsCheckedSources(t *testing.T) {
+	sha := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+	backupAssets := filepath.Join(t.TempDir(), "assets")
+	named := sha("backed up")
+	renamed := sha("harvested")
+	corrupt := sha("original")
+	writeFile(t, filepath.Join(backupAssets, named+".png"), "backed up")
+	writeFile(t, filepath.Join(backupAssets, "holiday photo.png"), "harvested")
+	writeFile(t, filepath.Join(backupAssets, corrupt+".png"), "bit rot")
+
+	algorithm, err := digest.Parse("sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets := t.TempDir()
+	restorer := NewRestorer(assets)
+	restorer.SetHashAlgorithm(&algorithm)
+	plan := restorer.PlanRestore(&SearchResult{FoundFiles: map[string][]BackupFile{
+		named:    {{Path: filepath.Join(backupAssets, named+".png"), Hash: named, RelativePath: named + ".png", Source: SourceBackup}},
+		renamed:  {{Path: filepath.Join(backupAssets, "holiday photo.png"), Hash: renamed, RelativePath: renamed + ".png", Source: SourceHarvest}},
+		corrupt:  {{Path: filepath.Join(backupAssets, corrupt+".png"), Hash: corrupt, RelativePath: corrupt + ".png", Source: SourceBackup}},
+		"zipped": {{Path: "backup.zip!assets/zipped.png", Hash: "zipped", RelativePath: "zipped.png", Archive: "backup.zip", Member: "assets/zipped.png", Source: SourceArchive}},
+	}})
+
+	folder := filepath.Join(t.TempDir(), ScriptFolderName)
+	script, err := restorer.EmitScript(context.Background(), plan, folder)
+	if err != nil {
+		t.Fatalf("EmitScript: %v", err)
+	}
+
+	if len(script.Entries) != 2 {
+		t.Fatalf("expected the two intact plain files exported, got %+v", script.Entries)
+	}
+	if _, ok := script.Excluded[corrupt]; !ok {
+		t.Errorf("expected %s left out for not hashing to its name, got %v", corrupt, script.Excluded)
+	}
+	if _, ok := script.Excluded["zipped"]; !ok {
+		t.Errorf("expected the archive member left out, got %v", script.Excluded)
+	}
+	if len(script.ScriptOnly) != 1 || script.ScriptOnly[0] != renamed {
+		t.Errorf("expected %s to be left to the script, got %v", renamed, script.ScriptOnly)
+	}
+	if entries, _ := os.ReadDir(assets); len(entries) != 0 {
+		t.Errorf("expected nothing written to the assets folder, got %v", entries)
+	}
+
+	// The script pins the manifest it was generated with
+	manifest, err := os.ReadFile(script.Manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(manifest), filepath.Join(assets, named+".png")+","+"9,"+sha("backed up")) {
+		t.Errorf("manifest does not list %s with its size and SHA-256:\n%s", named, manifest)
+	}
+	ps, err := os.ReadFile(script.Script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned := sha256.Sum256(manifest)
+	if !strings.Contains(string(ps), strings.ToUpper(hex.EncodeToString(pinned[:]))) {
+		t.Errorf("script does not pin the manifest's SHA-256")
+	}
+
+	if len(script.JobFiles) != 1 {
+		t.Fatalf("expected one robocopy job, got %v", script.JobFiles)
+	}
+	job, err := os.ReadFile(script.JobFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/SD:" + backupAssets, "/DD:" + assets, "\t" + named + ".png\r\n", "/XC", "/XN", "/XO"} {
+		if !strings.Contains(string(job), want) {
+			t.Errorf("robocopy job is missing %q:\n%s", want, job)
+		}
+	}
+	if strings.Contains(string(job), "holiday photo.png") {
+		t.Errorf("robocopy job must not copy a file that needs renaming:\n%s", job)
+	}
+}
@@ -1,6 +1,9 @@
 package backup
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,40 +16,187 @@ import (
 
 // BackupFile represents a found backup file
 type BackupFile struct {
-	Path         string    // Full path to the backup file
-	Hash         string    // Asset hash (filename without extension)
-	Extension    string    // File extension
-	ModifiedTime time.Time // File modification time
-	Size         int64     // File size in bytes
-	RelativePath string    // Relative path from backup root (preserves folder structure)
+	Path         string    `json:"path"`                    // Full path to the backup file
+	Hash         string    `json:"hash"`                    // Asset hash (filename without extension)
+	Extension    string    `json:"extension"`               // File extension
+	ModifiedTime time.Time `json:"modified_time"`           // File modification time
+	Size         int64     `json:"size"`                    // File size in bytes
+	RelativePath string    `json:"relative_path"`           // Relative path from backup root (preserves folder structure)
+	Generation   string    `json:"generation"`              // Backup folder the file was found in
+	Version      string    `json:"version"`                 // Canvus Server version that wrote the backup
+	BackupTime   time.Time `json:"backup_time"`             // When the backup was taken, from the folder name (zero if unknown)
+	Root         string    `json:"root"`                    // Label of the backup root the file was found in
+	RootPriority int       `json:"root_priority"`           // Priority of that root (lower is preferred)
+	Archive      string    `json:"archive,omitempty"`       // Archive containing the file (Path is then archive!member)
+	Member       string    `json:"member,omitempty"`        // Name of the file inside the archive
+	Verified     bool      `json:"verified,omitempty"`      // Passed the integrity checks
+	HashVerified bool      `json:"hash_verified,omitempty"` // Content hashes to the file name
+	Problems     []string  `json:"problems,omitempty"`      // Why the file was rejected as corrupt
+	Source       string    `json:"source,omitempty"`        // Recovery source that found the file, e.g. SourceBackup (empty in older manifests)
+	CanvasID     string    `json:"canvas_id,omitempty"`     // Canvas through which a Canvus Server serves the file
+}
+
+// DescribeGeneration returns the backup folder with its version and backup time, for reports
+func (f BackupFile) DescribeGeneration() string {
+	if f.Source == SourceHarvest {
+		return "none (matched by content)"
+	}
+	if f.Generation == "" {
+		return "unknown" // Manifests written before generations were parsed
+	}
+
+	var details []string
+	if f.Version != "" {
+		details = append(details, "v"+f.Version)
+	}
+	if !f.BackupTime.IsZero() {
+		details = append(details, "backed up "+f.BackupTime.Format("2006-01-02 15:04"))
+	}
+	if len(details) == 0 {
+		return f.Generation
+	}
+	return fmt.Sprintf("%s (%s)", f.Generation, strings.Join(details, ", "))
 }
 
 // SearchResult contains the results of a backup search
 type SearchResult struct {
-	FoundFiles    map[string][]BackupFile // Hash -> list of backup files (newest first)
-	MissingHashes []string                // Hashes that were not found in any backup
-	TotalSearched int                     // Total number of backup directories searched
-	TotalFiles    int                     // Total number of files found
+	FoundFiles    map[string][]BackupFile `json:"found_files"`    // Hash -> list of backup files (newest backup first)
+	MissingHashes []string                `json:"missing_hashes"` // Hashes that were not found in any backup
+	TotalSearched int                     `json:"total_searched"` // Total number of backup roots searched
+	TotalFiles    int                     `json:"total_files"`    // Total number of files found
+	Generations   int                     `json:"generations"`    // Backup generations searched
+	Reindexed     int                     `json:"reindexed"`      // Generations walked because they were new or changed
+	Filtered      int                     `json:"filtered"`       // Generations skipped by the date/version filter
+	Timings       []GenerationTiming      `json:"timings"`        // Per-generation search time, in search order
+	Rejected      map[string][]BackupFile `json:"rejected"`       // Hash -> corrupt candidates excluded by verification
+	Verified      bool                    `json:"verified"`       // Candidates were checked for integrity
+}
+
+// BackupRoot is a folder containing backup generations, e.g. the local backup folder or a NAS mount
+type BackupRoot struct {
+	Path     string            `json:"path"`
+	Label    string            `json:"label"`    // Shown in reports (default: see LabelRoots)
+	Priority int               `json:"priority"` // Lower numbers are preferred when a file is in several roots
+	Parser   *GenerationParser `json:"-"`        // Overrides SearchOptions.Parser for this root
 }
 
+// LabelRoots labels the roots that have no label with their folder name. Where another root has
+// the same folder name or label (e.g. D:\backups and \\nas\backups), a short hash of the full
+// path is appended so every root can still be told apart.
+func LabelRoots(roots []BackupRoot) []BackupRoot {
+	uses := make(map[string]int)
+	for _, root := range roots {
+		if root.Label != "" {
+			uses[root.Label]++
+		} else {
+			uses[filepath.Base(root.Path)]++
+		}
+	}
+
+	labelled := make([]BackupRoot, len(roots))
+	for i, root := range roots {
+		if root.Label == "" {
+			root.Label = filepath.Base(root.Path)
+			if uses[root.Label] > 1 {
+				root.Label += "-" + pathHash(root.Path)
+			}
+		}
+		labelled[i] = root
+	}
+	return labelled
+}
+
+// pathHash returns a short, stable hash of a cleaned path
+func pathHash(path string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(path)))
+	return hex.EncodeToString(sum[:4])
+}
+
+// SearchOptions controls which backup folders are searched
+type SearchOptions struct {
+	Parser *GenerationParser // Recognises backup folder names in roots without their own parser (nil = DefaultGenerationPatterns)
+	Filter GenerationFilter  // Limits the search to some generations
+	Layout GenerationLayout  // Limits the search to backup folders or to archives (default: both)
+
+	MaxConcurrentFiles int // Generations and generation subfolders walked at the same time (default 1)
+}
+
+// GenerationLayout selects how backup generations are stored
+type GenerationLayout int
+
+const (
+	AnyLayout     GenerationLayout = iota // Backup folders and archives
+	FolderLayout                          // Backup folders with an assets subfolder
+	ArchiveLayout                         // Zip, tar and tar.gz archives of backup folders
+)
+
 // Searcher handles searching for backup files
 type Searcher struct {
-	backupRootFolder string
-	logger           *logging.Logger
+	roots   []BackupRoot // In priority order
+	filter  GenerationFilter
+	layout  GenerationLayout
+	workers int
+	index   *Index // Persistent per-generation index (nil = walk every generation)
+	logger  *logging.Logger
 }
 
-// NewSearcher creates a new backup searcher
-func NewSearcher(backupRootFolder string) *Searcher {
+// NewSearcher creates a new backup searcher over the given roots
+func NewSearcher(roots []BackupRoot, opts SearchOptions) *Searcher {
+	parser := opts.Parser
+	if parser == nil {
+		// The default patterns are known to compile
+		parser, _ = NewGenerationParser(nil)
+	}
+
+	sorted := make([]BackupRoot, len(roots))
+	for i, root := range LabelRoots(roots) {
+		if root.Parser == nil {
+			root.Parser = parser
+		}
+		sorted[i] = root
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	workers := opts.MaxConcurrentFiles
+	if workers < 1 {
+		workers = 1
+	}
+
 	return &Searcher{
-		backupRootFolder: backupRootFolder,
-		logger:           logging.GetLogger(),
+		roots:   sorted,
+		filter:  opts.Filter,
+		layout:  opts.Layout,
+		workers: workers,
+		logger:  logging.GetLogger(),
 	}
 }
 
+// Roots returns the backup roots in priority order
+func (s *Searcher) Roots() []BackupRoot {
+	return s.roots
+}
+
+// WithLayout returns a searcher over the same roots and index that only searches generations
+// stored in the given layout
+func (s *Searcher) WithLayout(layout GenerationLayout) *Searcher {
+	restricted := *s
+	restricted.layout = layout
+	return &restricted
+}
+
+// SetIndex makes the searcher keep a persistent index of each backup generation, so unchanged
+// generations are looked up in the index instead of walking their assets folder again
+func (s *Searcher) SetIndex(index *Index) {
+	s.index = index
+}
+
 // SearchForAssets searches for missing assets in backup folders
-// Returns a map of hash -> list of backup files (newest first)
+// Returns a map of hash -> list of backup files (newest backup first once sorted)
 // Looks for backup folders with pattern: {timestamp}_{date}_{version}_mt-canvus_backup\assets\
-func (s *Searcher) SearchForAssets(missingHashes []string) (*SearchResult, error) {
+// If ctx is cancelled the search stops and the context error is returned with the files found so far.
+func (s *Searcher) SearchForAssets(ctx context.Context, missingHashes []string) (*SearchResult, error) {
 	result := &SearchResult{
 		FoundFiles:    make(map[string][]BackupFile),
 		MissingHashes: make([]string, 0),
@@ -59,160 +209,265 @@ func (s *Searcher) SearchForAssets(missingHashes []string) (*SearchResult, error
 		return result, nil
 	}
 
-	s.logger.Info("🔍 Searching for %d missing assets in backup folder: %s", len(missingHashes), s.backupRootFolder)
+	s.logger.Info("🔍 Searching for %d missing assets in %d backup root(s)", len(missingHashes), len(s.roots))
 
-	// Create a set of missing hashes for efficient lookup
-	missingSet := make(map[string]bool)
+	// Deduplicate the missing hashes, keeping their order
+	hashes := make([]string, 0, len(missingHashes))
+	seen := make(map[string]bool, len(missingHashes))
 	for _, hash := range missingHashes {
-		missingSet[hash] = true
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
 	}
 
-	// Check if backup root folder exists
-	if !s.pathExists(s.backupRootFolder) {
-		s.logger.Warn("Backup folder does not exist: %s", s.backupRootFolder)
-		return result, nil
+	// Find the generations of every root; an unreachable root (e.g. an offline NAS) does not stop the others
+	var targets []searchTarget
+	var lastErr error
+	for _, root := range s.roots {
+		// Check if backup root folder exists
+		if !s.pathExists(root.Path) {
+			s.logger.Warn("Backup folder [%s] does not exist: %s", root.Label, root.Path)
+			continue
+		}
+
+		s.logger.Info("📁 Searching backup root [%s] (priority %d): %s", root.Label, root.Priority, root.Path)
+
+		// Find backup folders with the expected pattern
+		rootTargets, err := s.listGenerations(root, result)
+		if err != nil {
+			s.logger.Error("Error searching backup folders %s: %v", root.Path, err)
+			lastErr = err
+			continue
+		}
+
+		targets = append(targets, rootTargets...)
+		result.TotalSearched++
 	}
 
-	// Find backup folders with the expected pattern and search their assets subfolder
-	err := s.searchBackupFolders(s.backupRootFolder, missingSet, result)
-	if err != nil {
-		s.logger.Error("Error searching backup folders %s: %v", s.backupRootFolder, err)
-		return result, err
+	if result.TotalSearched == 0 && lastErr != nil {
+		return result, lastErr
 	}
 
-	result.TotalSearched = 1 // We searched one root folder
+	// Index the generations concurrently, then look up the missing hashes in search order
+	// (root priority, newest backup first) so the result does not depend on scheduling
+	s.logger.Info("🗂️  Searching %d backup generations with up to %d concurrent walkers", len(targets), s.workers)
+	indexes, jobs := s.indexGenerations(ctx, targets)
+	for i, target := range targets {
+		job := jobs[i]
+		timing := GenerationTiming{
+			Root:       target.root.Label,
+			Generation: target.generation.Name,
+			FromIndex:  job.fromIndex,
+			Duration:   job.duration,
+		}
+
+		idx := indexes[i]
+		if idx == nil {
+			if job.err != nil && ctx.Err() == nil {
+				s.logger.Error("Error searching backup folder %s: %v", target.generation.AssetsPath, job.err)
+				timing.Error = job.err.Error()
+				result.Timings = append(result.Timings, timing)
+			}
+			continue
+		}
+
+		timing.Files = idx.FileCount
+		result.Timings = append(result.Timings, timing)
+		result.Generations++
+		if !job.fromIndex {
+			result.Reindexed++
+		}
+
+		for _, hash := range hashes {
+			for _, backupFile := range idx.Lookup(hash) {
+				backupFile.Generation = target.generation.Name
+				backupFile.Version = target.generation.Version
+				backupFile.BackupTime = target.generation.BackupTime()
+				backupFile.Root = target.root.Label
+				backupFile.RootPriority = target.root.Priority
+				result.FoundFiles[hash] = append(result.FoundFiles[hash], backupFile)
+				result.TotalFiles++
+
+				s.logger.Verbose("Found backup: %s (hash: %s, size: %d bytes, modified: %s)",
+					backupFile.Path, hash, backupFile.Size, backupFile.ModifiedTime.Format("2006-01-02 15:04:05"))
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		s.logger.Warn("Backup search interrupted after %d of %d generations (%d files found)", result.Generations, len(targets), result.TotalFiles)
+		return result, fmt.Errorf("backup search interrupted: %w", ctx.Err())
+	}
 
 	// Identify hashes that were not found
-	for hash := range missingSet {
+	for _, hash := range hashes {
 		if _, found := result.FoundFiles[hash]; !found {
 			result.MissingHashes = append(result.MissingHashes, hash)
 		}
 	}
 
 	s.logger.Info("✅ Backup search completed:")
-	s.logger.Info("   📁 Folders searched: %d", result.TotalSearched)
+	s.logger.Info("   📁 Backup roots searched: %d of %d", result.TotalSearched, len(s.roots))
+	s.logger.Info("   🗂️  Backup generations: %d (%d reindexed, %d filtered out)", result.Generations, result.Reindexed, result.Filtered)
 	s.logger.Info("   📄 Files found: %d", result.TotalFiles)
 	s.logger.Info("   ✅ Assets found: %d", len(result.FoundFiles))
 	s.logger.Info("   ❌ Assets still missing: %d", len(result.MissingHashes))
+	for _, timing := range result.Timings {
+		source := "walked"
+		if timing.FromIndex {
+			source = "from index"
+		}
+		if timing.Error != "" {
+			source = "failed"
+		}
+		s.logger.Info("   ⏱️  [%s] %s: %d files, %v (%s)", timing.Root, timing.Generation, timing.Files, timing.Duration.Round(time.Millisecond), source)
+	}
 
 	return result, nil
 }
 
-// searchBackupFolders finds backup folders with the expected pattern and searches their assets subfolder
-func (s *Searcher) searchBackupFolders(backupRoot string, missingSet map[string]bool, result *SearchResult) error {
+// listGenerations finds the backup generations of a root that pass the filter, newest first
+func (s *Searcher) listGenerations(root BackupRoot, result *SearchResult) ([]searchTarget, error) {
 	// Look for backup folders with pattern: {timestamp}_{date}_{version}_mt-canvus_backup
-	entries, err := os.ReadDir(backupRoot)
+	entries, err := os.ReadDir(root.Path)
 	if err != nil {
-		return fmt.Errorf("failed to read backup root directory: %w", err)
+		return nil, fmt.Errorf("failed to read backup root directory: %w", err)
 	}
 
-	backupFolders := make([]string, 0)
+	index := s.rootIndex(root)
+	generations := make([]BackupGeneration, 0)
+	names := make([]string, 0)
+	filtered := 0
 	for _, entry := range entries {
-		if entry.IsDir() && s.isBackupFolder(entry.Name()) {
-			backupPath := filepath.Join(backupRoot, entry.Name())
-			assetsPath := filepath.Join(backupPath, "assets")
-
-			// Check if this backup folder has an assets subfolder
-			if s.pathExists(assetsPath) {
-				backupFolders = append(backupFolders, assetsPath)
-				s.logger.Verbose("Found backup assets folder: %s", assetsPath)
-			}
+		generation, ok := s.parseGeneration(root, entry)
+		if !ok {
+			continue
 		}
-	}
+		names = append(names, generation.Name)
 
-	if len(backupFolders) == 0 {
-		s.logger.Warn("No backup folders with assets subfolder found in: %s", backupRoot)
-		return nil
-	}
+		if s.layout == FolderLayout && generation.Archive || s.layout == ArchiveLayout && !generation.Archive {
+			continue
+		}
 
-	// Search each backup assets folder
-	for _, assetsPath := range backupFolders {
-		err := s.searchBackupFolder(assetsPath, missingSet, result)
-		if err != nil {
-			s.logger.Error("Error searching backup folder %s: %v", assetsPath, err)
+		if ok, reason := s.filter.Matches(generation); !ok {
+			s.logger.Verbose("Skipping backup generation %s: %s", generation.Name, reason)
+			filtered++
 			continue
 		}
-	}
 
-	return nil
-}
+		generations = append(generations, generation)
+		s.logger.Verbose("Found backup assets folder: %s", generation.AssetsPath)
+	}
 
-// isBackupFolder checks if a folder name matches the backup pattern
-func (s *Searcher) isBackupFolder(folderName string) bool {
-	// Pattern: {timestamp}_{date}_{version}_mt-canvus_backup
-	// Example: 1757261054_2025_09_07_3.3.0_mt-canvus_backup
-	return strings.Contains(folderName, "_mt-canvus_backup")
-}
+	result.Filtered += filtered
 
-// searchBackupFolder recursively searches a backup folder for missing assets
-func (s *Searcher) searchBackupFolder(backupRoot string, missingSet map[string]bool, result *SearchResult) error {
-	return filepath.Walk(backupRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			// Log error but continue searching
-			s.logger.Verbose("Error accessing %s: %v", path, err)
-			return nil
+	// Filtered generations keep their index for later runs
+	if index != nil && len(names) > 0 {
+		if err := index.Prune(names); err != nil {
+			s.logger.Warn("Failed to prune backup index: %v", err)
 		}
+	}
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
+	if len(generations) == 0 {
+		if filtered > 0 {
+			s.logger.Warn("All %d backup generations in %s were excluded by the date/version filter", filtered, root.Path)
+		} else if s.layout == ArchiveLayout {
+			s.logger.Verbose("No backup archives found in: %s", root.Path)
+		} else {
+			s.logger.Warn("No backup folders with assets subfolder found in: %s", root.Path)
 		}
+		return nil, nil
+	}
 
-		// Extract hash from filename (filename without extension)
-		filename := info.Name()
-		ext := filepath.Ext(filename)
-		hash := strings.TrimSuffix(filename, ext)
+	// Search the newest backups first
+	sortGenerations(generations)
 
-		// Check if this hash is one we're looking for
-		if missingSet[hash] {
-			// Calculate relative path from the assets subfolder to preserve folder structure
-			// This ensures the path starts from assets\ and aligns with the target assets\ folder
-			relPath, err := filepath.Rel(backupRoot, path)
-			if err != nil {
-				// If we can't calculate relative path, use just the filename
-				relPath = info.Name()
-			}
+	targets := make([]searchTarget, len(generations))
+	for i, generation := range generations {
+		targets[i] = searchTarget{root: root, generation: generation, index: index}
+	}
+	return targets, nil
+}
 
-			backupFile := BackupFile{
-				Path:         path,
-				Hash:         hash,
-				Extension:    ext,
-				ModifiedTime: info.ModTime(),
-				Size:         info.Size(),
-				RelativePath: relPath,
-			}
+// parseGeneration recognises a backup generation in a root: a backup folder with an assets
+// subfolder, or a zip/tar/tar.gz archive of one (whose name without extension is parsed)
+func (s *Searcher) parseGeneration(root BackupRoot, entry os.DirEntry) (BackupGeneration, bool) {
+	if entry.IsDir() {
+		generation, ok := root.Parser.Parse(entry.Name())
+		if !ok {
+			return generation, false
+		}
 
-			// Add to results (will be sorted by modification time later)
-			result.FoundFiles[hash] = append(result.FoundFiles[hash], backupFile)
-			result.TotalFiles++
+		// Check if this backup folder has an assets subfolder
+		generation.AssetsPath = filepath.Join(root.Path, entry.Name(), "assets")
+		return generation, s.pathExists(generation.AssetsPath)
+	}
 
-			s.logger.Verbose("Found backup: %s (hash: %s, size: %d bytes, modified: %s)",
-				path, hash, info.Size(), info.ModTime().Format("2006-01-02 15:04:05"))
-		}
+	base, isArchive := archiveBaseName(entry.Name())
+	if !isArchive {
+		return BackupGeneration{}, false
+	}
+	generation, ok := root.Parser.Parse(base)
+	if !ok {
+		return generation, false
+	}
 
+	// Keep the extension so a generation and its archive get separate index files
+	generation.Name = entry.Name()
+	generation.AssetsPath = filepath.Join(root.Path, entry.Name())
+	generation.Archive = true
+	return generation, true
+}
+
+// rootIndex returns the index of a backup root, or nil if the searcher keeps no index.
+// Roots often hold copies of the same generation, so each root has its own index folder.
+func (s *Searcher) rootIndex(root BackupRoot) *Index {
+	if s.index == nil {
 		return nil
+	}
+	return s.index.ForRoot(root.Path)
+}
+
+// sortGenerations orders generations newest backup first; generations without a backup time go last
+func sortGenerations(generations []BackupGeneration) {
+	sort.SliceStable(generations, func(i, j int) bool {
+		ti, tj := generations[i].BackupTime(), generations[j].BackupTime()
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return generations[i].Name < generations[j].Name
 	})
 }
 
-// SortBackupFiles sorts backup files by modification time (newest first)
+// SortBackupFiles sorts backup files by root priority, then backup time (newest first).
+// File modification times are unreliable after copying backups around, so they only break ties.
 func (s *Searcher) SortBackupFiles(result *SearchResult) {
 	for hash, files := range result.FoundFiles {
-		// Sort by modification time (newest first)
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].ModifiedTime.After(files[j].ModifiedTime)
+		sort.SliceStable(files, func(i, j int) bool {
+			if files[i].RootPriority != files[j].RootPriority {
+				return files[i].RootPriority < files[j].RootPriority
+			}
+			if !files[i].BackupTime.Equal(files[j].BackupTime) {
+				return files[i].BackupTime.After(files[j].BackupTime)
+			}
+			if !files[i].ModifiedTime.Equal(files[j].ModifiedTime) {
+				return files[i].ModifiedTime.After(files[j].ModifiedTime)
+			}
+			return files[i].Path < files[j].Path
 		})
 		result.FoundFiles[hash] = files
 	}
 }
 
-// GetBestBackupFile returns the newest backup file for a given hash
+// GetBestBackupFile returns the preferred backup file for a given hash
 func (s *Searcher) GetBestBackupFile(result *SearchResult, hash string) *BackupFile {
 	files, exists := result.FoundFiles[hash]
 	if !exists || len(files) == 0 {
 		return nil
 	}
 
-	// Files are already sorted by modification time (newest first)
+	// Files are already sorted by root priority and backup time (newest first)
 	return &files[0]
 }
 
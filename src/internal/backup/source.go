@@ -0,0 +1,245 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+)
+
+// Recovery source names recorded in BackupFile.Source
+const (
+	SourceBackup  = "backup"  // Backup generation folders
+	SourceArchive = "archive" // Zip, tar and tar.gz backup generations
+	SourceHarvest = "harvest" // Files in arbitrary folders matched by content
+)
+
+// RecoverySource supplies restore candidates for missing assets from one kind of storage.
+// New recovery methods implement it and are added to a Planner; the commands stay unchanged.
+type RecoverySource interface {
+	// Name identifies the source and is recorded in BackupFile.Source of every candidate it finds
+	Name() string
+
+	// Priority orders the sources: lower numbers are queried first and their candidates preferred
+	Priority() int
+
+	// FindCandidates returns candidates for the hashes the source can supply, preferred first,
+	// and lists the rest in MissingHashes. If ctx is cancelled it returns the context error.
+	FindCandidates(ctx context.Context, hashes []string) (*SearchResult, error)
+
+	// Open streams the content of a candidate found by this source
+	Open(ctx context.Context, file BackupFile) (io.ReadCloser, error)
+}
+
+// BackupSource recovers assets from backup generations, checking every candidate for corruption
+// before it is offered
+type BackupSource struct {
+	name     string
+	priority int
+	searcher *Searcher
+	verifier *Verifier // nil = candidates are offered unchecked
+}
+
+// NewBackupSource creates a recovery source over a searcher's generations, e.g. one restricted
+// to backup folders (SourceBackup) or to archives (SourceArchive)
+func NewBackupSource(name string, priority int, searcher *Searcher, verifier *Verifier) *BackupSource {
+	return &BackupSource{
+		name:     name,
+		priority: priority,
+		searcher: searcher,
+		verifier: verifier,
+	}
+}
+
+// Name returns the source name
+func (b *BackupSource) Name() string {
+	return b.name
+}
+
+// Priority returns the source priority
+func (b *BackupSource) Priority() int {
+	return b.priority
+}
+
+// FindCandidates searches the backup generations, excludes corrupt copies and sorts the rest by
+// root priority and backup time
+func (b *BackupSource) FindCandidates(ctx context.Context, hashes []string) (*SearchResult, error) {
+	result, err := b.searcher.SearchForAssets(ctx, hashes)
+	if err != nil {
+		return result, err
+	}
+
+	for hash, files := range result.FoundFiles {
+		for i := range files {
+			files[i].Source = b.name
+		}
+		result.FoundFiles[hash] = files
+	}
+
+	// Verification stops at the first sound candidate, so the preferred ones must come first
+	b.searcher.SortBackupFiles(result)
+	if b.verifier != nil {
+		if err := b.verifier.Verify(ctx, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// Open opens a backup file, extracting it from its archive if needed
+func (b *BackupSource) Open(ctx context.Context, file BackupFile) (io.ReadCloser, error) {
+	return OpenBackupFile(file)
+}
+
+// HarvestSource recovers assets from arbitrary folders by matching file contents
+type HarvestSource struct {
+	priority  int
+	harvester *Harvester
+}
+
+// NewHarvestSource creates a recovery source over a harvester's folders
+func NewHarvestSource(priority int, harvester *Harvester) *HarvestSource {
+	return &HarvestSource{
+		priority:  priority,
+		harvester: harvester,
+	}
+}
+
+// Name returns SourceHarvest
+func (h *HarvestSource) Name() string {
+	return SourceHarvest
+}
+
+// Priority returns the source priority
+func (h *HarvestSource) Priority() int {
+	return h.priority
+}
+
+// FindCandidates hashes the harvest folders; matches are verified by their content hash
+func (h *HarvestSource) FindCandidates(ctx context.Context, hashes []string) (*SearchResult, error) {
+	harvested, err := h.harvester.Harvest(ctx, hashes)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		FoundFiles:    make(map[string][]BackupFile),
+		MissingHashes: append([]string(nil), hashes...),
+		TotalSearched: len(harvested.Directories),
+		Verified:      true,
+	}
+	harvested.MergeInto(result)
+	for _, files := range harvested.Matches {
+		result.TotalFiles += len(files)
+	}
+	return result, err
+}
+
+// Open opens a harvested file
+func (h *HarvestSource) Open(ctx context.Context, file BackupFile) (io.ReadCloser, error) {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source file: %w", err)
+	}
+	return f, nil
+}
+
+// Planner queries recovery sources in priority order and merges their candidates into one result
+type Planner struct {
+	sources []RecoverySource
+	logger  *logging.Logger
+}
+
+// NewPlanner creates a planner over the given sources; sources with equal priority keep their order
+func NewPlanner(sources ...RecoverySource) *Planner {
+	sorted := append([]RecoverySource(nil), sources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority() < sorted[j].Priority()
+	})
+
+	return &Planner{
+		sources: sorted,
+		logger:  logging.GetLogger(),
+	}
+}
+
+// Sources returns the sources in priority order
+func (p *Planner) Sources() []RecoverySource {
+	return p.sources
+}
+
+// FindCandidates asks each source, in priority order, for the hashes no earlier source could supply.
+// A source that fails (e.g. an unreachable server) is skipped; an error is only returned if every
+// source failed or ctx was cancelled, in which case the candidates found so far are returned with it.
+func (p *Planner) FindCandidates(ctx context.Context, hashes []string) (*SearchResult, error) {
+	merged := &SearchResult{
+		FoundFiles:    make(map[string][]BackupFile),
+		MissingHashes: append([]string(nil), hashes...),
+		Rejected:      make(map[string][]BackupFile),
+		Verified:      true,
+	}
+
+	failed := 0
+	var lastErr error
+	for _, source := range p.sources {
+		if len(merged.MissingHashes) == 0 {
+			break
+		}
+
+		p.logger.Info("🧭 Asking recovery source %s for %d missing assets...", source.Name(), len(merged.MissingHashes))
+		result, err := source.FindCandidates(ctx, merged.MissingHashes)
+		if ctx.Err() != nil {
+			if result != nil {
+				merged.merge(result, false)
+			}
+			return merged, fmt.Errorf("recovery source %s interrupted: %w", source.Name(), ctx.Err())
+		}
+		if err != nil {
+			p.logger.Error("Recovery source %s failed: %v", source.Name(), err)
+			failed++
+			lastErr = err
+			continue
+		}
+
+		merged.merge(result, true)
+		p.logger.Info("   %s supplied %d assets, %d still missing", source.Name(), len(result.FoundFiles), len(merged.MissingHashes))
+	}
+
+	if failed > 0 && failed == len(p.sources) {
+		return merged, fmt.Errorf("every recovery source failed: %w", lastErr)
+	}
+	return merged, nil
+}
+
+// merge adds the candidates and statistics of one source; hashes it supplied are no longer missing.
+// The result stays Verified only if every source that completed checked its candidates.
+func (r *SearchResult) merge(result *SearchResult, complete bool) {
+	for hash, files := range result.FoundFiles {
+		r.FoundFiles[hash] = append(r.FoundFiles[hash], files...)
+	}
+	for hash, files := range result.Rejected {
+		r.Rejected[hash] = append(r.Rejected[hash], files...)
+	}
+
+	missing := make([]string, 0, len(r.MissingHashes))
+	for _, hash := range r.MissingHashes {
+		if len(r.FoundFiles[hash]) == 0 {
+			missing = append(missing, hash)
+		}
+	}
+	r.MissingHashes = missing
+
+	r.TotalSearched += result.TotalSearched
+	r.TotalFiles += result.TotalFiles
+	r.Generations += result.Generations
+	r.Reindexed += result.Reindexed
+	r.Filtered += result.Filtered
+	r.Timings = append(r.Timings, result.Timings...)
+	if complete {
+		r.Verified = r.Verified && result.Verified
+	}
+}
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSource supplies fixed contents and records which hashes it was asked for
+type fakeSource struct {
+	name     string
+	priority int
+	contents map[string]string
+	err      error
+	asked    []string
+}
+
+func (f *fakeSource) Name() string  { return f.name }
+func (f *fakeSource) Priority() int { return f.priority }
+
+func (f *fakeSource) FindCandidates(ctx context.Context, hashes []string) (*SearchResult, error) {
+	f.asked = append([]string(nil), hashes...)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	result := &SearchResult{FoundFiles: make(map[string][]BackupFile), Verified: true}
+	for _, hash := range hashes {
+		if _, found := f.contents[hash]; found {
+			result.FoundFiles[hash] = []BackupFile{{Path: f.name + ":" + hash, Hash: hash, RelativePath: hash + ".bin", Source: f.name}}
+		} else {
+			result.MissingHashes = append(result.MissingHashes, hash)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeSource) Open(ctx context.Context, file BackupFile) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(f.contents[file.Hash])), nil
+}
+
+func TestPlannerQueriesSourcesInPriorityOrder(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets", "aaa.jpg"), "folder copy")
+	writeZip(t, filepath.Join(root, "1757347454_2025_09_08_3.3.0_mt-canvus_backup.zip"), map[string]string{
+		"assets/aaa.jpg": "newer archive copy",
+		"assets/ccc.png": "archive only",
+	})
+	searcher := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{})
+
+	broken := &fakeSource{name: "broken", priority: 2, err: errors.New("server unreachable")}
+	server := &fakeSource{name: "server", priority: 3, contents: map[string]string{"ddd": "downloaded", "ccc": "never asked"}}
+	planner := NewPlanner(
+		server,
+		NewBackupSource(SourceArchive, 1, searcher.WithLayout(ArchiveLayout), nil),
+		broken,
+		NewBackupSource(SourceBackup, 0, searcher.WithLayout(FolderLayout), nil),
+	)
+
+	result, err := planner.FindCandidates(context.Background(), []string{"aaa", "ccc", "ddd", "eee"})
+	if err != nil {
+		t.Fatalf("FindCandidates: %v", err)
+	}
+
+	// The folder generation is preferred although the archive is newer
+	if files := result.FoundFiles["aaa"]; len(files) != 1 || files[0].Source != SourceBackup {
+		t.Errorf("expected aaa only from the backup folder, got %+v", files)
+	}
+	if files := result.FoundFiles["ccc"]; len(files) != 1 || files[0].Source != SourceArchive {
+		t.Errorf("expected ccc from the archive, got %+v", files)
+	}
+	if files := result.FoundFiles["ddd"]; len(files) != 1 || files[0].Source != "server" {
+		t.Errorf("expected ddd from the server, got %+v", files)
+	}
+	if !reflect.DeepEqual(server.asked, []string{"ddd", "eee"}) {
+		t.Errorf("expected later sources to be asked only for still-missing hashes, got %v", server.asked)
+	}
+	if !reflect.DeepEqual(result.MissingHashes, []string{"eee"}) {
+		t.Errorf("expected only eee missing, got %v", result.MissingHashes)
+	}
+
+	// Candidates from a registered source are opened through it
+	assets := t.TempDir()
+	restorer := NewRestorer(assets)
+	restorer.RegisterSource(server)
+	restoreResult, err := restorer.ApplyPlan(context.Background(), restorer.PlanRestore(result))
+	if err != nil || len(restoreResult.RestoredFiles) != 3 {
+		t.Fatalf("expected 3 restored files, got %+v, %v", restoreResult, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(assets, "ddd.bin")); err != nil || string(got) != "downloaded" {
+		t.Errorf("ddd.bin: got %q, %v", got, err)
+	}
+
+	// Without the source registered its candidates cannot be restored
+	unregistered := NewRestorer(t.TempDir())
+	restoreResult, _ = unregistered.ApplyPlan(context.Background(), unregistered.PlanRestore(result))
+	if !reflect.DeepEqual(restoreResult.FailedFiles, []string{"ddd"}) {
+		t.Errorf("expected only ddd to fail without its source, got %+v", restoreResult)
+	}
+}
+
+func TestPlannerFailsWhenEverySourceFails(t *testing.T) {
+	planner := NewPlanner(&fakeSource{name: "broken", err: errors.New("server unreachable")})
+	if _, err := planner.FindCandidates(context.Background(), []string{"aaa"}); err == nil {
+		t.Error("expected an error when every source fails")
+	}
+}
@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// UndoFile is the outcome of undoing one restored file
+type UndoFile struct {
+	Run    string `json:"run"`
+	Hash   string `json:"hash"`
+	Target string `json:"target"`
+	Reason string `json:"reason,omitempty"` // Why the file was left in place
+}
+
+// UndoResult lists what an undo deleted and what it left alone
+type UndoResult struct {
+	Runs    []string   // Restore runs whose files were considered
+	Deleted []UndoFile // Files deleted (or that would be, in a dry run)
+	Refused []UndoFile // Files changed since they were restored, left in place
+	Gone    []UndoFile // Files already deleted by someone else
+	Cleaned []string   // Temporary files of unfinished copies removed
+}
+
+// Undo deletes the files the journal records as created by a restore, only those of one run if run
+// is set. A file is deleted only if it still has the size and SHA-256 recorded when it was restored;
+// anything else is refused and listed. With dryRun nothing is deleted or journaled.
+func Undo(journal *RestoreJournal, run string, dryRun bool) (*UndoResult, error) {
+	result := &UndoResult{}
+	runs := make(map[string]bool)
+
+	for _, entry := range journal.Entries() {
+		if run != "" && entry.Run != run {
+			continue
+		}
+
+		switch entry.Step {
+		case StepDone:
+		case StepStarted, StepCopied, StepVerified:
+			// An unfinished copy only ever created its temporary file
+			if _, err := os.Stat(entry.TempPath); err == nil {
+				if !dryRun {
+					if err := os.Remove(entry.TempPath); err != nil {
+						return result, fmt.Errorf("failed to remove %s: %w", entry.TempPath, err)
+					}
+				}
+				result.Cleaned = append(result.Cleaned, entry.TempPath)
+			}
+			continue
+		default:
+			continue
+		}
+		runs[entry.Run] = true
+
+		file := UndoFile{Run: entry.Run, Hash: entry.Hash, Target: entry.Target}
+		reason, gone := changedSinceRestore(entry)
+		if gone {
+			file.Reason = reason
+			result.Gone = append(result.Gone, file)
+			continue
+		}
+		if reason != "" {
+			file.Reason = reason
+			result.Refused = append(result.Refused, file)
+			continue
+		}
+
+		if !dryRun {
+			if err := os.Remove(entry.Target); err != nil {
+				return result, fmt.Errorf("failed to delete %s: %w", entry.Target, err)
+			}
+			entry.Step = StepUndone
+			entry.Time = time.Time{}
+			if err := journal.Record(entry); err != nil {
+				return result, err
+			}
+		}
+		result.Deleted = append(result.Deleted, file)
+	}
+
+	for id := range runs {
+		result.Runs = append(result.Runs, id)
+	}
+	sort.Strings(result.Runs)
+	return result, nil
+}
+
+// changedSinceRestore explains why a restored file must not be deleted, or returns "" if it is
+// unchanged. gone is set if the file no longer exists.
+func changedSinceRestore(entry RestoreJournalEntry) (reason string, gone bool) {
+	info, err := os.Stat(entry.Target)
+	if os.IsNotExist(err) {
+		return "already deleted", true
+	}
+	if err != nil {
+		return fmt.Sprintf("cannot be checked: %v", err), false
+	}
+	if entry.SHA256 == "" {
+		return "no checksum was recorded when it was restored", false
+	}
+	if info.Size() != entry.Bytes {
+		return fmt.Sprintf("size changed from %d to %d bytes since it was restored", entry.Bytes, info.Size()), false
+	}
+	sum, err := sumFile(entry.Target)
+	if err != nil {
+		return fmt.Sprintf("cannot be checked: %v", err), false
+	}
+	if sum != entry.SHA256 {
+		return "content changed since it was restored", false
+	}
+	return "", false
+}
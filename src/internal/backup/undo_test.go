@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoDeletesOnlyUnchangedFiles(t *testing.T) {
+	root := t.TempDir()
+	backupAssets := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets")
+	writeFile(t, filepath.Join(backupAssets, "aaaaaaaa.jpg"), "untouched image")
+	writeFile(t, filepath.Join(backupAssets, "bbbbbbbb.png"), "edited image")
+	result, err := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{}).SearchForAssets(context.Background(), []string{"aaaaaaaa", "bbbbbbbb"})
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+
+	assets := t.TempDir()
+	journalPath := filepath.Join(t.TempDir(), RestoreJournalFilename)
+	journal, err := OpenRestoreJournal(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restorer := NewRestorer(assets)
+	restorer.SetJournal(journal)
+	if _, err := restorer.RestoreAssets(context.Background(), result); err != nil {
+		t.Fatalf("RestoreAssets: %v", err)
+	}
+	journal.Close()
+
+	untouched := filepath.Join(assets, "aaaaaaaa.jpg")
+	edited := filepath.Join(assets, "bbbbbbbb.png")
+	writeFile(t, edited, "edited imagE")
+
+	journal, err = OpenRestoreJournal(journalPath)
+	if err != nil {
+		t.Fatalf("OpenRestoreJournal: %v", err)
+	}
+	defer journal.Close()
+
+	preview, err := Undo(journal, "", true)
+	if err != nil {
+		t.Fatalf("Undo dry run: %v", err)
+	}
+	if len(preview.Deleted) != 1 || len(preview.Refused) != 1 {
+		t.Fatalf("expected one file to delete and one refused, got %+v", preview)
+	}
+	if _, err := os.Stat(untouched); err != nil {
+		t.Fatalf("dry run deleted %s: %v", untouched, err)
+	}
+
+	undone, err := Undo(journal, "", false)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if len(undone.Deleted) != 1 || undone.Deleted[0].Target != untouched {
+		t.Errorf("expected only %s deleted, got %+v", untouched, undone.Deleted)
+	}
+	if len(undone.Refused) != 1 || undone.Refused[0].Target != edited {
+		t.Errorf("expected %s refused, got %+v", edited, undone.Refused)
+	}
+	if _, err := os.Stat(untouched); !os.IsNotExist(err) {
+		t.Errorf("expected %s deleted, got %v", untouched, err)
+	}
+	if got, err := os.ReadFile(edited); err != nil || string(got) != "edited imagE" {
+		t.Errorf("%s: got %q, %v", edited, got, err)
+	}
+	if entry, _ := journal.Latest(untouched); entry.Step != StepUndone {
+		t.Errorf("expected the journal to record the undo, got %+v", entry)
+	}
+
+	// Undoing again leaves the refused file alone and deletes nothing
+	again, err := Undo(journal, "", false)
+	if err != nil {
+		t.Fatalf("second Undo: %v", err)
+	}
+	if len(again.Deleted) != 0 || len(again.Refused) != 1 {
+		t.Errorf("expected nothing more deleted, got %+v", again)
+	}
+}
+
+func TestUndoOnlyTouchesRequestedRun(t *testing.T) {
+	assets := t.TempDir()
+	target := filepath.Join(assets, "cccccccc.pdf")
+	writeFile(t, target, "%PDF restored")
+	sum, _ := sumFile(target)
+
+	journal, err := OpenRestoreJournal(filepath.Join(t.TempDir(), RestoreJournalFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	journal.Record(RestoreJournalEntry{Run: "20250101-000000", Hash: "cccccccc", Target: target, Step: StepDone, Bytes: 13, SHA256: sum})
+
+	undone, err := Undo(journal, "20250907-101500", false)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if len(undone.Deleted) != 0 || len(undone.Runs) != 0 {
+		t.Errorf("expected another run's files left alone, got %+v", undone)
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("expected %s kept: %v", target, err)
+	}
+}
@@ -0,0 +1,394 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+)
+
+// tailSize is how much of the end of a file is kept to check for a format trailer
+const tailSize = 1024
+
+// fileFormat describes the signature of a file type the verifier understands
+type fileFormat struct {
+	name    string
+	magic   func(head []byte) bool
+	trailer []byte // Must appear in the last tailSize bytes of a complete file (nil = not checked)
+}
+
+var (
+	jpegFormat = fileFormat{"JPEG", func(head []byte) bool { return bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}) }, []byte{0xFF, 0xD9}}
+	pngFormat  = fileFormat{"PNG", func(head []byte) bool { return bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")) }, []byte("IEND")}
+	pdfFormat  = fileFormat{"PDF", func(head []byte) bool { return bytes.HasPrefix(head, []byte("%PDF-")) }, []byte("%%EOF")}
+	mp4Format  = fileFormat{"MP4", func(head []byte) bool { return len(head) >= 8 && bytes.Equal(head[4:8], []byte("ftyp")) }, nil}
+	movFormat  = fileFormat{"QuickTime", func(head []byte) bool { return len(head) >= 8 && quickTimeAtoms[string(head[4:8])] }, nil}
+)
+
+// quickTimeAtoms are the atoms a QuickTime file may start with; older files have no ftyp atom
+var quickTimeAtoms = map[string]bool{
+	"ftyp": true, "moov": true, "mdat": true, "wide": true, "free": true, "skip": true, "pnot": true,
+}
+
+// formatsByExtension maps asset extensions to the format their content must have
+var formatsByExtension = map[string]fileFormat{
+	".jpg":  jpegFormat,
+	".jpeg": jpegFormat,
+	".png":  pngFormat,
+	".pdf":  pdfFormat,
+	".mp4":  mp4Format,
+	".m4v":  mp4Format,
+	".mov":  movFormat,
+}
+
+// VerifyOptions controls backup candidate verification
+type VerifyOptions struct {
+	Workers int // Candidates read at the same time (default 1)
+
+	// Algorithm is the digest Canvus uses for asset file names. When set, a candidate whose content
+	// does not hash to its file name is corrupt. When nil, the standard digests are tried and a match
+	// marks the candidate as hash-verified, but a mismatch proves nothing.
+	Algorithm *digest.Algorithm
+}
+
+// Verifier checks backup candidates for integrity before they are recommended for restore
+type Verifier struct {
+	workers   int
+	algorithm *digest.Algorithm
+	logger    *logging.Logger
+}
+
+// NewVerifier creates a new backup candidate verifier
+func NewVerifier(opts VerifyOptions) *Verifier {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	return &Verifier{
+		workers:   workers,
+		algorithm: opts.Algorithm,
+		logger:    logging.GetLogger(),
+	}
+}
+
+// Verify checks the candidates of each hash in order, which must be the order of preference, and
+// stops at the first sound one: restore only uses that copy. Corrupt candidates before it are moved
+// from FoundFiles to Rejected; the later ones are kept unchecked. Hashes left without a sound
+// candidate become missing again, so a broken file is never restored.
+// If ctx is cancelled the result is left unchanged and the context error is returned.
+func (v *Verifier) Verify(ctx context.Context, result *SearchResult) error {
+	if len(result.FoundFiles) == 0 {
+		result.Verified = true
+		return nil
+	}
+
+	v.logger.Info("🔬 Verifying backup candidates for %d assets...", len(result.FoundFiles))
+
+	// Each check writes only its own candidate, so no locking is needed
+	checked := make(map[string][]BackupFile, len(result.FoundFiles))
+	expectedSizes := make(map[string]int64, len(result.FoundFiles))
+	next := make(map[string]int, len(result.FoundFiles)) // Candidate to check next, for hashes without a sound one yet
+	for hash, files := range result.FoundFiles {
+		checked[hash] = append([]BackupFile(nil), files...)
+		expectedSizes[hash], _ = consensusSize(files)
+		next[hash] = 0
+	}
+
+	// Each round checks the next candidate of every unresolved hash, so the candidates inside one
+	// archive are read in a single pass over it
+	checkedCount := 0
+	for len(next) > 0 {
+		round := make([]candidate, 0, len(next))
+		for hash, index := range next {
+			round = append(round, candidate{hash: hash, index: index, expectedSize: expectedSizes[hash]})
+		}
+		sort.Slice(round, func(i, j int) bool { return round[i].hash < round[j].hash })
+
+		if err := v.checkRound(ctx, checked, round); err != nil {
+			return fmt.Errorf("backup verification interrupted: %w", err)
+		}
+		checkedCount += len(round)
+
+		for _, c := range round {
+			if len(checked[c.hash][c.index].Problems) == 0 || c.index == len(checked[c.hash])-1 {
+				delete(next, c.hash)
+				continue
+			}
+			next[c.hash] = c.index + 1
+		}
+	}
+
+	// Split sound and corrupt candidates
+	if result.Rejected == nil {
+		result.Rejected = make(map[string][]BackupFile)
+	}
+	rejectedCount := 0
+	for hash, files := range checked {
+		sound := make([]BackupFile, 0, len(files))
+		for _, file := range files {
+			if len(file.Problems) > 0 {
+				result.Rejected[hash] = append(result.Rejected[hash], file)
+				rejectedCount++
+				v.logger.Warn("🚫 Rejected backup %s: %s", file.Path, strings.Join(file.Problems, "; "))
+				continue
+			}
+			sound = append(sound, file)
+		}
+
+		if len(sound) == 0 {
+			delete(result.FoundFiles, hash)
+			result.MissingHashes = append(result.MissingHashes, hash)
+			continue
+		}
+		result.FoundFiles[hash] = sound
+	}
+	sort.Strings(result.MissingHashes)
+	result.Verified = true
+
+	v.logger.Info("✅ Verification completed: %d candidates checked, %d rejected, %d assets left without a sound backup",
+		checkedCount, rejectedCount, countWithout(result))
+	return nil
+}
+
+// candidate is one backup file of a hash to check, with the size the other copies agree on
+type candidate struct {
+	hash         string
+	index        int
+	expectedSize int64
+}
+
+// checkRound checks candidates with at most v.workers readers. Plain files are checked one by
+// one; candidates inside the same archive are checked together while streaming it once.
+func (v *Verifier) checkRound(ctx context.Context, checked map[string][]BackupFile, round []candidate) error {
+	tasks := make([][]candidate, 0, len(round))
+	archives := make(map[string]int) // Archive path -> its task
+	for _, c := range round {
+		archive := checked[c.hash][c.index].Archive
+		if archive == "" {
+			tasks = append(tasks, []candidate{c})
+			continue
+		}
+		if i, ok := archives[archive]; ok {
+			tasks[i] = append(tasks[i], c)
+			continue
+		}
+		archives[archive] = len(tasks)
+		tasks = append(tasks, []candidate{c})
+	}
+
+	queue := make(chan []candidate)
+	var wg sync.WaitGroup
+	for w := 0; w < v.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				if ctx.Err() != nil {
+					continue // Drain the queue without reading
+				}
+				if archive := checked[task[0].hash][task[0].index].Archive; archive != "" {
+					v.checkArchive(ctx, archive, checked, task)
+					continue
+				}
+				file := &checked[task[0].hash][task[0].index]
+				v.checkFile(file, task[0].expectedSize)
+			}
+		}()
+	}
+
+feed:
+	for _, task := range tasks {
+		select {
+		case queue <- task:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// checkFile opens and checks a plain backup file
+func (v *Verifier) checkFile(file *BackupFile, expectedSize int64) {
+	reader, err := OpenBackupFile(*file)
+	if err != nil {
+		file.Problems = []string{fmt.Sprintf("unreadable: %v", err)}
+		return
+	}
+	defer reader.Close()
+	file.Verified, file.HashVerified, file.Problems = v.check(*file, reader, expectedSize)
+}
+
+// checkArchive checks candidates that are members of the same archive in one pass over it
+func (v *Verifier) checkArchive(ctx context.Context, archive string, checked map[string][]BackupFile, task []candidate) {
+	byMember := make(map[string]candidate, len(task))
+	members := make([]string, 0, len(task))
+	for _, c := range task {
+		member := checked[c.hash][c.index].Member
+		byMember[member] = c
+		members = append(members, member)
+	}
+
+	err := readArchiveMembers(ctx, archive, members, func(member string, content io.Reader, err error) {
+		c := byMember[member]
+		delete(byMember, member)
+		file := &checked[c.hash][c.index]
+		if err != nil {
+			file.Problems = []string{fmt.Sprintf("unreadable: %v", err)}
+			return
+		}
+		file.Verified, file.HashVerified, file.Problems = v.check(*file, content, c.expectedSize)
+	})
+
+	// Members the archive did not yield
+	for member, c := range byMember {
+		file := &checked[c.hash][c.index]
+		if err != nil {
+			file.Problems = []string{fmt.Sprintf("unreadable: %v", err)}
+		} else {
+			file.Problems = []string{fmt.Sprintf("unreadable: member %s not found in %s", member, archive)}
+		}
+	}
+}
+
+// countWithout returns the number of rejected hashes that have no sound candidate left
+func countWithout(result *SearchResult) int {
+	count := 0
+	for hash := range result.Rejected {
+		if _, found := result.FoundFiles[hash]; !found {
+			count++
+		}
+	}
+	return count
+}
+
+// consensusSize returns the size most candidates agree on. Asset files are content-addressed,
+// so every intact copy has the same size; there is no consensus with fewer than two copies or a tie.
+func consensusSize(files []BackupFile) (int64, bool) {
+	counts := make(map[int64]int)
+	for _, file := range files {
+		counts[file.Size]++
+	}
+
+	var best int64
+	bestCount, tie := 0, false
+	for size, count := range counts {
+		switch {
+		case count > bestCount:
+			best, bestCount, tie = size, count, false
+		case count == bestCount:
+			tie = true
+		}
+	}
+
+	if bestCount < 2 || tie {
+		return 0, false
+	}
+	return best, true
+}
+
+// check reads the content of a candidate and returns whether it is sound, whether its content hashes
+// to its file name, and the problems found. expectedSize is 0 if the candidates agree on no size.
+func (v *Verifier) check(file BackupFile, reader io.Reader, expectedSize int64) (bool, bool, []string) {
+	var problems []string
+
+	if file.Size == 0 {
+		return false, false, []string{"empty file"}
+	}
+
+	// Hash the content while keeping its start and end for the format checks
+	algorithms := digest.Candidates(len(file.Hash))
+	if v.algorithm != nil {
+		algorithms = []digest.Algorithm{*v.algorithm}
+	}
+	var head bytes.Buffer
+	tail := &tailBuffer{size: tailSize}
+	counter := &countingWriter{}
+	sums, err := digest.MultiSum(io.TeeReader(reader, io.MultiWriter(&limitedBuffer{&head, 16}, tail, counter)), algorithms)
+	if err != nil {
+		return false, false, []string{fmt.Sprintf("read failed after %d bytes: %v", counter.n, err)}
+	}
+	if counter.n == 0 {
+		return false, false, []string{"empty file"}
+	}
+
+	hashVerified := false
+	for i, algorithm := range algorithms {
+		if algorithm.Matches(sums[i], file.Hash) {
+			hashVerified = true
+			break
+		}
+	}
+	if v.algorithm != nil && !hashVerified {
+		problems = append(problems, fmt.Sprintf("content does not hash to %s (%s)", file.Hash, v.algorithm))
+	}
+
+	// A copy with the right content hash is intact, whatever the other copies look like
+	if !hashVerified && expectedSize > 0 && counter.n != expectedSize {
+		problems = append(problems, fmt.Sprintf("size %d bytes differs from %d bytes of the other copies", counter.n, expectedSize))
+	}
+
+	// The format checks are heuristics; content that hashes to the file name is the original
+	if format, known := formatsByExtension[strings.ToLower(file.Extension)]; known && !hashVerified {
+		if !format.magic(head.Bytes()) {
+			problems = append(problems, fmt.Sprintf("not a valid %s file (wrong signature)", format.name))
+		} else if format.trailer != nil && !bytes.Contains(tail.Bytes(), format.trailer) {
+			problems = append(problems, fmt.Sprintf("%s file is truncated (no end marker)", format.name))
+		}
+	}
+
+	return len(problems) == 0, hashVerified, problems
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		b.buf.Write(p[:room])
+	}
+	return len(p), nil
+}
+
+// tailBuffer keeps the last size bytes written to it
+type tailBuffer struct {
+	data []byte
+	size int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if len(b.data) > b.size {
+		b.data = append(b.data[:0], b.data[len(b.data)-b.size:]...)
+	}
+	return len(p), nil
+}
+
+// Bytes returns the kept bytes
+func (b *tailBuffer) Bytes() []byte {
+	return b.data
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,205 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+)
+
+// jpeg returns a minimal complete JPEG-like file around body
+func jpeg(body string) string {
+	return "\xFF\xD8\xFF" + body + "\xFF\xD9"
+}
+
+// searchAndVerify searches a backup root for hashes and verifies the candidates
+func searchAndVerify(t *testing.T, root string, hashes []string, opts VerifyOptions) *SearchResult {
+	t.Helper()
+	searcher := NewSearcher([]BackupRoot{{Path: root}}, SearchOptions{})
+	result, err := searcher.SearchForAssets(context.Background(), hashes)
+	if err != nil {
+		t.Fatalf("SearchForAssets: %v", err)
+	}
+	searcher.SortBackupFiles(result)
+	if err := NewVerifier(opts).Verify(context.Background(), result); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return result
+}
+
+func TestVerifyRejectsCorruptCandidates(t *testing.T) {
+	root := t.TempDir()
+	generations := []string{
+		"1757261054_2025_09_07_3.3.0_mt-canvus_backup",
+		"1757347454_2025_09_08_3.3.0_mt-canvus_backup",
+		"1757433854_2025_09_09_3.3.0_mt-canvus_backup",
+	}
+	for _, generation := range generations {
+		writeFile(t, filepath.Join(root, generation, "assets", "aaa.jpg"), jpeg("complete image"))
+	}
+	// The newest copy of aaa was cut short; bbb only survives as a PNG that is really a JPEG; ccc is empty
+	newest := filepath.Join(root, generations[2], "assets")
+	writeFile(t, filepath.Join(newest, "aaa.jpg"), "\xFF\xD8\xFFcomplete")
+	writeFile(t, filepath.Join(newest, "bbb.png"), jpeg("not a png"))
+	writeFile(t, filepath.Join(newest, "ccc.pdf"), "")
+
+	result := searchAndVerify(t, root, []string{"aaa", "bbb", "ccc"}, VerifyOptions{Workers: 2})
+
+	if !result.Verified {
+		t.Error("expected the result to be marked verified")
+	}
+	// Verification stops at the newest sound copy; the older one is kept unchecked
+	got := result.FoundFiles["aaa"]
+	if len(got) != 2 {
+		t.Fatalf("expected 2 remaining copies of aaa, got %+v", got)
+	}
+	if !got[0].Verified || !strings.Contains(got[0].Path, generations[1]) {
+		t.Errorf("expected the next newest copy to be verified first, got %+v", got[0])
+	}
+	if got[1].Verified || len(got[1].Problems) > 0 || !strings.Contains(got[1].Path, generations[0]) {
+		t.Errorf("expected the oldest copy to be left unchecked, got %+v", got[1])
+	}
+
+	rejected := result.Rejected["aaa"]
+	if len(rejected) != 1 || !strings.HasPrefix(rejected[0].Path, newest) {
+		t.Fatalf("expected the truncated copy of aaa to be rejected, got %+v", rejected)
+	}
+	problems := strings.Join(rejected[0].Problems, "; ")
+	if !strings.Contains(problems, "truncated") || !strings.Contains(problems, "differs") {
+		t.Errorf("expected truncation and size problems, got %q", problems)
+	}
+
+	if got := result.Rejected["bbb"]; len(got) != 1 || !strings.Contains(got[0].Problems[0], "wrong signature") {
+		t.Errorf("expected bbb to be rejected for its signature, got %+v", got)
+	}
+	if got := result.Rejected["ccc"]; len(got) != 1 || got[0].Problems[0] != "empty file" {
+		t.Errorf("expected ccc to be rejected as empty, got %+v", got)
+	}
+	if strings.Join(result.MissingHashes, ",") != "bbb,ccc" {
+		t.Errorf("expected hashes without a sound copy to be missing, got %v", result.MissingHashes)
+	}
+}
+
+func TestVerifyChecksContentHash(t *testing.T) {
+	content := jpeg("original")
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets", hash+".jpg"), content)
+	writeFile(t, filepath.Join(root, "1757347454_2025_09_08_3.3.0_mt-canvus_backup", "assets", hash+".jpg"), jpeg("modified"))
+
+	// Without a known algorithm a mismatch proves nothing, so the newest copy is accepted
+	result := searchAndVerify(t, root, []string{hash}, VerifyOptions{})
+	if got := result.FoundFiles[hash]; len(got) != 2 || !got[0].Verified || got[0].HashVerified {
+		t.Fatalf("expected the modified copy to be accepted first, got %+v", got)
+	}
+
+	// With the algorithm known, the copy that does not hash to its name is corrupt and the older
+	// one is checked instead
+	algorithm, err := digest.Parse("sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = searchAndVerify(t, root, []string{hash}, VerifyOptions{Algorithm: &algorithm})
+	if got := result.FoundFiles[hash]; len(got) != 1 || !got[0].HashVerified {
+		t.Errorf("expected only the original copy to be kept, got %+v", got)
+	}
+	if got := result.Rejected[hash]; len(got) != 1 || !strings.Contains(got[0].Problems[0], "does not hash") {
+		t.Errorf("expected the modified copy to be rejected, got %+v", got)
+	}
+}
+
+func TestVerifyFormatChecks(t *testing.T) {
+	// A genuine PDF can have more than tailSize bytes of padding after its end marker
+	odd := "%PDF-1.4 body\n%%EOF" + strings.Repeat(" ", tailSize)
+	sum := sha256.Sum256([]byte(odd))
+	oddHash := hex.EncodeToString(sum[:])
+
+	root := t.TempDir()
+	assets := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup", "assets")
+	writeFile(t, filepath.Join(assets, oddHash+".pdf"), odd)
+	writeFile(t, filepath.Join(assets, "aaa.pdf"), odd)
+	writeFile(t, filepath.Join(assets, "bbb.mov"), "\x00\x00\x00\x08wide\x00\x00\x00\x10mdat")
+	writeFile(t, filepath.Join(assets, "ccc.mov"), "\x00\x00\x00\x14ftypqt  ")
+	writeFile(t, filepath.Join(assets, "ddd.mov"), "not a movie")
+	writeFile(t, filepath.Join(assets, "eee.mp4"), "\x00\x00\x00\x08wide")
+
+	result := searchAndVerify(t, root, []string{oddHash, "aaa", "bbb", "ccc", "ddd", "eee"}, VerifyOptions{})
+
+	if got := result.FoundFiles[oddHash]; len(got) != 1 || !got[0].HashVerified {
+		t.Errorf("expected the hash-verified PDF to be kept despite its missing end marker, got %+v", result.Rejected[oddHash])
+	}
+	if got := result.Rejected["aaa"]; len(got) != 1 || !strings.Contains(got[0].Problems[0], "truncated") {
+		t.Errorf("expected the unverified PDF without an end marker to be rejected, got %+v", got)
+	}
+	for _, hash := range []string{"bbb", "ccc"} {
+		if len(result.FoundFiles[hash]) != 1 {
+			t.Errorf("expected QuickTime file %s to be accepted, got %+v", hash, result.Rejected[hash])
+		}
+	}
+	for _, hash := range []string{"ddd", "eee"} {
+		if got := result.Rejected[hash]; len(got) != 1 || !strings.Contains(got[0].Problems[0], "wrong signature") {
+			t.Errorf("expected %s to be rejected for its signature, got %+v", hash, got)
+		}
+	}
+}
+
+func TestVerifyArchiveCandidates(t *testing.T) {
+	root := t.TempDir()
+	older := filepath.Join(root, "1757261054_2025_09_07_3.3.0_mt-canvus_backup.zip")
+	newer := filepath.Join(root, "1757347454_2025_09_08_3.3.0_mt-canvus_backup.tar.gz")
+	writeZip(t, older, map[string]string{
+		"assets/aaa.jpg": jpeg("older copy"),
+		"assets/bbb.jpg": jpeg("older copy"),
+	})
+	writeTarGz(t, newer, map[string]string{
+		"assets/aaa.jpg":    "\xFF\xD8\xFFcut short",
+		"assets/bbb.jpg":    jpeg("newer copy"),
+		"assets/cd/ccc.pdf": "%PDF-1.4\n%%EOF",
+	})
+
+	result := searchAndVerify(t, root, []string{"aaa", "bbb", "ccc"}, VerifyOptions{Workers: 2})
+
+	if got := result.FoundFiles["aaa"]; len(got) != 1 || got[0].Archive != older || !got[0].Verified {
+		t.Errorf("expected the zip copy of aaa to replace the truncated one, got %+v", got)
+	}
+	if got := result.Rejected["aaa"]; len(got) != 1 || got[0].Archive != newer {
+		t.Errorf("expected the tar copy of aaa to be rejected, got %+v", got)
+	}
+	if got := result.FoundFiles["bbb"]; len(got) != 2 || got[0].Archive != newer || !got[0].Verified || got[1].Verified {
+		t.Errorf("expected only the newest copy of bbb to be checked, got %+v", got)
+	}
+	if got := result.FoundFiles["ccc"]; len(got) != 1 || !got[0].Verified {
+		t.Errorf("expected ccc to be verified, got %+v", got)
+	}
+}
+
+func TestReadArchiveMembers(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	writeTarGz(t, archive, map[string]string{"a": "1", "b": "2", "c": "3"})
+
+	read := make(map[string]string)
+	err := readArchiveMembers(context.Background(), archive, []string{"c", "a", "absent"}, func(member string, content io.Reader, err error) {
+		if err != nil {
+			t.Errorf("%s: %v", member, err)
+			return
+		}
+		data, err := io.ReadAll(content)
+		if err != nil {
+			t.Errorf("%s: %v", member, err)
+		}
+		read[member] = string(data)
+	})
+	if err != nil {
+		t.Fatalf("readArchiveMembers: %v", err)
+	}
+	if len(read) != 2 || read["a"] != "1" || read["c"] != "3" {
+		t.Errorf("expected members a and c, got %v", read)
+	}
+}
@@ -0,0 +1,161 @@
+package canvus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	canvussdk "canvus-go-api/canvus"
+)
+
+// CacheFilename is the default asset cache file name in the output folder
+const CacheFilename = "discovery_cache.json"
+
+// cacheVersion is bumped whenever the cached AssetInfo changes meaning; older caches are discarded.
+// Version 1 caches may hold canvases whose background or widget GETs failed.
+const cacheVersion = 2
+
+// CachedCanvas holds the assets extracted from a canvas at a given modification time
+type CachedCanvas struct {
+	ModifiedAt string      `json:"modified_at"`
+	Assets     []AssetInfo `json:"assets"`
+	CachedAt   time.Time   `json:"cached_at"`
+}
+
+// AssetCache stores the extracted assets of each canvas keyed by canvas ID and ModifiedAt,
+// so canvases that have not changed since the last run are not read again
+type AssetCache struct {
+	Version  int                     `json:"version"`
+	Canvases map[string]CachedCanvas `json:"canvases"` // Canvas ID -> cached assets
+
+	path string
+	mu   sync.Mutex
+}
+
+// NewAssetCache creates an empty cache that will be saved to path
+func NewAssetCache(path string) *AssetCache {
+	return &AssetCache{
+		Version:  cacheVersion,
+		Canvases: make(map[string]CachedCanvas),
+		path:     path,
+	}
+}
+
+// LoadAssetCache reads the cache at path. A missing or outdated cache yields an empty one.
+func LoadAssetCache(path string) (*AssetCache, error) {
+	cache := NewAssetCache(path)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset cache %s: %w", path, err)
+	}
+
+	var loaded AssetCache
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to decode asset cache %s: %w", path, err)
+	}
+
+	if loaded.Version != cacheVersion {
+		logging.GetLogger().Info("Asset cache %s has version %d (expected %d) - starting with an empty cache", path, loaded.Version, cacheVersion)
+		return cache, nil
+	}
+
+	if loaded.Canvases != nil {
+		cache.Canvases = loaded.Canvases
+	}
+	return cache, nil
+}
+
+// Lookup returns the cached assets of a canvas if it has not been modified since they were cached
+func (c *AssetCache) Lookup(canvas canvussdk.Canvas) ([]AssetInfo, bool) {
+	// Without a modification time there is no way to tell whether the cache is stale
+	if canvas.ModifiedAt == "" {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, found := c.Canvases[canvas.ID]
+	if !found || cached.ModifiedAt != canvas.ModifiedAt {
+		return nil, false
+	}
+
+	// Canvas names can change without touching the widgets, so refresh them from the listing
+	assets := make([]AssetInfo, len(cached.Assets))
+	for i, asset := range cached.Assets {
+		asset.CanvasName = canvas.Name
+		assets[i] = asset
+	}
+	return assets, true
+}
+
+// Store records the assets extracted from a canvas at its current modification time.
+// Only canvases read without any failed request may be stored.
+func (c *AssetCache) Store(canvas canvussdk.Canvas, assets []AssetInfo) {
+	if canvas.ModifiedAt == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Canvases[canvas.ID] = CachedCanvas{
+		ModifiedAt: canvas.ModifiedAt,
+		Assets:     assets,
+		CachedAt:   time.Now(),
+	}
+}
+
+// Prune drops canvases that no longer exist on the server
+func (c *AssetCache) Prune(canvases []canvussdk.Canvas) {
+	current := make(map[string]bool, len(canvases))
+	for _, canvas := range canvases {
+		current[canvas.ID] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id := range c.Canvases {
+		if !current[id] {
+			delete(c.Canvases, id)
+		}
+	}
+}
+
+// Save writes the cache to disk via a temporary file so a crash never leaves a half-written cache
+func (c *AssetCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode asset cache: %w", err)
+	}
+
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write asset cache %s: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move asset cache into place: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package canvus
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	canvussdk "canvus-go-api/canvus"
+)
+
+func TestAssetCacheKeyedOnModifiedAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CacheFilename)
+
+	cache := NewAssetCache(path)
+	canvas := canvussdk.Canvas{ID: "c1", Name: "Old Name", ModifiedAt: "2025-09-01T10:00:00Z"}
+	cache.Store(canvas, []AssetInfo{{Hash: "h1", CanvasID: "c1", CanvasName: "Old Name"}})
+	cache.Store(canvussdk.Canvas{ID: "gone", ModifiedAt: "2025-09-01T10:00:00Z"}, nil)
+	cache.Store(canvussdk.Canvas{ID: "undated"}, []AssetInfo{{Hash: "h2"}})
+	cache.Prune([]canvussdk.Canvas{canvas, {ID: "undated"}})
+
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadAssetCache(path)
+	if err != nil {
+		t.Fatalf("LoadAssetCache: %v", err)
+	}
+	if len(loaded.Canvases) != 1 {
+		t.Errorf("expected only the dated, existing canvas to be cached, got %d entries", len(loaded.Canvases))
+	}
+
+	renamed := canvas
+	renamed.Name = "New Name"
+	assets, hit := loaded.Lookup(renamed)
+	if !hit || len(assets) != 1 || assets[0].CanvasName != "New Name" {
+		t.Errorf("expected a hit with the refreshed canvas name, got hit=%v assets=%v", hit, assets)
+	}
+
+	modified := canvas
+	modified.ModifiedAt = "2025-09-02T08:00:00Z"
+	if _, hit := loaded.Lookup(modified); hit {
+		t.Errorf("expected a miss for a modified canvas")
+	}
+}
+
+func TestDiscoveryCachesOnlyCleanlyReadCanvases(t *testing.T) {
+	server := partialFailureServer()
+	defer server.Close()
+
+	cache := NewAssetCache(filepath.Join(t.TempDir(), CacheFilename))
+	session := canvussdk.NewSession(server.URL + "/api/v1")
+	if _, err := DiscoverAllAssets(context.Background(), session, DiscoveryOptions{MaxConcurrentAPI: 1, Cache: cache}); err != nil {
+		t.Fatalf("DiscoverAllAssets: %v", err)
+	}
+
+	// A canvas with a failed request may be missing assets; caching it would hide them until it changes
+	if _, found := cache.Canvases["c1"]; found {
+		t.Error("expected c1 (failed fallback GET) not to be cached")
+	}
+	if _, found := cache.Canvases["c2"]; found {
+		t.Error("expected c2 (failed background) not to be cached")
+	}
+	if _, found := cache.Canvases["c3"]; !found {
+		t.Error("expected the cleanly read c3 to be cached")
+	}
+}
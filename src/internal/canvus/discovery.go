@@ -2,7 +2,9 @@ package canvus
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"sync"
 	"time"
@@ -31,6 +33,11 @@ type DiscoveryResult struct {
 	Duration         time.Duration `json:"duration"`
 	Errors           []string    `json:"errors"`
 	ServerValidation *ServerValidationResult `json:"server_validation,omitempty"`
+	CompletedCanvases []string   `json:"completed_canvases,omitempty"` // IDs of fully processed canvases
+	ResumedCanvases  int         `json:"resumed_canvases,omitempty"`   // Canvases taken from the journal of a previous run
+	CachedCanvases   int         `json:"cached_canvases,omitempty"`    // Unchanged canvases served from the asset cache
+	RateLimit        *RateLimitStats `json:"rate_limit,omitempty"`     // How the request rate adapted during the run
+	Interrupted      bool        `json:"interrupted,omitempty"`        // Discovery was cancelled before every canvas was processed
 }
 
 // ServerValidationResult represents the result of server-side asset validation
@@ -38,60 +45,55 @@ type ServerValidationResult struct {
 	TotalAssets     int `json:"total_assets"`
 	ExistingAssets  int `json:"existing_assets"`
 	MissingAssets   int `json:"missing_assets"`
+	ErrorAssets     int `json:"error_assets"` // Probe failed for a reason other than "not found"
 	ValidationErrors []string `json:"validation_errors"`
+	Assets          map[string]AssetValidation `json:"assets"` // Hash -> per-asset result
 }
 
-// RateLimiter controls the rate of API requests
-type RateLimiter struct {
-	requests chan struct{}
-	rate     time.Duration
-}
-
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(requestsPerSecond int) *RateLimiter {
-	rate := time.Second / time.Duration(requestsPerSecond)
-	rl := &RateLimiter{
-		requests: make(chan struct{}, requestsPerSecond),
-		rate:     rate,
-	}
+// ServerStatus is the outcome of probing a single asset on the server
+type ServerStatus string
 
-	// Start the rate limiter goroutine
-	go rl.run()
+const (
+	ServerStatusServed  ServerStatus = "served"  // Server returned the asset
+	ServerStatusMissing ServerStatus = "missing" // Server answered 404
+	ServerStatusError   ServerStatus = "error"   // Any other failure (5xx, timeout, ...)
+)
 
-	return rl
+// AssetValidation records how the server answered for a single asset hash
+type AssetValidation struct {
+	Hash       string       `json:"hash"`
+	WidgetType string       `json:"widget_type"`
+	CanvasID   string       `json:"canvas_id"`
+	Status     ServerStatus `json:"status"`
+	Probe      string       `json:"probe"`                 // "mipmap" or "range"
+	StatusCode int          `json:"status_code,omitempty"` // HTTP status of the failed probe
+	Size       int64        `json:"size,omitempty"`        // File size reported by a range probe
+	Error      string       `json:"error,omitempty"`
 }
 
-// run manages the rate limiting
-func (rl *RateLimiter) run() {
-	ticker := time.NewTicker(rl.rate)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		select {
-		case rl.requests <- struct{}{}:
-		default:
-			// Channel is full, skip this tick
-		}
-	}
+// DiscoveryOptions controls how DiscoverAllAssets runs
+type DiscoveryOptions struct {
+	MaxConcurrentAPI int         // Number of canvases read and assets probed concurrently
+	Journal          *Journal    // Per-canvas progress journal (nil = none); canvases it records as done are skipped
+	Cache            *AssetCache // Assets of unchanged canvases (nil = read every canvas); updated with fresh results
 }
 
-// Wait blocks until a request slot is available
-func (rl *RateLimiter) Wait() {
-	<-rl.requests
-}
+// DiscoverAllAssets discovers all media assets across all canvases using the existing SDK.
+// If ctx is cancelled the assets of the canvases completed so far are returned together
+// with the context error, and the result is marked as interrupted.
+func DiscoverAllAssets(ctx context.Context, session *canvussdk.Session, opts DiscoveryOptions) (*DiscoveryResult, error) {
+	maxConcurrentAPI := opts.MaxConcurrentAPI
+	logger := logging.GetLogger()
 
-// DiscoverAllAssets discovers all media assets across all canvases using the existing SDK
-func DiscoverAllAssets(session *canvussdk.Session, requestsPerSecond int) (*DiscoveryResult, error) {
 	startTime := time.Now()
 	result := &DiscoveryResult{
 		StartTime: startTime,
 		Assets:    make([]AssetInfo, 0),
 		Canvases:  make([]canvussdk.Canvas, 0),
 		Errors:    make([]string, 0),
+		CompletedCanvases: make([]string, 0),
 	}
 
-	ctx := context.Background()
-
 	// Get all canvases using the existing SDK
 	canvases, err := session.ListCanvases(ctx, nil)
 	if err != nil {
@@ -100,32 +102,93 @@ func DiscoverAllAssets(session *canvussdk.Session, requestsPerSecond int) (*Disc
 
 	result.Canvases = canvases
 
-	// Create rate limiter
-	rateLimiter := NewRateLimiter(requestsPerSecond)
+	// Take completed canvases from the journal and retry failed ones first
+	pending := canvases
+	if opts.Journal != nil {
+		pending = resumeFromJournal(opts.Journal, canvases, result)
+		if result.ResumedCanvases > 0 {
+			logger.Info("⏩ Resuming: %d canvases already processed, %d remaining", result.ResumedCanvases, len(pending))
+		}
+	}
+
+	// Serve canvases that have not changed since the last run from the cache
+	if opts.Cache != nil {
+		opts.Cache.Prune(canvases)
+		pending = useAssetCache(opts.Cache, pending, result)
+		if result.CachedCanvases > 0 {
+			logger.Info("🗃️  %d unchanged canvases served from cache, %d to read", result.CachedCanvases, len(pending))
+		}
+	}
+
+	// Request pacing is done by the session (see canvussdk.WithRequestLimiter)
+	if maxConcurrentAPI < 1 {
+		maxConcurrentAPI = 1
+	}
 
 	// Process canvases in parallel with rate limiting
 	var wg sync.WaitGroup
 	var mu sync.Mutex
-	semaphore := make(chan struct{}, 10) // Limit concurrent requests
+	semaphore := make(chan struct{}, maxConcurrentAPI) // Limit concurrent canvases
+
+	for _, canvas := range pending {
+		// Stop handing out canvases once cancelled
+		select {
+		case semaphore <- struct{}{}: // Acquire semaphore
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
 
-	for _, canvas := range canvases {
 		wg.Add(1)
 		go func(canvas canvussdk.Canvas) {
 			defer wg.Done()
-			semaphore <- struct{}{} // Acquire semaphore
 			defer func() { <-semaphore }() // Release semaphore
 
-			rateLimiter.Wait() // Rate limit
-
 			// Extract media assets from widgets
-			widgetAssets := extractMediaAssets(ctx, session, canvas)
+			widgetAssets, err := extractMediaAssets(ctx, session, canvas)
 
 			// Extract media assets from canvas background
-			backgroundAssets := extractBackgroundAssets(ctx, session, canvas)
+			backgroundAssets, backgroundErr := extractBackgroundAssets(ctx, session, canvas)
+
+			// A canvas interrupted part-way may be missing assets, so it is not recorded
+			if ctx.Err() != nil {
+				return
+			}
+
+			// A canvas with any failed request may be missing assets and is retried on --resume
+			switch {
+			case err != nil && backgroundErr != nil:
+				err = fmt.Errorf("%w; %w", err, backgroundErr)
+			case backgroundErr != nil:
+				err = backgroundErr
+			}
+
+			entry := JournalEntry{CanvasID: canvas.ID, CanvasName: canvas.Name, Status: CanvasDone}
+			if err != nil {
+				entry.Status = CanvasFailed
+				entry.Error = err.Error()
+			} else {
+				entry.Assets = append(widgetAssets, backgroundAssets...)
+			}
+			if opts.Journal != nil {
+				if err := opts.Journal.Record(entry); err != nil {
+					logger.Warn("Failed to record canvas '%s' in journal: %v", canvas.Name, err)
+				}
+			}
+
+			// Only cache canvases read without errors, or their missing assets stay hidden until they change
+			if entry.Status == CanvasDone && opts.Cache != nil {
+				opts.Cache.Store(canvas, entry.Assets)
+			}
 
 			mu.Lock()
-			result.Assets = append(result.Assets, widgetAssets...)
-			result.Assets = append(result.Assets, backgroundAssets...)
+			if entry.Status == CanvasDone {
+				result.Assets = append(result.Assets, entry.Assets...)
+				result.CompletedCanvases = append(result.CompletedCanvases, canvas.ID)
+			} else {
+				result.Errors = append(result.Errors, fmt.Sprintf("Canvas '%s' (ID: %s): %s", canvas.Name, canvas.ID, entry.Error))
+			}
 			mu.Unlock()
 		}(canvas)
 	}
@@ -135,24 +198,88 @@ func DiscoverAllAssets(session *canvussdk.Session, requestsPerSecond int) (*Disc
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 
+	if err := ctx.Err(); err != nil {
+		result.Interrupted = true
+		logger.Warn("Discovery interrupted after %d of %d canvases", len(result.CompletedCanvases), len(canvases))
+		return result, fmt.Errorf("discovery interrupted: %w", err)
+	}
+
 	// Validate assets on the server
-	logger := logging.GetLogger()
 	logger.Info("🔍 Validating assets on Canvus Server...")
-	validationResult, err := validateAssetsOnServer(ctx, session, result.Assets)
-	if err != nil {
+	validationResult, err := validateAssetsOnServer(ctx, session, result.Assets, maxConcurrentAPI)
+	if ctx.Err() != nil {
+		// Keep the partial validation; unprobed hashes are reported as not validated
+		result.ServerValidation = validationResult
+		result.Interrupted = true
+		logger.Warn("Server validation interrupted after %d of %d assets", len(validationResult.Assets), validationResult.TotalAssets)
+		return result, fmt.Errorf("server validation interrupted: %w", ctx.Err())
+	} else if err != nil {
 		logger.Warn("Asset validation failed: %v", err)
 		result.Errors = append(result.Errors, fmt.Sprintf("Asset validation failed: %v", err))
 	} else {
 		result.ServerValidation = validationResult
-		logger.Info("✅ Server validation complete: %d/%d assets exist on server",
-			validationResult.ExistingAssets, validationResult.TotalAssets)
+		logger.Info("✅ Server validation complete: %d/%d assets exist on server (%d not found, %d probe errors)",
+			validationResult.ExistingAssets, validationResult.TotalAssets, validationResult.MissingAssets, validationResult.ErrorAssets)
 	}
 
 	return result, nil
 }
 
-// extractMediaAssets extracts media assets from widgets by calling the generic ListWidgets endpoint
-func extractMediaAssets(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas) []AssetInfo {
+// resumeFromJournal adds the assets of canvases a previous run completed to the result and
+// returns the canvases still to process, with the ones that previously failed first
+func resumeFromJournal(journal *Journal, canvases []canvussdk.Canvas, result *DiscoveryResult) []canvussdk.Canvas {
+	failed := make([]canvussdk.Canvas, 0)
+	remaining := make([]canvussdk.Canvas, 0, len(canvases))
+
+	for _, canvas := range canvases {
+		entry, found := journal.Previous(canvas.ID)
+		switch {
+		case found && entry.Status == CanvasDone:
+			result.Assets = append(result.Assets, entry.Assets...)
+			result.CompletedCanvases = append(result.CompletedCanvases, canvas.ID)
+			result.ResumedCanvases++
+		case found && entry.Status == CanvasFailed:
+			failed = append(failed, canvas)
+		default:
+			remaining = append(remaining, canvas)
+		}
+	}
+
+	return append(failed, remaining...)
+}
+
+// useAssetCache adds the cached assets of unchanged canvases to the result and returns the
+// canvases that still have to be read
+func useAssetCache(cache *AssetCache, canvases []canvussdk.Canvas, result *DiscoveryResult) []canvussdk.Canvas {
+	remaining := make([]canvussdk.Canvas, 0, len(canvases))
+
+	for _, canvas := range canvases {
+		assets, hit := cache.Lookup(canvas)
+		if !hit {
+			remaining = append(remaining, canvas)
+			continue
+		}
+		result.Assets = append(result.Assets, assets...)
+		result.CompletedCanvases = append(result.CompletedCanvases, canvas.ID)
+		result.CachedCanvases++
+	}
+
+	return remaining
+}
+
+// mediaDetails holds the asset fields shared by Image, PDF and Video widgets
+type mediaDetails struct {
+	Hash             string
+	OriginalFilename string
+	Title            string
+}
+
+// extractMediaAssets extracts media assets from a canvas.
+// It lists the widgets once, fetches every image, PDF and video on the canvas with one
+// request per type, and joins the two by widget ID. Widgets missing from the bulk listing
+// fall back to an individual GET. An error means the widgets could not be listed or a
+// fallback GET failed; the assets that could be read are still returned.
+func extractMediaAssets(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas) ([]AssetInfo, error) {
 	var assets []AssetInfo
 	logger := logging.GetLogger()
 
@@ -161,7 +288,7 @@ func extractMediaAssets(ctx context.Context, session *canvussdk.Session, canvas
 	widgets, err := session.ListWidgets(ctx, canvas.ID, nil)
 	if err != nil {
 		logger.Error("Failed to get widgets for canvas '%s' (ID: %s): %v", canvas.Name, canvas.ID, err)
-		return assets // Return empty slice if we can't get widgets
+		return assets, fmt.Errorf("failed to list widgets: %w", err)
 	}
 
 	logger.Verbose("Found %d widgets in canvas '%s' (ID: %s)", len(widgets), canvas.Name, canvas.ID)
@@ -174,11 +301,37 @@ func extractMediaAssets(ctx context.Context, session *canvussdk.Session, canvas
 		}
 	}
 
-	// Process each widget and extract media assets
+	// Fetch media details in bulk for the widget types present on this canvas
+	mediaTypes := make(map[string]bool)
+	for _, widget := range widgets {
+		if isMediaWidgetType(widget.WidgetType) {
+			mediaTypes[widget.WidgetType] = true
+		}
+	}
+	media := listMediaByType(ctx, session, canvas, mediaTypes)
+
+	// Join the widget list with the bulk media details
 	mediaCount := 0
+	fallbackCount := 0
+	var fallbackErrs []error
 	for _, widget := range widgets {
-		// Get the specific widget details to check for hash field
-		asset := extractAssetFromWidget(ctx, session, canvas, widget)
+		if !isMediaWidgetType(widget.WidgetType) {
+			continue
+		}
+
+		var asset *AssetInfo
+		if details, found := media[widget.ID]; found {
+			asset = newMediaAsset(canvas, widget, details)
+		} else {
+			// Not in the bulk listing (created since, or the list call failed) - fetch it directly
+			fallbackCount++
+			asset, err = extractAssetFromWidget(ctx, session, canvas, widget)
+			if err != nil {
+				fallbackErrs = append(fallbackErrs, err)
+				continue
+			}
+		}
+
 		if asset != nil {
 			assets = append(assets, *asset)
 			mediaCount++
@@ -186,21 +339,102 @@ func extractMediaAssets(ctx context.Context, session *canvussdk.Session, canvas
 		}
 	}
 
+	if fallbackCount > 0 {
+		logger.Verbose("Fetched %d widgets individually in canvas '%s' (not in bulk listing)", fallbackCount, canvas.Name)
+	}
 	logger.Verbose("Extracted %d media assets from canvas '%s' (ID: %s)", mediaCount, canvas.Name, canvas.ID)
-	return assets
+
+	if len(fallbackErrs) > 0 {
+		return assets, fmt.Errorf("failed to get %d of %d widgets missing from the bulk listing: %w",
+			len(fallbackErrs), fallbackCount, fallbackErrs[0])
+	}
+	return assets, nil
+}
+
+// isMediaWidgetType reports whether a widget type references an asset file
+func isMediaWidgetType(widgetType string) bool {
+	switch widgetType {
+	case "Image", "Pdf", "Video":
+		return true
+	default:
+		return false
+	}
+}
+
+// listMediaByType lists the images, PDFs and videos of a canvas (only for the requested types)
+// and returns their details keyed by widget ID. A failed list call is logged and its widgets
+// are left out, so the caller falls back to fetching them one by one.
+func listMediaByType(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas, mediaTypes map[string]bool) map[string]mediaDetails {
+	logger := logging.GetLogger()
+	media := make(map[string]mediaDetails)
+
+	if mediaTypes["Image"] {
+		images, err := session.ListImages(ctx, canvas.ID)
+		if err != nil {
+			logger.Verbose("Failed to list images for canvas '%s' (ID: %s): %v", canvas.Name, canvas.ID, err)
+		}
+		for _, image := range images {
+			media[image.ID] = mediaDetails{Hash: image.Hash, OriginalFilename: image.OriginalFilename, Title: image.Title}
+		}
+	}
+
+	if mediaTypes["Pdf"] {
+		pdfs, err := session.ListPDFs(ctx, canvas.ID)
+		if err != nil {
+			logger.Verbose("Failed to list PDFs for canvas '%s' (ID: %s): %v", canvas.Name, canvas.ID, err)
+		}
+		for _, pdf := range pdfs {
+			media[pdf.ID] = mediaDetails{Hash: pdf.Hash, OriginalFilename: pdf.OriginalFilename, Title: pdf.Title}
+		}
+	}
+
+	if mediaTypes["Video"] {
+		videos, err := session.ListVideos(ctx, canvas.ID)
+		if err != nil {
+			logger.Verbose("Failed to list videos for canvas '%s' (ID: %s): %v", canvas.Name, canvas.ID, err)
+		}
+		for _, video := range videos {
+			media[video.ID] = mediaDetails{Hash: video.Hash, OriginalFilename: video.OriginalFilename, Title: video.Title}
+		}
+	}
+
+	return media
 }
 
-// extractBackgroundAssets extracts media assets from canvas background images
-func extractBackgroundAssets(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas) []AssetInfo {
+// newMediaAsset builds an AssetInfo from a widget and its media details, or nil if it has no hash
+func newMediaAsset(canvas canvussdk.Canvas, widget canvussdk.Widget, details mediaDetails) *AssetInfo {
+	if details.Hash == "" {
+		logging.GetLogger().Verbose("No hash found for widget ID=%s, Type=%s - not a media asset", widget.ID, widget.WidgetType)
+		return nil
+	}
+
+	return &AssetInfo{
+		Hash:             details.Hash,
+		WidgetType:       widget.WidgetType,
+		OriginalFilename: details.OriginalFilename,
+		CanvasID:         canvas.ID,
+		CanvasName:       canvas.Name,
+		WidgetID:         widget.ID,
+		WidgetName:       details.Title,
+	}
+}
+
+// extractBackgroundAssets extracts media assets from canvas background images.
+// A canvas without a background (404) has none; any other failure is returned.
+func extractBackgroundAssets(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas) ([]AssetInfo, error) {
 	var assets []AssetInfo
 	logger := logging.GetLogger()
 
 	// Get canvas background
 	logger.Verbose("Getting background for canvas '%s' (ID: %s)", canvas.Name, canvas.ID)
 	background, err := session.GetCanvasBackground(ctx, canvas.ID)
+	if isNotFound(err) {
+		logger.Verbose("No background for canvas '%s' (ID: %s)", canvas.Name, canvas.ID)
+		return assets, nil
+	}
 	if err != nil {
-		logger.Verbose("Failed to get background for canvas '%s' (ID: %s): %v", canvas.Name, canvas.ID, err)
-		return assets // Return empty slice if we can't get background
+		logger.Warn("Failed to get background for canvas '%s' (ID: %s): %v", canvas.Name, canvas.ID, err)
+		return assets, fmt.Errorf("failed to get background: %w", err)
 	}
 
 	// Check if background has an image with a hash
@@ -223,64 +457,148 @@ func extractBackgroundAssets(ctx context.Context, session *canvussdk.Session, ca
 		logger.Verbose("No background image found for canvas '%s' (ID: %s)", canvas.Name, canvas.ID)
 	}
 
-	return assets
+	return assets, nil
 }
 
-// validateAssetsOnServer validates that assets exist on the Canvus server using GET /assets/{hash}
-func validateAssetsOnServer(ctx context.Context, session *canvussdk.Session, assets []AssetInfo) (*ServerValidationResult, error) {
+// validateAssetsOnServer checks that each unique asset is served by the Canvus server.
+// Assets are probed on a pool of maxConcurrent workers without downloading their content:
+// images, PDFs and backgrounds via their mipmap info, everything else (and any mipmap
+// failure) via a one-byte ranged GET of /assets/{hash}.
+func validateAssetsOnServer(ctx context.Context, session *canvussdk.Session, assets []AssetInfo, maxConcurrent int) (*ServerValidationResult, error) {
 	logger := logging.GetLogger()
 
+	// Get unique assets by hash to avoid duplicate validation
+	uniqueAssets := make(map[string]AssetInfo)
+	for _, asset := range assets {
+		if asset.Hash != "" {
+			uniqueAssets[asset.Hash] = asset
+		}
+	}
+
 	result := &ServerValidationResult{
-		TotalAssets:     len(assets),
+		TotalAssets:     len(uniqueAssets),
 		ExistingAssets:  0,
 		MissingAssets:   0,
 		ValidationErrors: make([]string, 0),
+		Assets:          make(map[string]AssetValidation, len(uniqueAssets)),
 	}
 
-	if len(assets) == 0 {
+	if len(uniqueAssets) == 0 {
 		return result, nil
 	}
 
-	// Get unique assets by hash to avoid duplicate validation
-	uniqueAssets := make(map[string]AssetInfo)
-	for _, asset := range assets {
-		if asset.Hash != "" {
-			uniqueAssets[asset.Hash] = asset
-		}
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
 	}
 
-	logger.Verbose("Validating %d unique assets on server", len(uniqueAssets))
+	logger.Verbose("Validating %d unique assets on server with %d workers", len(uniqueAssets), maxConcurrent)
 
-	// Validate each unique asset
-	for hash, asset := range uniqueAssets {
-		logger.Verbose("Validating asset hash: %s (%s) for canvas: %s", hash, asset.WidgetType, asset.CanvasID)
-		logger.Verbose("   Hash length: %d characters", len(hash))
+	jobs := make(chan AssetInfo)
+	results := make(chan AssetValidation)
 
-		// Try to get the asset from the server
-		// We need a canvas ID for the request, so we'll use the first canvas that has this asset
-		assetData, err := session.GetAssetByHash(ctx, asset.CanvasID, hash)
-		if err != nil {
-			// Asset doesn't exist on server or there's an error
+	var wg sync.WaitGroup
+	for i := 0; i < maxConcurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for asset := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain the queue without probing
+				}
+				validation := probeAsset(ctx, session, asset)
+				if ctx.Err() != nil {
+					continue // The probe was cut short; its outcome says nothing about the asset
+				}
+				results <- validation
+			}
+		}()
+	}
+
+	go func() {
+	feed:
+		for _, asset := range uniqueAssets {
+			select {
+			case jobs <- asset:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	for validation := range results {
+		result.Assets[validation.Hash] = validation
+		asset := uniqueAssets[validation.Hash]
+
+		switch validation.Status {
+		case ServerStatusServed:
+			result.ExistingAssets++
+			logger.Verbose("✅ Asset exists on server: %s (%s) - Hash: %s (probe: %s)",
+				asset.WidgetName, asset.WidgetType, validation.Hash, validation.Probe)
+		case ServerStatusMissing:
 			result.MissingAssets++
-			logger.Verbose("❌ Asset validation failed: %s (%s) - Hash: %s",
-				asset.WidgetName, asset.WidgetType, hash)
-			logger.Verbose("   Server error: %v", err)
+			logger.Verbose("❌ Asset not found on server: %s (%s) - Hash: %s",
+				asset.WidgetName, asset.WidgetType, validation.Hash)
 			result.ValidationErrors = append(result.ValidationErrors,
-				fmt.Sprintf("Missing: %s (%s) - Hash: %s - Error: %v", asset.WidgetName, asset.WidgetType, hash, err))
-		} else {
-			// Asset exists on server
-			result.ExistingAssets++
-			logger.Verbose("✅ Asset exists on server: %s (%s) - Hash: %s",
-				asset.WidgetName, asset.WidgetType, hash)
-			logger.Verbose("   Asset data size: %d bytes", len(assetData))
+				fmt.Sprintf("Missing: %s (%s) - Hash: %s - Error: %s", asset.WidgetName, asset.WidgetType, validation.Hash, validation.Error))
+		default:
+			result.ErrorAssets++
+			logger.Verbose("⚠️  Asset validation failed: %s (%s) - Hash: %s - %s",
+				asset.WidgetName, asset.WidgetType, validation.Hash, validation.Error)
+			result.ValidationErrors = append(result.ValidationErrors,
+				fmt.Sprintf("Error: %s (%s) - Hash: %s - Error: %s", asset.WidgetName, asset.WidgetType, validation.Hash, validation.Error))
 		}
 	}
 
 	return result, nil
 }
 
-// extractAssetFromWidget extracts asset information from a widget if it has a hash field
-func extractAssetFromWidget(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas, widget canvussdk.Widget) *AssetInfo {
+// probeAsset checks a single asset on the server using the cheapest probe for its type
+func probeAsset(ctx context.Context, session *canvussdk.Session, asset AssetInfo) AssetValidation {
+	validation := AssetValidation{
+		Hash:       asset.Hash,
+		WidgetType: asset.WidgetType,
+		CanvasID:   asset.CanvasID,
+	}
+
+	// Images and PDFs have mipmaps; their info is a tiny JSON document
+	switch asset.WidgetType {
+	case "Image", "Pdf", "CanvasBackground":
+		validation.Probe = "mipmap"
+		if _, err := session.GetMipmapInfo(ctx, asset.CanvasID, asset.Hash, nil); err == nil {
+			validation.Status = ServerStatusServed
+			return validation
+		}
+		// Mipmaps may not have been generated yet - fall through to the asset itself
+	}
+
+	validation.Probe = "range"
+	stat, err := session.StatAssetByHash(ctx, asset.CanvasID, asset.Hash)
+	if err != nil {
+		validation.Error = err.Error()
+		validation.Status = ServerStatusError
+		var apiErr *canvussdk.APIError
+		if errors.As(err, &apiErr) {
+			validation.StatusCode = apiErr.StatusCode
+			if apiErr.StatusCode == http.StatusNotFound {
+				validation.Status = ServerStatusMissing
+			}
+		}
+		return validation
+	}
+
+	validation.Status = ServerStatusServed
+	if stat.Size > 0 {
+		validation.Size = stat.Size
+	}
+	return validation
+}
+
+// extractAssetFromWidget extracts asset information from a widget if it has a hash field.
+// A widget deleted since it was listed (404) is skipped; any other failure is returned.
+func extractAssetFromWidget(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas, widget canvussdk.Widget) (*AssetInfo, error) {
 	logger := logging.GetLogger()
 
 	// Get the specific widget details based on type
@@ -298,12 +616,16 @@ func extractAssetFromWidget(ctx context.Context, session *canvussdk.Session, can
 		widgetDetails, err = session.GetVideo(ctx, canvas.ID, widget.ID)
 	default:
 		logger.Verbose("Skipping non-media widget type: %s", widget.WidgetType)
-		return nil // Not a media widget type
+		return nil, nil // Not a media widget type
 	}
 
+	if isNotFound(err) {
+		logger.Verbose("Widget ID=%s, Type=%s was deleted since it was listed", widget.ID, widget.WidgetType)
+		return nil, nil
+	}
 	if err != nil {
 		logger.Verbose("Failed to get widget details for ID=%s, Type=%s: %v", widget.ID, widget.WidgetType, err)
-		return nil
+		return nil, fmt.Errorf("failed to get %s widget %s: %w", widget.WidgetType, widget.ID, err)
 	}
 
 	// Extract hash and other fields using reflection
@@ -345,7 +667,7 @@ func extractAssetFromWidget(ctx context.Context, session *canvussdk.Session, can
 	// Only return asset if it has a hash (media assets only)
 	if hash == "" {
 		logger.Verbose("No hash found for widget ID=%s, Type=%s - not a media asset", widget.ID, widget.WidgetType)
-		return nil
+		return nil, nil
 	}
 
 	return &AssetInfo{
@@ -356,7 +678,13 @@ func extractAssetFromWidget(ctx context.Context, session *canvussdk.Session, can
 		CanvasName:       canvas.Name,
 		WidgetID:         widget.ID,
 		WidgetName:       name,
-	}
+	}, nil
+}
+
+// isNotFound reports whether err is a 404 from the Canvus Server
+func isNotFound(err error) bool {
+	var apiErr *canvussdk.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
 }
 
 
@@ -0,0 +1,327 @@
+package canvus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	canvussdk "canvus-go-api/canvus"
+)
+
+// mockCanvusServer serves a single canvas with a mix of media and non-media widgets
+// and counts every request it receives.
+type mockCanvusServer struct {
+	*httptest.Server
+	requests atomic.Int64
+	widgets  []canvussdk.Widget
+}
+
+func newMockCanvusServer(widgetsPerType int) *mockCanvusServer {
+	m := &mockCanvusServer{}
+	for i := 0; i < widgetsPerType; i++ {
+		for _, widgetType := range []string{"Image", "Pdf", "Video", "Note"} {
+			m.widgets = append(m.widgets, canvussdk.Widget{
+				ID:         fmt.Sprintf("%s-%d", strings.ToLower(widgetType), i),
+				WidgetType: widgetType,
+			})
+		}
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+func (m *mockCanvusServer) handle(w http.ResponseWriter, r *http.Request) {
+	m.requests.Add(1)
+
+	// /api/v1/canvases/{canvasID}/{collection}[/{widgetID}]
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/canvases/"), "/")
+	if len(parts) < 2 {
+		http.NotFound(w, r)
+		return
+	}
+	collection := parts[1]
+
+	widgetType := map[string]string{"images": "Image", "pdfs": "Pdf", "videos": "Video"}[collection]
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case collection == "widgets":
+		json.NewEncoder(w).Encode(m.widgets)
+	case widgetType != "" && len(parts) == 2:
+		var media []map[string]string
+		for _, widget := range m.widgets {
+			if widget.WidgetType == widgetType {
+				media = append(media, mediaJSON(widget))
+			}
+		}
+		json.NewEncoder(w).Encode(media)
+	case widgetType != "" && len(parts) == 3:
+		json.NewEncoder(w).Encode(mediaJSON(canvussdk.Widget{ID: parts[2], WidgetType: widgetType}))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func mediaJSON(widget canvussdk.Widget) map[string]string {
+	return map[string]string{
+		"id":          widget.ID,
+		"widget_type": widget.WidgetType,
+		"hash":        "hash" + strings.ReplaceAll(widget.ID, "-", ""),
+		"title":       "Title " + widget.ID,
+	}
+}
+
+// extractMediaAssetsPerWidget is the previous discovery strategy (one GET per media widget),
+// kept here as the benchmark baseline
+func extractMediaAssetsPerWidget(ctx context.Context, session *canvussdk.Session, canvas canvussdk.Canvas) ([]AssetInfo, error) {
+	var assets []AssetInfo
+	widgets, err := session.ListWidgets(ctx, canvas.ID, nil)
+	if err != nil {
+		return assets, err
+	}
+	for _, widget := range widgets {
+		if asset, _ := extractAssetFromWidget(ctx, session, canvas, widget); asset != nil {
+			assets = append(assets, *asset)
+		}
+	}
+	return assets, nil
+}
+
+func TestExtractMediaAssetsUsesBulkListing(t *testing.T) {
+	server := newMockCanvusServer(50)
+	defer server.Close()
+
+	session := canvussdk.NewSession(server.URL + "/api/v1")
+	canvas := canvussdk.Canvas{ID: "canvas-1", Name: "Test Canvas"}
+
+	assets, err := extractMediaAssets(context.Background(), session, canvas)
+	if err != nil {
+		t.Fatalf("extractMediaAssets: %v", err)
+	}
+
+	if len(assets) != 150 {
+		t.Fatalf("expected 150 media assets, got %d", len(assets))
+	}
+	// One widget listing plus one listing per media type
+	if got := server.requests.Load(); got != 4 {
+		t.Errorf("expected 4 requests, got %d", got)
+	}
+	for _, asset := range assets {
+		if asset.Hash != "hash"+strings.ReplaceAll(asset.WidgetID, "-", "") {
+			t.Errorf("widget %s joined to wrong hash %s", asset.WidgetID, asset.Hash)
+		}
+		if asset.WidgetName != "Title "+asset.WidgetID {
+			t.Errorf("widget %s joined to wrong title %q", asset.WidgetID, asset.WidgetName)
+		}
+	}
+}
+
+func BenchmarkExtractMediaAssets(b *testing.B) {
+	strategies := []struct {
+		name    string
+		extract func(context.Context, *canvussdk.Session, canvussdk.Canvas) ([]AssetInfo, error)
+	}{
+		{"per-widget", extractMediaAssetsPerWidget},
+		{"bulk", extractMediaAssets},
+	}
+
+	for _, widgetsPerType := range []int{10, 100} {
+		for _, strategy := range strategies {
+			b.Run(fmt.Sprintf("%s/widgets=%d", strategy.name, widgetsPerType*4), func(b *testing.B) {
+				server := newMockCanvusServer(widgetsPerType)
+				defer server.Close()
+
+				session := canvussdk.NewSession(server.URL + "/api/v1")
+				canvas := canvussdk.Canvas{ID: "canvas-1", Name: "Benchmark Canvas"}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					strategy.extract(context.Background(), session, canvas)
+				}
+				b.StopTimer()
+
+				b.ReportMetric(float64(server.requests.Load())/float64(b.N), "requests/op")
+			})
+		}
+	}
+}
+
+func TestValidateAssetsOnServerProbes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/mipmaps/imagehash":
+			json.NewEncoder(w).Encode(map[string]interface{}{"max_level": 3, "pages": 1})
+		case "/api/v1/assets/videohash":
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("expected a one-byte range request, got Range=%q", r.Header.Get("Range"))
+			}
+			w.Header().Set("Content-Range", "bytes 0-0/5000000000")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte{0})
+		case "/api/v1/assets/brokenhash":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	session := canvussdk.NewSession(server.URL + "/api/v1")
+	assets := []AssetInfo{
+		{Hash: "imagehash", WidgetType: "Image", CanvasID: "c1"},
+		{Hash: "imagehash", WidgetType: "Image", CanvasID: "c2"},
+		{Hash: "videohash", WidgetType: "Video", CanvasID: "c1"},
+		{Hash: "gonehash", WidgetType: "Pdf", CanvasID: "c1"},
+		{Hash: "brokenhash", WidgetType: "Video", CanvasID: "c1"},
+	}
+
+	result, err := validateAssetsOnServer(context.Background(), session, assets, 3)
+	if err != nil {
+		t.Fatalf("validateAssetsOnServer: %v", err)
+	}
+
+	if result.TotalAssets != 4 || result.ExistingAssets != 2 || result.MissingAssets != 1 || result.ErrorAssets != 1 {
+		t.Errorf("unexpected counts: total=%d existing=%d missing=%d errors=%d",
+			result.TotalAssets, result.ExistingAssets, result.MissingAssets, result.ErrorAssets)
+	}
+
+	expected := map[string]struct {
+		status ServerStatus
+		probe  string
+	}{
+		"imagehash":  {ServerStatusServed, "mipmap"},
+		"videohash":  {ServerStatusServed, "range"},
+		"gonehash":   {ServerStatusMissing, "range"},
+		"brokenhash": {ServerStatusError, "range"},
+	}
+	for hash, want := range expected {
+		got := result.Assets[hash]
+		if got.Status != want.status || got.Probe != want.probe {
+			t.Errorf("%s: got status=%s probe=%s, want status=%s probe=%s", hash, got.Status, got.Probe, want.status, want.probe)
+		}
+	}
+	if size := result.Assets["videohash"].Size; size != 5000000000 {
+		t.Errorf("videohash: expected size from Content-Range, got %d", size)
+	}
+}
+
+func TestDiscoverAllAssetsStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/canvases":
+			json.NewEncoder(w).Encode([]canvussdk.Canvas{{ID: "c1"}, {ID: "c2"}, {ID: "c3"}})
+		case "/api/v1/canvases/c2/widgets":
+			cancel() // Ctrl+C while the second canvas is being read
+			w.Write([]byte(`[]`))
+		default:
+			if strings.HasSuffix(r.URL.Path, "/widgets") {
+				w.Write([]byte(`[]`))
+			} else {
+				w.Write([]byte(`{}`))
+			}
+		}
+	}))
+	defer server.Close()
+
+	journalPath := filepath.Join(t.TempDir(), JournalFilename)
+	journal, err := OpenJournal(journalPath, false)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+
+	session := canvussdk.NewSession(server.URL + "/api/v1")
+	result, err := DiscoverAllAssets(ctx, session, DiscoveryOptions{MaxConcurrentAPI: 1, Journal: journal})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation error, got %v", err)
+	}
+
+	if result == nil || !result.Interrupted {
+		t.Fatalf("expected a partial result marked as interrupted, got %+v", result)
+	}
+	if len(result.CompletedCanvases) != 1 || result.CompletedCanvases[0] != "c1" {
+		t.Errorf("expected only c1 to be completed, got %v", result.CompletedCanvases)
+	}
+	if result.ServerValidation != nil {
+		t.Error("expected server validation to be skipped after cancellation")
+	}
+
+	// The canvas read while cancelled may be incomplete and must be read again on --resume
+	journal.Close()
+	journal, err = OpenJournal(journalPath, true)
+	if err != nil {
+		t.Fatalf("OpenJournal(resume): %v", err)
+	}
+	defer journal.Close()
+	if _, found := journal.Previous("c2"); found {
+		t.Error("expected the interrupted canvas not to be journaled")
+	}
+	if entry, found := journal.Previous("c1"); !found || entry.Status != CanvasDone {
+		t.Errorf("expected c1 to be journaled as done, got %+v", entry)
+	}
+}
+
+// partialFailureServer serves c1, whose only image is missing from the bulk listing and fails
+// to load individually, c2, whose background fails to load, and c3, which reads cleanly
+func partialFailureServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/canvases":
+			json.NewEncoder(w).Encode([]canvussdk.Canvas{{ID: "c1", ModifiedAt: "2024-01-01T00:00:00Z"}, {ID: "c2", ModifiedAt: "2024-01-01T00:00:00Z"}, {ID: "c3", ModifiedAt: "2024-01-01T00:00:00Z"}})
+		case "/api/v1/canvases/c1/widgets":
+			json.NewEncoder(w).Encode([]canvussdk.Widget{{ID: "img-1", WidgetType: "Image"}})
+		case "/api/v1/canvases/c1/images", "/api/v1/canvases/c2/widgets", "/api/v1/canvases/c3/widgets":
+			w.Write([]byte(`[]`))
+		case "/api/v1/canvases/c1/background", "/api/v1/canvases/c3/background":
+			w.Write([]byte(`{}`))
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+}
+
+func TestDiscoverAllAssetsJournalsPartialCanvasesAsFailed(t *testing.T) {
+	server := partialFailureServer()
+	defer server.Close()
+
+	journalPath := filepath.Join(t.TempDir(), JournalFilename)
+	journal, err := OpenJournal(journalPath, false)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+
+	session := canvussdk.NewSession(server.URL + "/api/v1")
+	result, err := DiscoverAllAssets(context.Background(), session, DiscoveryOptions{MaxConcurrentAPI: 1, Journal: journal})
+	if err != nil {
+		t.Fatalf("DiscoverAllAssets: %v", err)
+	}
+	journal.Close()
+
+	if len(result.CompletedCanvases) != 1 || len(result.Errors) != 2 {
+		t.Errorf("expected c1 and c2 to fail, got completed=%v errors=%v", result.CompletedCanvases, result.Errors)
+	}
+
+	journal, err = OpenJournal(journalPath, true)
+	if err != nil {
+		t.Fatalf("OpenJournal(resume): %v", err)
+	}
+	defer journal.Close()
+
+	for id, want := range map[string]string{"c1": "img-1", "c2": "background"} {
+		entry, found := journal.Previous(id)
+		if !found || entry.Status != CanvasFailed || !strings.Contains(entry.Error, want) {
+			t.Errorf("%s: expected a failed entry mentioning %q, got %+v", id, want, entry)
+		}
+	}
+}
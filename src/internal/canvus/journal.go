@@ -0,0 +1,189 @@
+package canvus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+)
+
+// JournalFilename is the default discovery journal file name in the output folder
+const JournalFilename = "discovery_journal.jsonl"
+
+// CanvasStatus is the outcome of processing a single canvas
+type CanvasStatus string
+
+const (
+	CanvasDone   CanvasStatus = "done"   // All assets of the canvas were extracted
+	CanvasFailed CanvasStatus = "failed" // The canvas could not be read and must be retried
+)
+
+// JournalEntry records the outcome of one canvas. Completed canvases carry their assets
+// so a resumed run can rebuild the full result without asking the server again.
+type JournalEntry struct {
+	CanvasID   string       `json:"canvas_id"`
+	CanvasName string       `json:"canvas_name"`
+	Status     CanvasStatus `json:"status"`
+	Assets     []AssetInfo  `json:"assets,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	Time       time.Time    `json:"time"`
+}
+
+// Journal is an append-only, one-JSON-object-per-line record of per-canvas discovery progress.
+// Each entry is written as soon as its canvas finishes, so a crash loses at most the canvases in flight.
+type Journal struct {
+	path    string
+	file    *os.File
+	mu      sync.Mutex
+	entries map[string]JournalEntry // Canvas ID -> latest entry from the previous run(s)
+}
+
+// OpenJournal opens the journal at path. With resume the existing entries are loaded and new
+// entries are appended; otherwise any previous journal is discarded.
+func OpenJournal(path string, resume bool) (*Journal, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create journal directory: %w", err)
+		}
+	}
+
+	j := &Journal{
+		path:    path,
+		entries: make(map[string]JournalEntry),
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		if err := j.load(); err != nil {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s: %w", path, err)
+	}
+	j.file = file
+
+	if resume {
+		if err := j.terminateLastLine(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return j, nil
+}
+
+// terminateLastLine ends a line cut short by a killed run, so the next entry starts on its own line
+func (j *Journal) terminateLastLine() error {
+	info, err := j.file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	reader, err := os.Open(j.path)
+	if err != nil {
+		return fmt.Errorf("failed to open journal %s: %w", j.path, err)
+	}
+	defer reader.Close()
+
+	last := make([]byte, 1)
+	if _, err := reader.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("failed to read journal %s: %w", j.path, err)
+	}
+	if last[0] != '\n' {
+		if _, err := j.file.Write([]byte{'\n'}); err != nil {
+			return fmt.Errorf("failed to write journal %s: %w", j.path, err)
+		}
+	}
+
+	return nil
+}
+
+// load reads the entries of an existing journal; later entries for a canvas replace earlier ones
+func (j *Journal) load() error {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open journal %s: %w", j.path, err)
+	}
+	defer file.Close()
+
+	logger := logging.GetLogger()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Canvases with many assets make long lines
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// The last line may be cut short if the previous run was killed mid-write
+			logger.Verbose("Skipping unreadable journal line %d: %v", line, err)
+			continue
+		}
+		j.entries[entry.CanvasID] = entry
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read journal %s: %w", j.path, err)
+	}
+
+	return nil
+}
+
+// Previous returns the entry recorded for a canvas by a previous run, if any
+func (j *Journal) Previous(canvasID string) (JournalEntry, bool) {
+	entry, ok := j.entries[canvasID]
+	return entry, ok
+}
+
+// Record appends the outcome of a canvas to the journal
+func (j *Journal) Record(entry JournalEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry for canvas %s: %w", entry.CanvasID, err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write journal %s: %w", j.path, err)
+	}
+
+	return nil
+}
+
+// Path returns the journal file path
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// Remove closes and deletes the journal once the run it tracks has finished
+func (j *Journal) Remove() error {
+	j.file.Close()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal %s: %w", j.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package canvus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	canvussdk "canvus-go-api/canvus"
+)
+
+func TestJournalResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFilename)
+
+	journal, err := OpenJournal(path, false)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	journal.Record(JournalEntry{CanvasID: "c1", Status: CanvasDone, Assets: []AssetInfo{{Hash: "h1", CanvasID: "c1"}}})
+	journal.Record(JournalEntry{CanvasID: "c2", Status: CanvasFailed, Error: "timeout"})
+	journal.Record(JournalEntry{CanvasID: "c3", Status: CanvasFailed, Error: "timeout"})
+	journal.Record(JournalEntry{CanvasID: "c3", Status: CanvasDone, Assets: []AssetInfo{{Hash: "h3", CanvasID: "c3"}}})
+	journal.Close()
+
+	// Simulate a run killed mid-write
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(`{"canvas_id":"c4","sta`)
+	file.Close()
+
+	journal, err = OpenJournal(path, true)
+	if err != nil {
+		t.Fatalf("OpenJournal(resume): %v", err)
+	}
+	defer journal.Close()
+
+	// New entries must not be glued to the cut-short line
+	journal.Record(JournalEntry{CanvasID: "c6", Status: CanvasDone})
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "\n{\"canvas_id\":\"c6\"") {
+		t.Errorf("expected the new entry on its own line, journal was:\n%s", data)
+	}
+
+	canvases := []canvussdk.Canvas{{ID: "c1"}, {ID: "c2"}, {ID: "c3"}, {ID: "c4"}, {ID: "c5"}}
+	result := &DiscoveryResult{}
+	pending := resumeFromJournal(journal, canvases, result)
+
+	if result.ResumedCanvases != 2 || len(result.Assets) != 2 {
+		t.Errorf("expected 2 resumed canvases with 2 assets, got %d canvases and %d assets", result.ResumedCanvases, len(result.Assets))
+	}
+
+	var ids []string
+	for _, canvas := range pending {
+		ids = append(ids, canvas.ID)
+	}
+	// The failed canvas comes first, then the ones never processed
+	if len(ids) != 3 || ids[0] != "c2" || ids[1] != "c4" || ids[2] != "c5" {
+		t.Errorf("unexpected pending order: %v", ids)
+	}
+}
+
+func TestJournalWithoutResumeStartsOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFilename)
+
+	journal, _ := OpenJournal(path, false)
+	journal.Record(JournalEntry{CanvasID: "c1", Status: CanvasDone})
+	journal.Close()
+
+	journal, _ = OpenJournal(path, false)
+	journal.Close()
+
+	journal, _ = OpenJournal(path, true)
+	defer journal.Close()
+	if _, found := journal.Previous("c1"); found {
+		t.Errorf("expected a fresh run to discard the previous journal")
+	}
+}
@@ -0,0 +1,248 @@
+package canvus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	canvussdk "canvus-go-api/canvus"
+)
+
+// ReviewFolderName is the default folder in the output folder for images rebuilt from mipmaps
+const ReviewFolderName = "degraded_review"
+
+// DegradedPage is one page of a rebuilt asset, saved as the WebP image the server sends
+type DegradedPage struct {
+	Page   int    `json:"page"`
+	Path   string `json:"path"`
+	Level  int    `json:"level"` // Mipmap level used; 0 is the highest resolution
+	Width  int    `json:"width"` // Approximate size of the level, from the level 0 resolution
+	Height int    `json:"height"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// DegradedAsset is a lossy reconstruction of an asset whose original file is gone. It is never
+// written to the assets folder; a person decides whether it is good enough to re-upload.
+type DegradedAsset struct {
+	Hash             string         `json:"hash"`
+	WidgetType       string         `json:"widget_type"`
+	OriginalFilename string         `json:"original_filename"`
+	CanvasID         string         `json:"canvas_id"`
+	CanvasName       string         `json:"canvas_name"`
+	Width            int            `json:"width"` // Resolution of mipmap level 0
+	Height           int            `json:"height"`
+	PageCount        int            `json:"page_count"` // Pages the server reports
+	Pages            []DegradedPage `json:"pages"`      // Pages rebuilt; may be fewer than PageCount
+	Lossy            bool           `json:"lossy"`
+	RebuiltAt        time.Time      `json:"rebuilt_at"`
+}
+
+// Complete reports whether every page the server reports was rebuilt
+func (d *DegradedAsset) Complete() bool {
+	return len(d.Pages) >= d.PageCount
+}
+
+// DegradedResult contains the assets rebuilt from mipmaps
+type DegradedResult struct {
+	ReviewFolder string                    `json:"review_folder"`
+	Assets       map[string]*DegradedAsset `json:"assets"`
+	Failed       map[string]string         `json:"failed"` // Hash -> why it could not be rebuilt
+	Duration     time.Duration             `json:"duration"`
+}
+
+// HasMipmaps reports whether the server keeps mipmaps for a widget type
+func HasMipmaps(widgetType string) bool {
+	switch widgetType {
+	case "Image", "Pdf", "CanvasBackground":
+		return true
+	}
+	return false
+}
+
+// MipmapRebuilder rebuilds lost images and PDFs from the mipmap levels the server still holds
+type MipmapRebuilder struct {
+	session       *canvussdk.Session
+	reviewFolder  string
+	maxConcurrent int
+	logger        *logging.Logger
+}
+
+// NewMipmapRebuilder creates a rebuilder that saves its results in reviewFolder
+func NewMipmapRebuilder(session *canvussdk.Session, reviewFolder string, maxConcurrent int) *MipmapRebuilder {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &MipmapRebuilder{
+		session:       session,
+		reviewFolder:  reviewFolder,
+		maxConcurrent: maxConcurrent,
+		logger:        logging.GetLogger(),
+	}
+}
+
+// Rebuild saves the highest-resolution mipmap level of every page of the given hashes. Hashes whose
+// widgets have no mipmaps are skipped. If ctx is cancelled the assets rebuilt so far are returned
+// with the context error.
+func (m *MipmapRebuilder) Rebuild(ctx context.Context, assets []AssetInfo, hashes []string) (*DegradedResult, error) {
+	start := time.Now()
+	result := &DegradedResult{
+		ReviewFolder: m.reviewFolder,
+		Assets:       make(map[string]*DegradedAsset),
+		Failed:       make(map[string]string),
+	}
+
+	byHash := make(map[string][]AssetInfo)
+	for _, asset := range assets {
+		if HasMipmaps(asset.WidgetType) {
+			byHash[asset.Hash] = append(byHash[asset.Hash], asset)
+		}
+	}
+	wanted := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		if len(byHash[hash]) > 0 {
+			wanted = append(wanted, hash)
+		}
+	}
+	if len(wanted) == 0 {
+		m.logger.Info("No missing images or PDFs to rebuild from mipmaps")
+		return result, nil
+	}
+
+	if err := os.MkdirAll(m.reviewFolder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create review folder: %w", err)
+	}
+
+	m.logger.Info("🖼️  Rebuilding %d missing images and PDFs from mipmaps into %s...", len(wanted), m.reviewFolder)
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < m.maxConcurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hash := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain the queue without downloading
+				}
+				degraded, err := m.rebuild(ctx, byHash[hash])
+
+				mu.Lock()
+				if degraded != nil {
+					result.Assets[hash] = degraded
+				} else if ctx.Err() == nil {
+					result.Failed[hash] = err.Error()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, hash := range wanted {
+		select {
+		case jobs <- hash:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	result.Duration = time.Since(start)
+
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("mipmap rebuild interrupted: %w", err)
+	}
+
+	m.logger.Info("✅ Rebuilt %d assets from mipmaps in %v (%d could not be rebuilt)",
+		len(result.Assets), result.Duration.Round(time.Millisecond), len(result.Failed))
+	return result, nil
+}
+
+// rebuild saves the pages of one asset through the first canvas whose mipmaps the server serves.
+// An asset with some pages saved is returned even if others failed.
+func (m *MipmapRebuilder) rebuild(ctx context.Context, widgets []AssetInfo) (*DegradedAsset, error) {
+	var lastErr error
+	for _, widget := range widgets {
+		info, err := m.session.GetMipmapInfo(ctx, widget.CanvasID, widget.Hash, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		degraded := &DegradedAsset{
+			Hash:             widget.Hash,
+			WidgetType:       widget.WidgetType,
+			OriginalFilename: widget.OriginalFilename,
+			CanvasID:         widget.CanvasID,
+			CanvasName:       widget.CanvasName,
+			Width:            info.Resolution.Width,
+			Height:           info.Resolution.Height,
+			PageCount:        max(info.Pages, 1),
+			Lossy:            true,
+			RebuiltAt:        time.Now(),
+		}
+
+		for page := 0; page < degraded.PageCount; page++ {
+			saved, err := m.savePage(ctx, widget, info, page, degraded.PageCount > 1)
+			if err != nil {
+				m.logger.Verbose("No mipmap for page %d of %s: %v", page, widget.Hash, err)
+				lastErr = err
+				continue
+			}
+			degraded.Pages = append(degraded.Pages, *saved)
+		}
+		if len(degraded.Pages) > 0 {
+			sort.Slice(degraded.Pages, func(i, j int) bool { return degraded.Pages[i].Page < degraded.Pages[j].Page })
+			return degraded, nil
+		}
+	}
+	return nil, fmt.Errorf("no mipmaps served: %w", lastErr)
+}
+
+// savePage downloads the highest-resolution level the server has for a page. Level 0 is tried
+// first; the server may not have generated every level.
+func (m *MipmapRebuilder) savePage(ctx context.Context, widget AssetInfo, info *canvussdk.MipmapInfo, page int, paged bool) (*DegradedPage, error) {
+	var pageParam *int
+	path := filepath.Join(m.reviewFolder, widget.Hash+".webp")
+	if paged {
+		pageParam = &page
+		path = filepath.Join(m.reviewFolder, widget.Hash, fmt.Sprintf("page-%03d.webp", page+1))
+	}
+
+	var lastErr error
+	for level := 0; level <= info.MaxLevel; level++ {
+		data, err := m.session.GetMipmapLevel(ctx, widget.CanvasID, widget.Hash, level, pageParam)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create review folder: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return &DegradedPage{
+			Page:   page + 1,
+			Path:   path,
+			Level:  level,
+			Width:  info.Resolution.Width >> level,
+			Height: info.Resolution.Height >> level,
+			Bytes:  int64(len(data)),
+		}, nil
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("server reports no mipmap levels (max_level %d)", info.MaxLevel)
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,90 @@
+package canvus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	canvussdk "canvus-go-api/canvus"
+)
+
+func TestMipmapRebuilderSavesTopLevelOfEveryPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		switch r.URL.Path {
+		case "/api/v1/mipmaps/imagehash":
+			json.NewEncoder(w).Encode(map[string]interface{}{"resolution": map[string]int{"width": 4000, "height": 3000}, "max_level": 4, "pages": 1})
+		case "/api/v1/mipmaps/imagehash/0":
+			http.NotFound(w, r) // Level 0 was never generated
+		case "/api/v1/mipmaps/imagehash/1":
+			w.Write([]byte("webp image level 1"))
+		case "/api/v1/mipmaps/pdfhash":
+			json.NewEncoder(w).Encode(map[string]interface{}{"resolution": map[string]int{"width": 1240, "height": 1754}, "max_level": 2, "pages": 3})
+		case "/api/v1/mipmaps/pdfhash/0":
+			if page == "2" {
+				http.NotFound(w, r) // The server lost the last page
+				return
+			}
+			w.Write([]byte("webp page " + page))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	review := t.TempDir()
+	rebuilder := NewMipmapRebuilder(canvussdk.NewSession(server.URL+"/api/v1"), review, 2)
+	result, err := rebuilder.Rebuild(context.Background(), []AssetInfo{
+		{Hash: "imagehash", WidgetType: "Image", CanvasID: "c1"},
+		{Hash: "pdfhash", WidgetType: "Pdf", CanvasID: "c1"},
+		{Hash: "videohash", WidgetType: "Video", CanvasID: "c1"},
+		{Hash: "gonehash", WidgetType: "Image", CanvasID: "c1"},
+	}, []string{"imagehash", "pdfhash", "videohash", "gonehash"})
+	if err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	image := result.Assets["imagehash"]
+	if image == nil || !image.Lossy || len(image.Pages) != 1 || image.Pages[0].Level != 1 || image.Pages[0].Width != 2000 {
+		t.Fatalf("expected the image from level 1, got %+v", image)
+	}
+	if data, err := os.ReadFile(filepath.Join(review, "imagehash.webp")); err != nil || string(data) != "webp image level 1" {
+		t.Errorf("imagehash.webp: got %q, %v", data, err)
+	}
+
+	pdf := result.Assets["pdfhash"]
+	if pdf == nil || pdf.PageCount != 3 || len(pdf.Pages) != 2 || pdf.Complete() {
+		t.Fatalf("expected 2 of 3 PDF pages, got %+v", pdf)
+	}
+	if data, err := os.ReadFile(filepath.Join(review, "pdfhash", "page-002.webp")); err != nil || string(data) != "webp page 1" {
+		t.Errorf("page-002.webp: got %q, %v", data, err)
+	}
+
+	if _, found := result.Failed["gonehash"]; !found || len(result.Failed) != 1 {
+		t.Errorf("expected only gonehash to fail (videos have no mipmaps), got %v", result.Failed)
+	}
+}
+
+func TestMipmapRebuilderFailsWithoutMipmapLevels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/mipmaps/imagehash" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"resolution": map[string]int{"width": 4000, "height": 3000}, "max_level": -1, "pages": 1})
+	}))
+	defer server.Close()
+
+	rebuilder := NewMipmapRebuilder(canvussdk.NewSession(server.URL+"/api/v1"), t.TempDir(), 1)
+	result, err := rebuilder.Rebuild(context.Background(), []AssetInfo{{Hash: "imagehash", WidgetType: "Image", CanvasID: "c1"}}, []string{"imagehash"})
+	if err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if _, found := result.Failed["imagehash"]; !found || len(result.Assets) != 0 {
+		t.Errorf("expected imagehash to fail, got %+v", result)
+	}
+}
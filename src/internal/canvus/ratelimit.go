@@ -0,0 +1,247 @@
+package canvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	canvussdk "canvus-go-api/canvus"
+)
+
+// ErrLimiterStopped is returned by Wait once the limiter has been stopped
+var ErrLimiterStopped = errors.New("rate limiter stopped")
+
+// The limiter is installed on SDK sessions with canvussdk.WithRequestLimiter
+var _ canvussdk.RequestLimiter = (*AdaptiveRateLimiter)(nil)
+
+const (
+	latencySpikeFactor = 4               // A response this many times slower than the baseline is a spike
+	minSpikeLatency    = time.Second     // Responses faster than this never count as a spike
+	latencyBaselineEWM = 0.1             // Weight of a new sample in the latency baseline
+	defaultCooldown    = 5 * time.Second // Minimum time between two rate decreases
+	defaultRecovery    = 30 * time.Second
+)
+
+// RateLimitConfig controls the adaptive rate limiter
+type RateLimitConfig struct {
+	InitialRate      float64       // Requests per second to start with, and the ceiling when recovering
+	MinRate          float64       // Floor the rate backs off to
+	Cooldown         time.Duration // Minimum time between two decreases, so one burst of errors counts once
+	RecoveryInterval time.Duration // Trouble-free time before the rate steps back up
+}
+
+// RateChange records one adjustment of the request rate
+type RateChange struct {
+	Time   time.Time `json:"time"`
+	From   float64   `json:"from"`
+	To     float64   `json:"to"`
+	Reason string    `json:"reason"`
+}
+
+// RateLimitStats summarises how the limiter behaved during a run
+type RateLimitStats struct {
+	InitialRate   float64      `json:"initial_rate"`
+	FinalRate     float64      `json:"final_rate"`
+	LowestRate    float64      `json:"lowest_rate"`
+	Requests      int          `json:"requests"`
+	Throttled     int          `json:"throttled"`      // 429 responses
+	ServerErrors  int          `json:"server_errors"`  // 5xx responses and connection failures
+	LatencySpikes int          `json:"latency_spikes"` // Responses far slower than the baseline
+	Changes       []RateChange `json:"changes"`
+}
+
+// AdaptiveRateLimiter is a token bucket whose rate halves on 429s, 5xx responses and latency
+// spikes (down to MinRate) and doubles again after a trouble-free RecoveryInterval.
+// With the defaults this gives the 100/sec -> 50/sec -> 25/sec steps from the PRD.
+// Install it on a session with canvussdk.WithRequestLimiter so every request is paced and observed.
+type AdaptiveRateLimiter struct {
+	config RateLimitConfig
+
+	mu          sync.Mutex
+	rate        float64
+	tokens      float64
+	lastRefill  time.Time
+	lastChange  time.Time
+	lastTrouble time.Time
+	baseline    time.Duration
+	stats       RateLimitStats
+
+	stopped  chan struct{}
+	stopOnce sync.Once
+	now      func() time.Time
+}
+
+// NewAdaptiveRateLimiter creates a limiter running at the initial rate
+func NewAdaptiveRateLimiter(cfg RateLimitConfig) *AdaptiveRateLimiter {
+	if cfg.InitialRate <= 0 {
+		cfg.InitialRate = 100
+	}
+	if cfg.MinRate <= 0 || cfg.MinRate > cfg.InitialRate {
+		cfg.MinRate = cfg.InitialRate
+	}
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = defaultCooldown
+	}
+	if cfg.RecoveryInterval == 0 {
+		cfg.RecoveryInterval = defaultRecovery
+	}
+
+	l := &AdaptiveRateLimiter{
+		config:  cfg,
+		rate:    cfg.InitialRate,
+		stopped: make(chan struct{}),
+		now:     time.Now,
+		stats: RateLimitStats{
+			InitialRate: cfg.InitialRate,
+			LowestRate:  cfg.InitialRate,
+			Changes:     make([]RateChange, 0),
+		},
+	}
+	l.lastRefill = l.now()
+	l.tokens = l.burst()
+	return l
+}
+
+// burst is the bucket capacity: a tenth of a second's worth of requests
+func (l *AdaptiveRateLimiter) burst() float64 {
+	if b := l.rate / 10; b > 1 {
+		return b
+	}
+	return 1
+}
+
+// Wait blocks until a request may be sent, the context is cancelled or the limiter is stopped
+func (l *AdaptiveRateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		l.tokens += now.Sub(l.lastRefill).Seconds() * l.rate
+		if capacity := l.burst(); l.tokens > capacity {
+			l.tokens = capacity
+		}
+		l.lastRefill = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-l.stopped:
+			timer.Stop()
+			return ErrLimiterStopped
+		}
+	}
+}
+
+// Observe feeds the outcome of a request back into the limiter.
+// statusCode is 0 when no response was received (err is then the transport error).
+func (l *AdaptiveRateLimiter) Observe(statusCode int, latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.stats.Requests++
+
+	reason := ""
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		l.stats.Throttled++
+		reason = "429 Too Many Requests"
+	case statusCode >= 500:
+		l.stats.ServerErrors++
+		reason = fmt.Sprintf("server error %d", statusCode)
+	case err != nil:
+		l.stats.ServerErrors++
+		reason = "connection error"
+	case l.baseline > 0 && latency > minSpikeLatency && latency > l.baseline*latencySpikeFactor:
+		l.stats.LatencySpikes++
+		reason = fmt.Sprintf("latency spike %v (baseline %v)", latency.Round(time.Millisecond), l.baseline.Round(time.Millisecond))
+	}
+
+	if reason != "" {
+		l.lastTrouble = now
+		if l.rate > l.config.MinRate && now.Sub(l.lastChange) >= l.config.Cooldown {
+			l.setRate(now, l.rate/2, reason)
+		}
+		return
+	}
+
+	// Only healthy responses move the latency baseline
+	if l.baseline == 0 {
+		l.baseline = latency
+	} else {
+		l.baseline += time.Duration(latencyBaselineEWM * float64(latency-l.baseline))
+	}
+
+	if l.rate < l.config.InitialRate &&
+		now.Sub(l.lastTrouble) >= l.config.RecoveryInterval &&
+		now.Sub(l.lastChange) >= l.config.RecoveryInterval {
+		l.setRate(now, l.rate*2, "stable responses")
+	}
+}
+
+// setRate changes the rate within [MinRate, InitialRate] and records the change; l.mu must be held
+func (l *AdaptiveRateLimiter) setRate(now time.Time, rate float64, reason string) {
+	if rate < l.config.MinRate {
+		rate = l.config.MinRate
+	}
+	if rate > l.config.InitialRate {
+		rate = l.config.InitialRate
+	}
+	if rate == l.rate {
+		return
+	}
+
+	logger := logging.GetLogger()
+	if rate < l.rate {
+		logger.Warn("🐢 API rate lowered from %.0f to %.0f requests/sec (%s)", l.rate, rate, reason)
+	} else {
+		logger.Info("🐇 API rate raised from %.0f to %.0f requests/sec (%s)", l.rate, rate, reason)
+	}
+
+	l.stats.Changes = append(l.stats.Changes, RateChange{Time: now, From: l.rate, To: rate, Reason: reason})
+	if rate < l.stats.LowestRate {
+		l.stats.LowestRate = rate
+	}
+	l.rate = rate
+	l.lastChange = now
+	if capacity := l.burst(); l.tokens > capacity {
+		l.tokens = capacity
+	}
+}
+
+// Rate returns the current request rate in requests per second
+func (l *AdaptiveRateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Stats returns a snapshot of the limiter's behaviour so far
+func (l *AdaptiveRateLimiter) Stats() *RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.FinalRate = l.rate
+	stats.Changes = append([]RateChange(nil), l.stats.Changes...)
+	return &stats
+}
+
+// Stop releases every waiter with ErrLimiterStopped; it is safe to call more than once
+func (l *AdaptiveRateLimiter) Stop() {
+	l.stopOnce.Do(func() { close(l.stopped) })
+}
@@ -0,0 +1,108 @@
+package canvus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClock lets a test move the limiter's notion of time
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(clock *fakeClock) *AdaptiveRateLimiter {
+	l := NewAdaptiveRateLimiter(RateLimitConfig{
+		InitialRate:      100,
+		MinRate:          25,
+		Cooldown:         5 * time.Second,
+		RecoveryInterval: 30 * time.Second,
+	})
+	l.now = clock.now
+	return l
+}
+
+func TestAdaptiveRateLimiterBacksOffAndRecovers(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	l := newTestLimiter(clock)
+
+	clock.advance(10 * time.Second)
+	l.Observe(http.StatusTooManyRequests, 10*time.Millisecond, nil)
+	// A second error inside the cooldown belongs to the same burst
+	l.Observe(http.StatusServiceUnavailable, 10*time.Millisecond, nil)
+	if rate := l.Rate(); rate != 50 {
+		t.Fatalf("expected 50/sec after the first burst, got %.0f", rate)
+	}
+
+	clock.advance(6 * time.Second)
+	l.Observe(0, 0, errors.New("connection reset"))
+	clock.advance(6 * time.Second)
+	l.Observe(http.StatusBadGateway, 10*time.Millisecond, nil)
+	if rate := l.Rate(); rate != 25 {
+		t.Fatalf("expected the rate to stop at the 25/sec floor, got %.0f", rate)
+	}
+
+	// Healthy responses before the recovery interval keep the rate down
+	clock.advance(10 * time.Second)
+	l.Observe(http.StatusOK, 10*time.Millisecond, nil)
+	if rate := l.Rate(); rate != 25 {
+		t.Fatalf("expected no recovery yet, got %.0f", rate)
+	}
+
+	clock.advance(30 * time.Second)
+	l.Observe(http.StatusOK, 10*time.Millisecond, nil)
+	clock.advance(30 * time.Second)
+	l.Observe(http.StatusOK, 10*time.Millisecond, nil)
+	clock.advance(30 * time.Second)
+	l.Observe(http.StatusOK, 10*time.Millisecond, nil)
+	if rate := l.Rate(); rate != 100 {
+		t.Fatalf("expected a step-by-step recovery to 100/sec, got %.0f", rate)
+	}
+
+	stats := l.Stats()
+	if stats.LowestRate != 25 || stats.FinalRate != 100 || len(stats.Changes) != 4 {
+		t.Errorf("unexpected stats: lowest=%.0f final=%.0f changes=%d", stats.LowestRate, stats.FinalRate, len(stats.Changes))
+	}
+	if stats.Throttled != 1 || stats.ServerErrors != 3 {
+		t.Errorf("unexpected counts: throttled=%d server errors=%d", stats.Throttled, stats.ServerErrors)
+	}
+}
+
+func TestAdaptiveRateLimiterLatencySpike(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	l := newTestLimiter(clock)
+
+	clock.advance(10 * time.Second)
+	for i := 0; i < 20; i++ {
+		l.Observe(http.StatusOK, 300*time.Millisecond, nil)
+	}
+	l.Observe(http.StatusOK, 5*time.Second, nil)
+
+	if rate := l.Rate(); rate != 50 {
+		t.Errorf("expected a latency spike to halve the rate, got %.0f", rate)
+	}
+	if spikes := l.Stats().LatencySpikes; spikes != 1 {
+		t.Errorf("expected 1 latency spike, got %d", spikes)
+	}
+}
+
+func TestAdaptiveRateLimiterStop(t *testing.T) {
+	l := NewAdaptiveRateLimiter(RateLimitConfig{InitialRate: 1, MinRate: 1})
+	l.Wait(context.Background()) // Use up the only token
+
+	done := make(chan error)
+	go func() { done <- l.Wait(context.Background()) }()
+
+	l.Stop()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrLimiterStopped) {
+			t.Errorf("expected ErrLimiterStopped, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Wait did not return after Stop")
+	}
+}
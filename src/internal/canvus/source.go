@@ -0,0 +1,211 @@
+package canvus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	canvussdk "canvus-go-api/canvus"
+)
+
+// Recovery source names recorded in BackupFile.Source
+const (
+	SourceCanvusAPI = "canvus-api" // The Canvus Server being repaired
+	SourceReplica   = "replica"    // A secondary Canvus Server, e.g. staging restored from an older snapshot
+)
+
+// extensionsByContentType names downloaded files whose original filename has no extension
+var extensionsByContentType = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"video/mp4":       ".mp4",
+	"video/quicktime": ".mov",
+}
+
+// APISource recovers assets that a Canvus Server still serves although their file is missing
+// from the assets folder. The server only serves an asset in the context of a canvas that uses it,
+// so the source is built from the discovered assets.
+type APISource struct {
+	session       *canvussdk.Session
+	name          string
+	label         string // Server shown in reports
+	priority      int
+	maxConcurrent int
+	assets        map[string][]AssetInfo // Hash -> widgets using it
+	algorithm     *digest.Algorithm      // Digest downloads are checked against when restored (nil = unknown)
+	logger        *logging.Logger
+}
+
+var _ backup.RecoverySource = (*APISource)(nil)
+
+// NewAPISource creates a recovery source that downloads assets from the server of session, e.g.
+// SourceCanvusAPI for the server being repaired or SourceReplica for a secondary one
+func NewAPISource(session *canvussdk.Session, name, label string, priority int, assets []AssetInfo, maxConcurrent int) *APISource {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	byHash := make(map[string][]AssetInfo)
+	for _, asset := range assets {
+		if asset.Hash != "" && asset.CanvasID != "" {
+			byHash[asset.Hash] = append(byHash[asset.Hash], asset)
+		}
+	}
+
+	return &APISource{
+		session:       session,
+		name:          name,
+		label:         label,
+		priority:      priority,
+		maxConcurrent: maxConcurrent,
+		assets:        byHash,
+		logger:        logging.GetLogger(),
+	}
+}
+
+// Name returns the source name
+func (a *APISource) Name() string {
+	return a.name
+}
+
+// Priority returns the source priority
+func (a *APISource) Priority() int {
+	return a.priority
+}
+
+// SetHashAlgorithm sets the digest of asset file names, which restored downloads are checked against
+func (a *APISource) SetHashAlgorithm(algorithm *digest.Algorithm) {
+	a.algorithm = algorithm
+}
+
+// FindCandidates checks which hashes the server serves, without downloading them. Served content
+// is checked against its hash when it is restored, so the result counts as verified only if the
+// hash algorithm is known.
+func (a *APISource) FindCandidates(ctx context.Context, hashes []string) (*backup.SearchResult, error) {
+	result := &backup.SearchResult{
+		FoundFiles:    make(map[string][]backup.BackupFile),
+		MissingHashes: make([]string, 0),
+		TotalSearched: 1,
+		Verified:      a.algorithm != nil,
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < a.maxConcurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hash := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain the queue without probing
+				}
+				candidate, found := a.locate(ctx, hash)
+
+				mu.Lock()
+				if found {
+					result.FoundFiles[hash] = []backup.BackupFile{candidate}
+					result.TotalFiles++
+				} else if ctx.Err() == nil {
+					result.MissingHashes = append(result.MissingHashes, hash)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, hash := range hashes {
+		select {
+		case jobs <- hash:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("server lookup interrupted: %w", err)
+	}
+
+	sort.Strings(result.MissingHashes)
+	a.logger.Info("🌐 %s serves %d of %d missing assets", a.label, len(result.FoundFiles), len(hashes))
+	return result, nil
+}
+
+// locate finds a canvas through which the server serves an asset
+func (a *APISource) locate(ctx context.Context, hash string) (backup.BackupFile, bool) {
+	for _, asset := range a.assets[hash] {
+		stat, err := a.session.StatAssetByHash(ctx, asset.CanvasID, hash)
+		if err != nil {
+			a.logger.Verbose("%s does not serve %s for canvas %s: %v", a.label, hash, asset.CanvasID, err)
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(asset.OriginalFilename))
+		if ext == "" {
+			ext = extensionsByContentType[strings.TrimSpace(strings.Split(stat.ContentType, ";")[0])]
+		}
+
+		candidate := backup.BackupFile{
+			Path:         fmt.Sprintf("%s/assets/%s", strings.TrimSuffix(a.label, "/"), hash),
+			Hash:         hash,
+			Extension:    ext,
+			RelativePath: hash + ext,
+			Root:         a.label,
+			Source:       a.name,
+			CanvasID:     asset.CanvasID,
+		}
+		if stat.Size > 0 {
+			candidate.Size = stat.Size
+		}
+		return candidate, true
+	}
+	return backup.BackupFile{}, false
+}
+
+// Open streams a served asset from the server, so even multi-GB videos are never held in memory
+func (a *APISource) Open(ctx context.Context, file backup.BackupFile) (io.ReadCloser, error) {
+	body, _, err := a.session.OpenAssetByHash(ctx, file.CanvasID, file.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s from %s: %w", file.Hash, a.label, err)
+	}
+	return body, nil
+}
+
+// MapAssetsToCanvases rewrites the canvas IDs of assets for another server holding copies of the
+// same canvases. A canvas keeps its ID when the other server was restored from a snapshot of this one;
+// otherwise canvases with the same name are tried. Assets whose canvas is on neither are kept as is.
+func MapAssetsToCanvases(assets []AssetInfo, canvases []canvussdk.Canvas) []AssetInfo {
+	ids := make(map[string]bool, len(canvases))
+	byName := make(map[string][]string)
+	for _, canvas := range canvases {
+		ids[canvas.ID] = true
+		byName[canvas.Name] = append(byName[canvas.Name], canvas.ID)
+	}
+
+	mapped := make([]AssetInfo, 0, len(assets))
+	for _, asset := range assets {
+		if ids[asset.CanvasID] || len(byName[asset.CanvasName]) == 0 {
+			mapped = append(mapped, asset)
+			continue
+		}
+		for _, id := range byName[asset.CanvasName] {
+			moved := asset
+			moved.CanvasID = id
+			mapped = append(mapped, moved)
+		}
+	}
+	return mapped
+}
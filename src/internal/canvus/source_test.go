@@ -0,0 +1,108 @@
+package canvus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+	canvussdk "canvus-go-api/canvus"
+)
+
+func TestAPISourceLocatesAndDownloadsServedAssets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only serves an asset through a canvas that still uses it
+		if r.URL.Path != "/api/v1/assets/pdfhash" || r.Header.Get("canvas-id") != "c2" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7 document"))
+	}))
+	defer server.Close()
+
+	session := canvussdk.NewSession(server.URL + "/api/v1")
+	source := NewAPISource(session, SourceCanvusAPI, server.URL, 3, []AssetInfo{
+		{Hash: "pdfhash", WidgetType: "Pdf", CanvasID: "c1"},
+		{Hash: "pdfhash", WidgetType: "Pdf", CanvasID: "c2", OriginalFilename: "report"},
+		{Hash: "gonehash", WidgetType: "Image", CanvasID: "c1", OriginalFilename: "photo.JPG"},
+	}, 2)
+
+	result, err := source.FindCandidates(context.Background(), []string{"pdfhash", "gonehash"})
+	if err != nil {
+		t.Fatalf("FindCandidates: %v", err)
+	}
+	if !reflect.DeepEqual(result.MissingHashes, []string{"gonehash"}) {
+		t.Errorf("expected only gonehash missing, got %v", result.MissingHashes)
+	}
+	// Nothing will check the downloads without a known hash algorithm
+	if result.Verified {
+		t.Error("expected the result not to count as verified without a hash algorithm")
+	}
+
+	files := result.FoundFiles["pdfhash"]
+	if len(files) != 1 {
+		t.Fatalf("expected one candidate for pdfhash, got %+v", files)
+	}
+	candidate := files[0]
+	if candidate.CanvasID != "c2" || candidate.RelativePath != "pdfhash.pdf" || candidate.Source != SourceCanvusAPI {
+		t.Errorf("unexpected candidate %+v", candidate)
+	}
+
+	reader, err := source.Open(context.Background(), candidate)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer reader.Close()
+	if data, _ := io.ReadAll(reader); string(data) != "%PDF-1.7 document" {
+		t.Errorf("unexpected content %q", data)
+	}
+}
+
+func TestMapAssetsToCanvases(t *testing.T) {
+	assets := []AssetInfo{
+		{Hash: "a", CanvasID: "same-id", CanvasName: "Board"},
+		{Hash: "b", CanvasID: "prod-id", CanvasName: "Workshop"},
+		{Hash: "c", CanvasID: "gone-id", CanvasName: "Deleted"},
+	}
+	canvases := []canvussdk.Canvas{
+		{ID: "same-id", Name: "Board"},
+		{ID: "staging-1", Name: "Workshop"},
+		{ID: "staging-2", Name: "Workshop"},
+	}
+
+	var got []string
+	for _, asset := range MapAssetsToCanvases(assets, canvases) {
+		got = append(got, asset.Hash+"@"+asset.CanvasID)
+	}
+	want := []string{"a@same-id", "b@staging-1", "b@staging-2", "c@gone-id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAPISourceIsVerifiedWithHashAlgorithm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	algorithm, err := digest.Parse("sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := NewAPISource(canvussdk.NewSession(server.URL+"/api/v1"), SourceCanvusAPI, server.URL, 0,
+		[]AssetInfo{{Hash: "hash", CanvasID: "c1"}}, 1)
+	source.SetHashAlgorithm(&algorithm)
+
+	result, err := source.FindCandidates(context.Background(), []string{"hash"})
+	if err != nil {
+		t.Fatalf("FindCandidates: %v", err)
+	}
+	if !result.Verified || len(result.FoundFiles["hash"]) != 1 {
+		t.Errorf("expected a verified result with one candidate, got %+v", result)
+	}
+}
@@ -11,57 +11,77 @@ import (
 	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
 	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
 	"github.com/jaypaulb/kpmg-db-solver/internal/config"
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
 	"github.com/jaypaulb/kpmg-db-solver/internal/filesystem"
 	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/report"
 	canvussdk "canvus-go-api/canvus"
 )
 
+// DiscoverOptions controls how the discover and run commands crawl the server
+type DiscoverOptions struct {
+	Resume         bool     // Skip canvases the discovery journal records as done; retry failed ones first
+	Full           bool     // Read every canvas, ignoring the asset cache of unchanged canvases
+	BackupSince    string   // Only search backups taken on or after this date (overrides backup.since)
+	BackupUntil    string   // Only search backups taken on or before this date (overrides backup.until)
+	BackupVersions []string // Only search backups from these Canvus versions (overrides backup.versions)
+	NoVerify       bool     // Recommend backup candidates without checking them for corruption
+	VerifyAssets   bool     // Hash every existing asset to find corrupt files (overrides assets.verify_content)
+}
+
 // DiscoverCommand handles the discover command
 type DiscoverCommand struct {
-	config *config.Config
+	config      *config.Config
+	options     DiscoverOptions
+	journal     *canvus.Journal
+	rateLimiter *canvus.AdaptiveRateLimiter
+	algorithm   *digest.Algorithm // Digest used for asset file names; nil until known
 }
 
 // NewDiscoverCommand creates a new discover command
-func NewDiscoverCommand(cfg *config.Config) *DiscoverCommand {
+func NewDiscoverCommand(cfg *config.Config, opts DiscoverOptions) *DiscoverCommand {
 	return &DiscoverCommand{
-		config: cfg,
+		config:  cfg,
+		options: opts,
 	}
 }
 
-// Execute runs the discover command
-func (cmd *DiscoverCommand) Execute() error {
+// Execute runs the discover command.
+// Cancelling ctx (Ctrl+C) stops the run and saves a checkpoint manifest with the partial results.
+func (cmd *DiscoverCommand) Execute(ctx context.Context) error {
 	logger := logging.GetLogger()
 
 	logger.Info("🔍 Starting asset discovery...")
 	logger.Info("📡 Connecting to Canvus Server: %s", cmd.config.CanvusServer.URL)
 	logger.Info("📁 Scanning assets folder: %s", cmd.config.Paths.AssetsFolder)
 
-	// Create Canvus session using existing SDK
-	ctx := context.Background()
-	var session *canvussdk.Session
-	if cmd.config.CanvusServer.InsecureTLS {
-		session = canvussdk.NewSession(cmd.config.GetCanvusAPIURL(), canvussdk.WithInsecureTLS())
-	} else {
-		session = canvussdk.NewSession(cmd.config.GetCanvusAPIURL())
+	// Check the backup settings before the long discovery
+	searcher, err := cmd.newSearcher()
+	if err != nil {
+		return fmt.Errorf("invalid backup settings: %w", err)
 	}
 
+	// Create Canvus session using existing SDK
+	session := cmd.newSession()
+	defer cmd.rateLimiter.Stop()
+
 	// Authenticate using existing SDK
 	logger.Info("🔐 Authenticating with Canvus Server...")
-	err := session.Login(ctx, cmd.config.CanvusServer.Username, cmd.config.CanvusServer.Password)
+	err = session.Login(ctx, cmd.config.CanvusServer.Username, cmd.config.CanvusServer.Password)
 	if err != nil {
 		logger.Error("Authentication failed: %v", err)
 		return fmt.Errorf("authentication failed: %w", err)
 	}
-	defer session.Logout(ctx)
+	defer logout(session)
 
 	logger.Info("✅ Successfully authenticated with Canvus Server")
 
 	// Discover assets from API
 	logger.Info("📊 Discovering assets from Canvus API...")
-	discoveryResult, err := canvus.DiscoverAllAssets(session, cmd.config.Performance.MaxConcurrentAPI)
+	discoveryResult, err := cmd.discoverAssets(ctx, session)
 	if err != nil {
-		logger.Error("Asset discovery failed: %v", err)
-		return fmt.Errorf("asset discovery failed: %w", err)
+		return err
 	}
 
 	logger.Info("📈 Found %d canvases with %d total media assets",
@@ -79,7 +99,11 @@ func (cmd *DiscoverCommand) Execute() error {
 
 	// Scan filesystem
 	logger.Info("💾 Scanning assets folder...")
-	scanResult, err := filesystem.ScanAssetsFolder(cmd.config.Paths.AssetsFolder)
+	scanResult, err := filesystem.ScanAssetsFolder(ctx, cmd.config.Paths.AssetsFolder)
+	if ctx.Err() != nil {
+		cmd.saveCheckpoint(manifest.StageScan, discoveryResult, nil, nil)
+		return fmt.Errorf("filesystem scan interrupted: %w", err)
+	}
 	if err != nil {
 		logger.Error("Filesystem scan failed: %v", err)
 		return fmt.Errorf("filesystem scan failed: %w", err)
@@ -88,36 +112,50 @@ func (cmd *DiscoverCommand) Execute() error {
 	logger.Info("📂 Found %d files in assets folder (%.2f MB total)",
 		len(scanResult.Files), float64(scanResult.TotalSize)/(1024*1024))
 
+	// Work out the asset hash algorithm and check existing files against it
+	if err := cmd.checkContent(ctx, scanResult); err != nil {
+		if ctx.Err() != nil {
+			cmd.saveCheckpoint(manifest.StageScan, discoveryResult, nil, nil)
+		}
+		return err
+	}
+
 	// Find missing assets
 	missingAssets := filesystem.FindMissingAssets(assetHashes, scanResult)
 	logger.Info("❌ Missing assets: %d", len(missingAssets))
 
-	// Search for missing assets in backup folders
+	// Ask the recovery sources for the missing assets
 	var backupSearchResult *backup.SearchResult
 	if len(missingAssets) > 0 {
-		logger.Info("🔍 Searching for missing assets in backup folder...")
-		searcher := backup.NewSearcher(cmd.config.Paths.BackupRootFolder)
-		backupSearchResult, err = searcher.SearchForAssets(missingAssets)
+		logger.Info("🔍 Searching recovery sources for missing assets...")
+		backupSearchResult, err = cmd.findCandidates(ctx, searcher, session, discoveryResult, missingAssets)
+		if ctx.Err() != nil {
+			cmd.saveCheckpoint(manifest.StageBackupSearch, discoveryResult, scanResult, missingAssets)
+			return err
+		}
 		if err != nil {
 			logger.Error("Backup search failed: %v", err)
 			return fmt.Errorf("backup search failed: %w", err)
 		}
 
-		// Sort backup files by modification time (newest first)
-		searcher.SortBackupFiles(backupSearchResult)
-
-		// Report found assets (restoration disabled in non-admin version)
+		// Report found assets (restoration is a separate step)
 		if len(backupSearchResult.FoundFiles) > 0 {
-			logger.Info("💾 Found %d missing assets in backup folder", len(backupSearchResult.FoundFiles))
+			logger.Info("💾 Found %d missing assets in recovery sources", len(backupSearchResult.FoundFiles))
 			logger.Info("📋 Asset locations will be included in the detailed report")
-			logger.Info("⚠️  Note: Asset restoration requires administrator privileges and is disabled in this version")
+			logger.Info("⚠️  Note: Run the restore command (requires write access to the assets folder) to copy them back")
 		}
 	}
 
+	// Persist the run so report and restore can work without querying the server again
+	cmd.saveManifest(discoveryResult, scanResult, missingAssets, backupSearchResult)
+
+	// Classify every asset by filesystem and server state
+	classification := report.ClassifyAssets(discoveryResult.Assets, scanResult, discoveryResult.ServerValidation)
+
 	// Generate reports
-	if len(missingAssets) > 0 {
+	if len(missingAssets) > 0 || classification.Counts[report.ClassServerError] > 0 {
 		logger.Info("📋 Generating reports...")
-		err = cmd.generateReports(discoveryResult, missingAssets, uniqueAssets, backupSearchResult)
+		err = cmd.generateReports(discoveryResult, missingAssets, backupSearchResult, classification)
 		if err != nil {
 			logger.Error("Report generation failed: %v", err)
 			return fmt.Errorf("report generation failed: %w", err)
@@ -127,182 +165,369 @@ func (cmd *DiscoverCommand) Execute() error {
 	}
 
 	// Print summary
-	cmd.printSummary(discoveryResult, scanResult, missingAssets)
+	cmd.printSummary(discoveryResult, scanResult, missingAssets, classification)
+	cmd.finishJournal(discoveryResult)
 
 	return nil
 }
 
-// generateReports generates detailed and CSV reports
-func (cmd *DiscoverCommand) generateReports(discoveryResult *canvus.DiscoveryResult, missingAssets []string, uniqueAssets []canvus.AssetInfo, backupSearchResult *backup.SearchResult) error {
-	// Create missing assets map for quick lookup
-	missingMap := make(map[string]bool)
-	for _, hash := range missingAssets {
-		missingMap[hash] = true
-	}
+// newSession creates a session with the configured Canvus Server
+func (cmd *DiscoverCommand) newSession() *canvussdk.Session {
+	var session *canvussdk.Session
+	session, cmd.rateLimiter = newRateLimitedSession(cmd.config, cmd.config.GetCanvusAPIURL(), cmd.config.CanvusServer.InsecureTLS)
+	return session
+}
 
-	// Filter unique assets to only include missing ones
-	var missingAssetInfos []canvus.AssetInfo
-	for _, asset := range uniqueAssets {
-		if missingMap[asset.Hash] {
-			missingAssetInfos = append(missingAssetInfos, asset)
-		}
+// newRateLimitedSession creates a Canvus session whose requests are paced by an adaptive rate limiter
+// (backing off when the server struggles) and capped at MaxConcurrentAPI in flight.
+// The caller stops the returned rate limiter when done.
+func newRateLimitedSession(cfg *config.Config, apiURL string, insecureTLS bool) (*canvussdk.Session, *canvus.AdaptiveRateLimiter) {
+	rateLimiter := canvus.NewAdaptiveRateLimiter(canvus.RateLimitConfig{
+		InitialRate: float64(cfg.Performance.RequestsPerSecond),
+		MinRate:     float64(cfg.Performance.MinRequestsPerSecond),
+	})
+
+	opts := []canvussdk.SessionOption{
+		canvussdk.WithRequestLimiter(rateLimiter),
+		canvussdk.WithMaxInFlight(cfg.Performance.MaxConcurrentAPI),
+	}
+	if insecureTLS {
+		opts = append(opts, canvussdk.WithInsecureTLS())
 	}
 
-	// Generate detailed report
-	err := cmd.generateDetailedReport(missingAssetInfos, backupSearchResult)
+	return canvussdk.NewSession(apiURL, opts...), rateLimiter
+}
+
+// newSearcher creates a backup searcher over the configured backup roots, limited to the configured
+// generations (command-line filters take precedence), that keeps its per-generation index in the output folder
+func (cmd *DiscoverCommand) newSearcher() (*backup.Searcher, error) {
+	parser, err := backup.NewGenerationParser(cmd.config.Backup.FolderPatterns)
 	if err != nil {
-		return fmt.Errorf("failed to generate detailed report: %w", err)
+		return nil, err
 	}
 
-	// Generate CSV report
-	err = cmd.generateCSVReport(missingAssetInfos, backupSearchResult)
+	roots := make([]backup.BackupRoot, 0)
+	for _, rootConfig := range cmd.config.GetBackupRoots() {
+		root := backup.BackupRoot{Path: rootConfig.Path, Label: rootConfig.Label, Priority: rootConfig.Priority}
+		if len(rootConfig.FolderPatterns) > 0 {
+			if root.Parser, err = backup.NewGenerationParser(rootConfig.FolderPatterns); err != nil {
+				return nil, fmt.Errorf("backup root %q: %w", rootConfig.Label, err)
+			}
+		}
+		roots = append(roots, root)
+	}
+
+	since, until, versions := cmd.config.Backup.Since, cmd.config.Backup.Until, cmd.config.Backup.Versions
+	if cmd.options.BackupSince != "" {
+		since = cmd.options.BackupSince
+	}
+	if cmd.options.BackupUntil != "" {
+		until = cmd.options.BackupUntil
+	}
+	if len(cmd.options.BackupVersions) > 0 {
+		versions = cmd.options.BackupVersions
+	}
+	filter, err := backup.ParseGenerationFilter(since, until, versions)
 	if err != nil {
-		return fmt.Errorf("failed to generate CSV report: %w", err)
+		return nil, err
 	}
 
-	return nil
+	searcher := backup.NewSearcher(roots, backup.SearchOptions{
+		Parser:             parser,
+		Filter:             filter,
+		MaxConcurrentFiles: cmd.config.Performance.MaxConcurrentFiles,
+	})
+	searcher.SetIndex(backup.NewIndex(filepath.Join(cmd.config.Paths.OutputFolder, backup.IndexFolderName)))
+	return searcher, nil
 }
 
-// generateDetailedReport generates a detailed text report
-func (cmd *DiscoverCommand) generateDetailedReport(missingAssets []canvus.AssetInfo, backupSearchResult *backup.SearchResult) error {
-	reportPath := filepath.Join(cmd.config.Paths.OutputFolder, "missing_assets_report.txt")
+// findCandidates asks the recovery sources listed in recovery.sources, in that order, for the
+// missing assets. Backup candidates are checked for corruption unless --no-verify is given.
+func (cmd *DiscoverCommand) findCandidates(ctx context.Context, searcher *backup.Searcher, session *canvussdk.Session, discoveryResult *canvus.DiscoveryResult, missingAssets []string) (*backup.SearchResult, error) {
+	logger := logging.GetLogger()
 
-	// Group assets by canvas
-	canvasMap := make(map[string][]canvus.AssetInfo)
-	for _, asset := range missingAssets {
-		canvasMap[asset.CanvasName] = append(canvasMap[asset.CanvasName], asset)
+	var verifier *backup.Verifier
+	if cmd.options.NoVerify {
+		logger.Warn("⚠️  Backup candidates were not verified (--no-verify)")
+	} else {
+		verifier = backup.NewVerifier(backup.VerifyOptions{
+			Workers:   cmd.config.Performance.MaxConcurrentFiles,
+			Algorithm: cmd.algorithm,
+		})
 	}
 
-	// Generate report content
-	content := fmt.Sprintf("KPMG DB Solver - Missing Assets Report (Non-Admin Version)\n")
-	content += fmt.Sprintf("Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	content += fmt.Sprintf("Total Missing Assets: %d\n", len(missingAssets))
-
-	// Add backup summary
-	if backupSearchResult != nil {
-		content += fmt.Sprintf("Assets Found in Backup: %d\n", len(backupSearchResult.FoundFiles))
-		content += fmt.Sprintf("Assets Still Missing: %d\n", len(backupSearchResult.MissingHashes))
+	sources := make([]backup.RecoverySource, 0, len(cmd.config.Recovery.Sources))
+	for priority, name := range cmd.config.Recovery.Sources {
+		switch name {
+		case backup.SourceBackup:
+			sources = append(sources, backup.NewBackupSource(name, priority, searcher.WithLayout(backup.FolderLayout), verifier))
+		case backup.SourceArchive:
+			sources = append(sources, backup.NewBackupSource(name, priority, searcher.WithLayout(backup.ArchiveLayout), verifier))
+		case backup.SourceHarvest:
+			if len(cmd.config.Harvest.Directories) == 0 || cmd.algorithm == nil {
+				logger.Warn("⚠️  Skipping recovery source %s: it needs harvest.directories and a known asset hash algorithm", name)
+				continue
+			}
+			sources = append(sources, backup.NewHarvestSource(priority, backup.NewHarvester(cmd.config.Harvest.Directories, backup.HarvestOptions{
+				Algorithm:   *cmd.algorithm,
+				Workers:     cmd.config.Performance.MaxConcurrentFiles,
+				MaxFileSize: int64(cmd.config.Harvest.MaxFileSizeMB) * 1024 * 1024,
+			})))
+		case canvus.SourceCanvusAPI:
+			source := canvus.NewAPISource(session, name, cmd.config.CanvusServer.URL, priority, discoveryResult.Assets, cmd.config.Performance.MaxConcurrentAPI)
+			source.SetHashAlgorithm(cmd.algorithm)
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no usable recovery sources in recovery.sources")
 	}
-	content += fmt.Sprintf("\nNote: This is a read-only version. Asset restoration requires administrator privileges.\n\n")
 
-	for canvasName, assets := range canvasMap {
-		content += fmt.Sprintf("Canvas: %s (ID: %s)\n", canvasName, assets[0].CanvasID)
-		for _, asset := range assets {
-			content += fmt.Sprintf("  Widget: %s (ID: %s, Type: %s)\n", asset.WidgetName, asset.WidgetID, asset.WidgetType)
-			content += fmt.Sprintf("    Hash: %s\n", asset.Hash)
-			if asset.OriginalFilename != "" {
-				content += fmt.Sprintf("    Original Filename: %s\n", asset.OriginalFilename)
-			}
+	return backup.NewPlanner(sources...).FindCandidates(ctx, missingAssets)
+}
 
-			// Add backup status with enhanced information
-			if backupSearchResult != nil {
-				if backupFiles, found := backupSearchResult.FoundFiles[asset.Hash]; found && len(backupFiles) > 0 {
-					bestBackup := backupFiles[0] // Newest file
-					content += fmt.Sprintf("    Backup Status: ✅ Found in backup\n")
-					content += fmt.Sprintf("    Backup Path: %s\n", bestBackup.Path)
-					content += fmt.Sprintf("    Backup Size: %d bytes (%.2f MB)\n", bestBackup.Size, float64(bestBackup.Size)/(1024*1024))
-					content += fmt.Sprintf("    Backup Modified: %s\n", bestBackup.ModifiedTime.Format("2006-01-02 15:04:05"))
-					content += fmt.Sprintf("    Backup Count: %d versions found\n", len(backupFiles))
-					if len(backupFiles) > 1 {
-						content += fmt.Sprintf("    All Backup Locations:\n")
-						for i, backupFile := range backupFiles {
-							content += fmt.Sprintf("      %d. %s (Modified: %s, Size: %d bytes)\n",
-								i+1, backupFile.Path,
-								backupFile.ModifiedTime.Format("2006-01-02 15:04:05"),
-								backupFile.Size)
-						}
-					}
-				} else {
-					content += fmt.Sprintf("    Backup Status: ❌ Not found in any backup\n")
-					content += fmt.Sprintf("    Action Required: Manual investigation needed\n")
-				}
-			}
-			content += "\n"
+// checkContent works out the digest used for asset file names, from the config or by sampling the
+// scanned files, and if requested hashes every existing asset to find files that do not match their name
+func (cmd *DiscoverCommand) checkContent(ctx context.Context, scanResult *filesystem.ScanResult) error {
+	logger := logging.GetLogger()
+
+	if name := cmd.config.Assets.HashAlgorithm; name != "" {
+		algorithm, err := digest.Parse(name)
+		if err != nil {
+			return fmt.Errorf("invalid assets hash algorithm: %w", err)
 		}
+		cmd.algorithm = &algorithm
+		logger.Info("🔑 Asset hash algorithm: %s (configured)", algorithm)
+	} else {
+		detection, err := filesystem.DetectHashAlgorithm(ctx, scanResult.Files, filesystem.DefaultHashSampleSize)
+		if err != nil {
+			return err
+		}
+		if detection.Algorithm == nil {
+			logger.Warn("⚠️  Could not detect the asset hash algorithm from %d sampled files - file contents will not be checked against their hashes", detection.Sampled)
+			return nil
+		}
+		cmd.algorithm = detection.Algorithm
+		logger.Info("🔑 Asset hash algorithm: %s (detected, %d of %d sampled files match)", detection.Algorithm, detection.Matched, detection.Sampled)
+	}
+	scanResult.HashAlgorithm = cmd.algorithm.String()
+
+	if !cmd.options.VerifyAssets && !cmd.config.Assets.VerifyContent {
+		return nil
 	}
 
-	// Write report to file
-	err := writeFile(reportPath, content)
+	logger.Info("🔬 Verifying the content of %d existing assets...", len(scanResult.Files))
+	err := filesystem.VerifyContent(ctx, scanResult, *cmd.algorithm, cmd.config.Performance.MaxConcurrentFiles)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("📄 Detailed report saved to: %s\n", reportPath)
+	if len(scanResult.ContentMismatches) > 0 {
+		logger.Warn("⚠️  %d existing assets do not match their hash and may be corrupt", len(scanResult.ContentMismatches))
+		for _, hash := range scanResult.ContentMismatches {
+			logger.Verbose("Content does not match hash: %s", scanResult.HashMap[hash].Path)
+		}
+	} else {
+		logger.Info("✅ All %d existing assets match their hash", len(scanResult.Files))
+	}
 	return nil
 }
 
-// generateCSVReport generates a CSV report
-func (cmd *DiscoverCommand) generateCSVReport(missingAssets []canvus.AssetInfo, backupSearchResult *backup.SearchResult) error {
-	reportPath := filepath.Join(cmd.config.Paths.OutputFolder, "missing_assets.csv")
-
-	// Generate CSV content with enhanced backup information
-	content := "Hash,WidgetType,OriginalFilename,CanvasID,CanvasName,WidgetID,WidgetName,BackupStatus,BackupPath,BackupSize,BackupModified,BackupCount,AllBackupPaths\n"
-
-	for _, asset := range missingAssets {
-		backupStatus := "Not Found"
-		backupPath := ""
-		backupSize := ""
-		backupModified := ""
-		backupCount := "0"
-		allBackupPaths := ""
-
-		if backupSearchResult != nil {
-			if backupFiles, found := backupSearchResult.FoundFiles[asset.Hash]; found && len(backupFiles) > 0 {
-				bestBackup := backupFiles[0] // Newest file
-				backupStatus = "Found"
-				backupPath = bestBackup.Path
-				backupSize = fmt.Sprintf("%d", bestBackup.Size)
-				backupModified = bestBackup.ModifiedTime.Format("2006-01-02 15:04:05")
-				backupCount = fmt.Sprintf("%d", len(backupFiles))
-
-				// Create semicolon-separated list of all backup paths
-				allPaths := make([]string, len(backupFiles))
-				for i, backupFile := range backupFiles {
-					allPaths[i] = backupFile.Path
-				}
-				allBackupPaths = strings.Join(allPaths, ";")
-			}
+// discoverAssets crawls every canvas, recording per-canvas progress in the journal in the
+// output folder. An interrupted crawl is saved as a checkpoint before the error is returned.
+func (cmd *DiscoverCommand) discoverAssets(ctx context.Context, session *canvussdk.Session) (*canvus.DiscoveryResult, error) {
+	logger := logging.GetLogger()
+
+	journalPath := filepath.Join(cmd.config.Paths.OutputFolder, canvus.JournalFilename)
+	journal, err := canvus.OpenJournal(journalPath, cmd.options.Resume)
+	if err != nil {
+		logger.Warn("Discovery journal unavailable, progress will not be resumable: %v", err)
+	} else {
+		cmd.journal = journal
+		defer journal.Close()
+	}
+
+	cache := cmd.openAssetCache()
+
+	discoveryResult, err := canvus.DiscoverAllAssets(ctx, session, canvus.DiscoveryOptions{
+		MaxConcurrentAPI: cmd.config.Performance.MaxConcurrentAPI,
+		Journal:          cmd.journal,
+		Cache:            cache,
+	})
+	if discoveryResult != nil {
+		discoveryResult.RateLimit = cmd.rateLimiter.Stats()
+	}
+
+	// Keep whatever was read, even from an interrupted crawl
+	if cache != nil && discoveryResult != nil {
+		if err := cache.Save(); err != nil {
+			logger.Warn("Failed to save asset cache: %v", err)
 		}
+	}
+	if discoveryResult != nil && ctx.Err() != nil {
+		cmd.saveCheckpoint(manifest.StageDiscovery, discoveryResult, nil, nil)
+		logger.Info("⏩ Run again with --resume to continue from the discovery journal")
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Asset discovery failed: %v", err)
+		return nil, fmt.Errorf("asset discovery failed: %w", err)
+	}
 
-		content += fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s\n",
-			asset.Hash,
-			asset.WidgetType,
-			asset.OriginalFilename,
-			asset.CanvasID,
-			asset.CanvasName,
-			asset.WidgetID,
-			asset.WidgetName,
-			backupStatus,
-			backupPath,
-			backupSize,
-			backupModified,
-			backupCount,
-			allBackupPaths,
-		)
-	}
-
-	// Write CSV to file
-	err := writeFile(reportPath, content)
+	return discoveryResult, nil
+}
+
+// openAssetCache loads the asset cache from the output folder, or starts an empty one for a full refresh
+func (cmd *DiscoverCommand) openAssetCache() *canvus.AssetCache {
+	logger := logging.GetLogger()
+	cachePath := filepath.Join(cmd.config.Paths.OutputFolder, canvus.CacheFilename)
+
+	if cmd.options.Full {
+		logger.Info("🔄 Full refresh: reading every canvas")
+		return canvus.NewAssetCache(cachePath)
+	}
+
+	cache, err := canvus.LoadAssetCache(cachePath)
 	if err != nil {
-		return err
+		logger.Warn("Asset cache unavailable, reading every canvas: %v", err)
+		return canvus.NewAssetCache(cachePath)
 	}
 
-	fmt.Printf("📊 CSV report saved to: %s\n", reportPath)
-	return nil
+	return cache
+}
+
+// finishJournal removes the discovery journal after a complete run, or keeps it so that
+// --resume retries only the canvases that failed
+func (cmd *DiscoverCommand) finishJournal(discoveryResult *canvus.DiscoveryResult) {
+	if cmd.journal == nil {
+		return
+	}
+
+	logger := logging.GetLogger()
+	if failed := len(discoveryResult.Canvases) - len(discoveryResult.CompletedCanvases); failed > 0 {
+		logger.Warn("%d canvases could not be read - run again with --resume to retry only those", failed)
+		return
+	}
+
+	if err := cmd.journal.Remove(); err != nil {
+		logger.Verbose("Failed to remove discovery journal: %v", err)
+	}
+}
+
+// saveManifest writes the results of this run to the manifest in the output folder and
+// removes the checkpoint of an earlier interrupted run
+func (cmd *DiscoverCommand) saveManifest(discoveryResult *canvus.DiscoveryResult, scanResult *filesystem.ScanResult, missingAssets []string, backupSearchResult *backup.SearchResult) {
+	manifestPath := manifest.DefaultPath(cmd.config.Paths.OutputFolder)
+	if !cmd.writeManifest(cmd.newManifest(discoveryResult, scanResult, missingAssets, backupSearchResult), manifestPath) {
+		return
+	}
+
+	checkpointPath := manifest.CheckpointPath(cmd.config.Paths.OutputFolder)
+	if err := os.Remove(checkpointPath); err != nil && !os.IsNotExist(err) {
+		logging.GetLogger().Verbose("Failed to remove discovery checkpoint: %v", err)
+	}
+}
+
+// saveCheckpoint writes the stages completed before an interruption to the checkpoint file,
+// marked with the stage that was cancelled. The manifest of the last complete run is kept.
+func (cmd *DiscoverCommand) saveCheckpoint(stage string, discoveryResult *canvus.DiscoveryResult, scanResult *filesystem.ScanResult, missingAssets []string) {
+	logger := logging.GetLogger()
+	logger.Warn("⏹️  Interrupted during %s - saving partial results", stage)
+
+	m := cmd.newManifest(discoveryResult, scanResult, missingAssets, nil)
+	m.InterruptedStage = stage
+	cmd.writeManifest(m, manifest.CheckpointPath(cmd.config.Paths.OutputFolder))
+}
+
+// newManifest builds a manifest from the results of this run
+func (cmd *DiscoverCommand) newManifest(discoveryResult *canvus.DiscoveryResult, scanResult *filesystem.ScanResult, missingAssets []string, backupSearchResult *backup.SearchResult) *manifest.Manifest {
+	m := manifest.New()
+	m.CanvusServer = cmd.config.CanvusServer.URL
+	m.AssetsFolder = cmd.config.Paths.AssetsFolder
+	for _, root := range cmd.config.GetBackupRoots() {
+		m.BackupRoots = append(m.BackupRoots, backup.BackupRoot{Path: root.Path, Label: root.Label, Priority: root.Priority})
+	}
+	if len(m.BackupRoots) > 0 {
+		m.BackupRootFolder = m.BackupRoots[0].Path
+	}
+	m.Discovery = discoveryResult
+	m.Scan = scanResult
+	m.Backup = backupSearchResult
+	if missingAssets != nil {
+		m.MissingHashes = missingAssets
+	}
+	m.Errors = append(m.Errors, discoveryResult.Errors...)
+	return m
+}
+
+// writeManifest saves a manifest or checkpoint, logging rather than failing on error.
+// It reports whether the file was written.
+func (cmd *DiscoverCommand) writeManifest(m *manifest.Manifest, path string) bool {
+	logger := logging.GetLogger()
+
+	if err := m.Save(path); err != nil {
+		logger.Warn("Failed to save discovery manifest: %v", err)
+		return false
+	}
+
+	if m.Complete() {
+		logger.Info("💾 Discovery manifest saved to: %s", path)
+	} else {
+		logger.Info("💾 Partial results saved to: %s (the last complete manifest is kept)", path)
+	}
+	return true
+}
+
+// logout ends the session with its own timeout, so it still runs after ctx was cancelled
+func logout(session *canvussdk.Session) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := session.Logout(ctx); err != nil {
+		logging.GetLogger().Verbose("Logout failed: %v", err)
+	}
+}
+
+// describeRateLimit summarises how the request rate adapted during a run
+func describeRateLimit(stats *canvus.RateLimitStats) string {
+	if len(stats.Changes) == 0 {
+		return fmt.Sprintf("steady at %.0f requests/sec (%d requests)", stats.InitialRate, stats.Requests)
+	}
+	return fmt.Sprintf("started at %.0f, lowest %.0f, ended at %.0f requests/sec (%d changes; %d throttled, %d server errors, %d latency spikes)",
+		stats.InitialRate, stats.LowestRate, stats.FinalRate, len(stats.Changes), stats.Throttled, stats.ServerErrors, stats.LatencySpikes)
+}
+
+// generateReports generates detailed, CSV and classification reports
+func (cmd *DiscoverCommand) generateReports(discoveryResult *canvus.DiscoveryResult, missingAssets []string, backupSearchResult *backup.SearchResult, classification *report.Classification) error {
+	generator := report.NewGenerator(cmd.config.Paths.OutputFolder, report.Options{})
+	generator.SetClassification(classification)
+	return generator.Generate(discoveryResult.Assets, missingAssets, backupSearchResult)
 }
 
 // printSummary prints a summary of the discovery results
-func (cmd *DiscoverCommand) printSummary(discoveryResult *canvus.DiscoveryResult, scanResult *filesystem.ScanResult, missingAssets []string) {
+func (cmd *DiscoverCommand) printSummary(discoveryResult *canvus.DiscoveryResult, scanResult *filesystem.ScanResult, missingAssets []string, classification *report.Classification) {
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("📊 DISCOVERY SUMMARY")
 	fmt.Println(strings.Repeat("=", 60))
 
 	fmt.Printf("⏱️  Discovery Duration: %v\n", discoveryResult.Duration)
 	fmt.Printf("📈 Total Canvases: %d\n", len(discoveryResult.Canvases))
+	if discoveryResult.ResumedCanvases > 0 {
+		fmt.Printf("⏩ Resumed From Journal: %d canvases\n", discoveryResult.ResumedCanvases)
+	}
+	if discoveryResult.CachedCanvases > 0 {
+		fmt.Printf("🗃️  Unchanged (From Cache): %d canvases\n", discoveryResult.CachedCanvases)
+	}
 	fmt.Printf("🎯 Total Media Assets: %d\n", len(discoveryResult.Assets))
 	fmt.Printf("🔗 Unique Assets: %d\n", len(discoveryResult.GetUniqueAssets()))
 	fmt.Printf("💾 Files in Assets Folder: %d\n", len(scanResult.Files))
 	fmt.Printf("💽 Total Assets Size: %.2f MB\n", float64(scanResult.TotalSize)/(1024*1024))
+	if scanResult.HashAlgorithm != "" {
+		fmt.Printf("🔑 Asset Hash Algorithm: %s\n", scanResult.HashAlgorithm)
+	}
+	if scanResult.ContentMismatches != nil {
+		fmt.Printf("🔬 Assets Failing Content Check: %d\n", len(scanResult.ContentMismatches))
+	}
 	fmt.Printf("❌ Missing Assets (Filesystem): %d\n", len(missingAssets))
 
 	// Show server validation results if available
@@ -313,6 +538,26 @@ func (cmd *DiscoverCommand) printSummary(discoveryResult *canvus.DiscoveryResult
 		if discoveryResult.ServerValidation.MissingAssets > 0 {
 			fmt.Printf("❌ Missing Assets (Server): %d\n", discoveryResult.ServerValidation.MissingAssets)
 		}
+		if discoveryResult.ServerValidation.ErrorAssets > 0 {
+			fmt.Printf("⚠️  Validation Errors (Server): %d\n", discoveryResult.ServerValidation.ErrorAssets)
+		}
+	}
+
+	// Show the combined classification with what to do about each class
+	if classification != nil && discoveryResult.ServerValidation != nil {
+		fmt.Println("🧭 Asset Classification:")
+		for _, class := range report.AllClasses {
+			if count := classification.Counts[class]; count > 0 {
+				fmt.Printf("   %s: %d\n", class.Description(), count)
+				if class != report.ClassHealthy {
+					fmt.Printf("      → %s\n", class.RecommendedAction())
+				}
+			}
+		}
+	}
+
+	if stats := discoveryResult.RateLimit; stats != nil {
+		fmt.Printf("🚦 API Rate: %s\n", describeRateLimit(stats))
 	}
 
 	if len(discoveryResult.Errors) > 0 {
@@ -324,19 +569,3 @@ func (cmd *DiscoverCommand) printSummary(discoveryResult *canvus.DiscoveryResult
 
 	fmt.Println(strings.Repeat("=", 60))
 }
-
-// writeFile writes content to a file
-func writeFile(filename, content string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filename, err)
-	}
-	defer file.Close()
-
-	_, err = file.WriteString(content)
-	if err != nil {
-		return fmt.Errorf("failed to write to file %s: %w", filename, err)
-	}
-
-	return nil
-}
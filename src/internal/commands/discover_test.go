@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/config"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+)
+
+func newTestConfig(t *testing.T) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Paths.AssetsFolder = t.TempDir()
+	cfg.Paths.BackupRootFolder = t.TempDir()
+	cfg.Paths.OutputFolder = t.TempDir()
+	return cfg
+}
+
+func TestCheckpointKeepsLastCompleteManifest(t *testing.T) {
+	cfg := newTestConfig(t)
+	cmd := NewDiscoverCommand(cfg, DiscoverOptions{})
+	manifestPath := manifest.DefaultPath(cfg.Paths.OutputFolder)
+	checkpointPath := manifest.CheckpointPath(cfg.Paths.OutputFolder)
+
+	complete := &canvus.DiscoveryResult{Assets: []canvus.AssetInfo{{Hash: "h1"}, {Hash: "h2"}}}
+	cmd.saveManifest(complete, nil, []string{"h2"}, nil)
+
+	// An interrupted run only has part of the canvases
+	partial := &canvus.DiscoveryResult{Assets: []canvus.AssetInfo{{Hash: "h1"}}, Interrupted: true}
+	cmd.saveCheckpoint(manifest.StageDiscovery, partial, nil, nil)
+
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		t.Fatalf("Load(manifest): %v", err)
+	}
+	if !m.Complete() || len(m.Discovery.Assets) != 2 || len(m.MissingHashes) != 1 {
+		t.Errorf("expected the complete manifest to be kept, got stage %q with %d assets", m.InterruptedStage, len(m.Discovery.Assets))
+	}
+
+	checkpoint, err := manifest.Load(checkpointPath)
+	if err != nil {
+		t.Fatalf("Load(checkpoint): %v", err)
+	}
+	if checkpoint.InterruptedStage != manifest.StageDiscovery || len(checkpoint.Discovery.Assets) != 1 {
+		t.Errorf("expected a discovery checkpoint with 1 asset, got stage %q with %d assets",
+			checkpoint.InterruptedStage, len(checkpoint.Discovery.Assets))
+	}
+
+	// The next complete run supersedes the checkpoint
+	cmd.saveManifest(complete, nil, []string{"h2"}, nil)
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoint to be removed by a complete run, stat returned %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/config"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+)
+
+// HarvestOptions controls how the harvest command runs
+type HarvestOptions struct {
+	From        string   // Discovery manifest to harvest for (defaults to the output folder)
+	Directories []string // Folders to search in addition to harvest.directories
+}
+
+// HarvestCommand searches arbitrary folders by content for assets no backup could supply
+type HarvestCommand struct {
+	config  *config.Config
+	options HarvestOptions
+}
+
+// NewHarvestCommand creates a new harvest command
+func NewHarvestCommand(cfg *config.Config, opts HarvestOptions) *HarvestCommand {
+	return &HarvestCommand{
+		config:  cfg,
+		options: opts,
+	}
+}
+
+// Execute hashes the files in the harvest directories and adds those matching a missing hash to the
+// manifest as restore candidates. Cancelling ctx (Ctrl+C) keeps the matches found so far.
+func (cmd *HarvestCommand) Execute(ctx context.Context) error {
+	logger := logging.GetLogger()
+
+	manifestPath := cmd.options.From
+	if manifestPath == "" {
+		manifestPath = manifest.DefaultPath(cmd.config.Paths.OutputFolder)
+	}
+
+	logger.Info("📂 Loading discovery manifest: %s", manifestPath)
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		logger.Error("Failed to load discovery manifest: %v", err)
+		return fmt.Errorf("failed to load discovery manifest (run discover first): %w", err)
+	}
+
+	if !m.Complete() {
+		logger.Warn("Manifest is from a run interrupted during %s - only its missing hashes are harvested", m.InterruptedStage)
+	}
+
+	directories := append(append([]string(nil), cmd.config.Harvest.Directories...), cmd.options.Directories...)
+	if len(directories) == 0 {
+		return fmt.Errorf("no harvest directories: set harvest.directories in the config or pass --dir")
+	}
+
+	// Files are matched by content only, so the hash algorithm must be known
+	algorithm, err := manifestHashAlgorithm(cmd.config, m)
+	if err != nil {
+		return err
+	}
+	if algorithm == nil {
+		return fmt.Errorf("asset hash algorithm unknown: set assets.hash_algorithm (e.g. sha256) or run discover again")
+	}
+
+	// Harvest whatever the backups could not supply
+	searchResult := m.Backup
+	if searchResult == nil {
+		searchResult = &backup.SearchResult{
+			FoundFiles:    make(map[string][]backup.BackupFile),
+			MissingHashes: append([]string(nil), m.MissingHashes...),
+		}
+	}
+	if len(searchResult.MissingHashes) == 0 {
+		logger.Info("✅ No missing assets left to harvest")
+		return nil
+	}
+
+	harvester := backup.NewHarvester(directories, backup.HarvestOptions{
+		Algorithm:   *algorithm,
+		Workers:     cmd.config.Performance.MaxConcurrentFiles,
+		MaxFileSize: int64(cmd.config.Harvest.MaxFileSizeMB) * 1024 * 1024,
+	})
+	result, harvestErr := harvester.Harvest(ctx, searchResult.MissingHashes)
+	if harvestErr != nil && result == nil {
+		return harvestErr
+	}
+
+	// Keep the matches, even from an interrupted harvest, so restore can use them
+	result.MergeInto(searchResult)
+	m.Backup = searchResult
+	m.Harvest = result
+	if err := m.Save(manifestPath); err != nil {
+		logger.Error("Failed to save discovery manifest: %v", err)
+		return fmt.Errorf("failed to save discovery manifest: %w", err)
+	}
+	logger.Info("💾 Manifest updated: %s", manifestPath)
+
+	cmd.printSummary(result, len(searchResult.MissingHashes))
+
+	return harvestErr
+}
+
+// printSummary prints the matched assets and what is still missing
+func (cmd *HarvestCommand) printSummary(result *backup.HarvestResult, stillMissing int) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("🌾 HARVEST SUMMARY")
+	fmt.Println(strings.Repeat("=", 60))
+
+	hashes := make([]string, 0, len(result.Matches))
+	for hash := range result.Matches {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	for _, hash := range hashes {
+		files := result.Matches[hash]
+		fmt.Printf("✅ %s <- %s\n", hash, files[0].Path)
+		if len(files) > 1 {
+			fmt.Printf("    (%d more copies)\n", len(files)-1)
+		}
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("📁 Directories: %s\n", strings.Join(result.Directories, ", "))
+	fmt.Printf("🔑 Hash Algorithm: %s\n", result.Algorithm)
+	fmt.Printf("📄 Files Hashed: %d (%.2f MB) in %v\n", result.FilesHashed, float64(result.BytesHashed)/(1024*1024), result.Duration.Round(time.Second))
+	if result.Skipped > 0 {
+		fmt.Printf("⏭️  Files Skipped: %d (too large or unreadable)\n", result.Skipped)
+	}
+	fmt.Printf("🌾 Assets Matched: %d\n", len(result.Matches))
+	fmt.Printf("❌ Still Missing: %d\n", stillMissing)
+	if len(result.Matches) > 0 {
+		fmt.Println("⚠️  Note: Run the restore command to copy the matched files into the assets folder")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/config"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+)
+
+// ReconstructOptions controls how the reconstruct command runs
+type ReconstructOptions struct {
+	From         string // Discovery manifest to reconstruct for (defaults to the output folder)
+	ReviewFolder string // Folder for the rebuilt images (defaults to <output_folder>/degraded_review)
+}
+
+// ReconstructCommand rebuilds lost images and PDFs from the mipmaps the server still holds
+type ReconstructCommand struct {
+	config  *config.Config
+	options ReconstructOptions
+}
+
+// NewReconstructCommand creates a new reconstruct command
+func NewReconstructCommand(cfg *config.Config, opts ReconstructOptions) *ReconstructCommand {
+	return &ReconstructCommand{
+		config:  cfg,
+		options: opts,
+	}
+}
+
+// Execute saves the highest-resolution mipmap level of every asset no recovery source could supply
+// into the review folder and records the lossy reconstructions in the manifest for the reports.
+// The assets folder is never touched. Cancelling ctx (Ctrl+C) keeps the assets rebuilt so far.
+func (cmd *ReconstructCommand) Execute(ctx context.Context) error {
+	logger := logging.GetLogger()
+
+	manifestPath := cmd.options.From
+	if manifestPath == "" {
+		manifestPath = manifest.DefaultPath(cmd.config.Paths.OutputFolder)
+	}
+
+	logger.Info("📂 Loading discovery manifest: %s", manifestPath)
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		logger.Error("Failed to load discovery manifest: %v", err)
+		return fmt.Errorf("failed to load discovery manifest (run discover first): %w", err)
+	}
+	if m.Discovery == nil {
+		return fmt.Errorf("manifest has no discovery results (run discover first)")
+	}
+
+	// Only assets with no original copy anywhere are worth a lossy reconstruction
+	missing := m.MissingHashes
+	if m.Backup != nil {
+		missing = m.Backup.MissingHashes
+	}
+	if len(missing) == 0 {
+		logger.Info("✅ No missing assets left to reconstruct")
+		return nil
+	}
+
+	reviewFolder := cmd.options.ReviewFolder
+	if reviewFolder == "" {
+		reviewFolder = filepath.Join(cmd.config.Paths.OutputFolder, canvus.ReviewFolderName)
+	}
+
+	session, rateLimiter := newRateLimitedSession(cmd.config, cmd.config.GetCanvusAPIURL(), cmd.config.CanvusServer.InsecureTLS)
+	defer rateLimiter.Stop()
+
+	logger.Info("🔐 Authenticating with Canvus Server...")
+	if err := session.Login(ctx, cmd.config.CanvusServer.Username, cmd.config.CanvusServer.Password); err != nil {
+		logger.Error("Authentication failed: %v", err)
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+	defer logout(session)
+
+	rebuilder := canvus.NewMipmapRebuilder(session, reviewFolder, cmd.config.Performance.MaxConcurrentAPI)
+	result, rebuildErr := rebuilder.Rebuild(ctx, m.Discovery.Assets, missing)
+	if result == nil {
+		return rebuildErr
+	}
+
+	// Keep the reconstructions, even from an interrupted run, so the reports can mark them
+	m.Degraded = result
+	if err := m.Save(manifestPath); err != nil {
+		logger.Error("Failed to save discovery manifest: %v", err)
+		return fmt.Errorf("failed to save discovery manifest: %w", err)
+	}
+	logger.Info("💾 Manifest updated: %s", manifestPath)
+
+	cmd.printSummary(result)
+
+	return rebuildErr
+}
+
+// printSummary prints the rebuilt assets and those the server had no mipmaps for
+func (cmd *ReconstructCommand) printSummary(result *canvus.DegradedResult) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("🖼️  DEGRADED RECOVERY SUMMARY")
+	fmt.Println(strings.Repeat("=", 60))
+
+	hashes := make([]string, 0, len(result.Assets))
+	for hash := range result.Assets {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	incomplete := 0
+	for _, hash := range hashes {
+		degraded := result.Assets[hash]
+		fmt.Printf("⚠️  %s (%s) <- mipmap level %d, %d of %d pages\n",
+			hash, degraded.WidgetType, degraded.Pages[0].Level, len(degraded.Pages), degraded.PageCount)
+		if !degraded.Complete() {
+			incomplete++
+		}
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("📁 Review Folder: %s\n", result.ReviewFolder)
+	fmt.Printf("🖼️  Assets Rebuilt: %d in %v\n", len(result.Assets), result.Duration.Round(time.Second))
+	if incomplete > 0 {
+		fmt.Printf("📄 Missing Some Pages: %d\n", incomplete)
+	}
+	fmt.Printf("❌ No Mipmaps: %d\n", len(result.Failed))
+	if len(result.Assets) > 0 {
+		fmt.Println("⚠️  Note: These are LOSSY reconstructions, not the original files. Review them before re-uploading;")
+		fmt.Println("    they are marked as lossy in the reports (run the report command to regenerate them)")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+}
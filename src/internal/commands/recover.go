@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/config"
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+)
+
+// RecoverOptions controls how the recover command runs
+type RecoverOptions struct {
+	From        string // Discovery manifest to recover for (defaults to the output folder)
+	Server      string // Secondary server URL (overrides replica.url)
+	Username    string // Secondary server username (overrides replica.username)
+	InsecureTLS bool   // Skip TLS certificate verification on the secondary server
+	DryRun      bool   // Show which files the server holds without downloading them
+	Yes         bool   // Skip the confirmation prompt before downloading
+}
+
+// RecoverCommand downloads missing assets from a secondary Canvus Server
+type RecoverCommand struct {
+	config  *config.Config
+	options RecoverOptions
+}
+
+// NewRecoverCommand creates a new recover command
+func NewRecoverCommand(cfg *config.Config, opts RecoverOptions) *RecoverCommand {
+	return &RecoverCommand{
+		config:  cfg,
+		options: opts,
+	}
+}
+
+// Execute locates the assets no backup could supply on the secondary server and streams them into
+// the assets folder as {hash}.{ext}, appending a provenance record for every recovered file.
+// Cancelling ctx (Ctrl+C) stops after the file being downloaded.
+func (cmd *RecoverCommand) Execute(ctx context.Context) error {
+	logger := logging.GetLogger()
+
+	replica := cmd.config.Replica
+	if cmd.options.Server != "" {
+		replica.URL = cmd.options.Server
+	}
+	if cmd.options.Username != "" {
+		replica.Username = cmd.options.Username
+	}
+	replica.InsecureTLS = replica.InsecureTLS || cmd.options.InsecureTLS
+	if replica.URL == "" || replica.Username == "" {
+		return fmt.Errorf("no secondary server: set replica.url and replica.username in the config or pass --server and --username")
+	}
+
+	manifestPath := cmd.options.From
+	if manifestPath == "" {
+		manifestPath = manifest.DefaultPath(cmd.config.Paths.OutputFolder)
+	}
+
+	logger.Info("📂 Loading discovery manifest: %s", manifestPath)
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		logger.Error("Failed to load discovery manifest: %v", err)
+		return fmt.Errorf("failed to load discovery manifest (run discover first): %w", err)
+	}
+	if m.Discovery == nil {
+		return fmt.Errorf("manifest has no discovery results (run discover first)")
+	}
+
+	// Recover whatever the backups could not supply
+	missing := m.MissingHashes
+	if m.Backup != nil {
+		missing = m.Backup.MissingHashes
+	}
+	if len(missing) == 0 {
+		logger.Info("✅ No missing assets left to recover")
+		return nil
+	}
+
+	if replica.Password == "" {
+		prompts := config.NewInteractivePrompts()
+		replica.Password = prompts.PromptForPassword(fmt.Sprintf("Password for %s on %s", replica.Username, replica.URL))
+		prompts.Close()
+	}
+
+	session, rateLimiter := newRateLimitedSession(cmd.config, config.APIURL(replica.URL), replica.InsecureTLS)
+	defer rateLimiter.Stop()
+
+	logger.Info("🔐 Authenticating with secondary Canvus Server: %s", replica.URL)
+	if err := session.Login(ctx, replica.Username, replica.Password); err != nil {
+		logger.Error("Authentication failed: %v", err)
+		return fmt.Errorf("authentication with %s failed: %w", replica.URL, err)
+	}
+	defer logout(session)
+
+	// The secondary server only serves an asset through a canvas of its own that uses it
+	canvases, err := session.ListCanvases(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list canvases on %s: %w", replica.URL, err)
+	}
+	assets := canvus.MapAssetsToCanvases(m.Discovery.Assets, canvases)
+
+	logger.Info("🌐 Looking for %d missing assets on %s (%d canvases)...", len(missing), replica.URL, len(canvases))
+	algorithm, err := manifestHashAlgorithm(cmd.config, m)
+	if err != nil {
+		return err
+	}
+	source := canvus.NewAPISource(session, canvus.SourceReplica, replica.URL, 0, assets, cmd.config.Performance.MaxConcurrentAPI)
+	source.SetHashAlgorithm(algorithm)
+	found, err := source.FindCandidates(ctx, missing)
+	if err != nil {
+		return err
+	}
+
+	restorer := backup.NewRestorer(cmd.config.Paths.AssetsFolder)
+	restorer.RegisterSource(source)
+	if algorithm != nil {
+		restorer.SetHashAlgorithm(algorithm)
+	} else {
+		logger.Warn("⚠️  Asset hash algorithm unknown - downloaded files will not be checked against their hash")
+	}
+	plan := restorer.PlanRestore(found)
+
+	cmd.printPlan(plan, replica.URL)
+
+	if cmd.options.DryRun {
+		fmt.Println("🧪 Dry run: no files were downloaded")
+		return nil
+	}
+	if plan.CountByAction(backup.ActionCopy) == 0 {
+		logger.Info("✅ Nothing to recover")
+		return nil
+	}
+
+	if !cmd.options.Yes {
+		prompts := config.NewInteractivePrompts()
+		message := fmt.Sprintf("Download %d files from %s into %s", plan.CountByAction(backup.ActionCopy), replica.URL, plan.AssetsFolder)
+		confirmed := prompts.PromptForConfirmation(message)
+		prompts.Close()
+		if !confirmed {
+			fmt.Println("❎ Recovery cancelled")
+			return nil
+		}
+	}
+
+	journal, err := openRestoreJournal(cmd.config)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+	restorer.SetJournal(journal)
+
+	result, applyErr := restorer.ApplyPlan(ctx, plan)
+	if applyErr != nil && result == nil {
+		logger.Error("Recovery failed: %v", applyErr)
+		return fmt.Errorf("recovery failed: %w", applyErr)
+	}
+
+	if err := cmd.writeProvenance(plan, result, algorithm, replica.Username); err != nil {
+		logger.Warn("Failed to write provenance records: %v", err)
+	}
+
+	printRestoreResult(result)
+
+	if result.Interrupted {
+		return applyErr
+	}
+	if len(result.FailedFiles) > 0 {
+		return fmt.Errorf("%d of %d files failed to recover", len(result.FailedFiles), plan.CountByAction(backup.ActionCopy))
+	}
+	return nil
+}
+
+// writeProvenance appends a provenance record for every recovered file to the output folder
+func (cmd *RecoverCommand) writeProvenance(plan *backup.RestorePlan, result *backup.RestoreResult, algorithm *digest.Algorithm, username string) error {
+	entries := make(map[string]backup.PlanEntry, len(plan.Entries))
+	for _, entry := range plan.Entries {
+		entries[entry.Hash] = entry
+	}
+
+	algorithmName := ""
+	if algorithm != nil {
+		algorithmName = algorithm.String()
+	}
+
+	records := make([]backup.Provenance, 0, len(result.RestoredFiles))
+	for _, status := range result.Files {
+		if status.Status != backup.StatusRestored {
+			continue
+		}
+		record := backup.NewProvenance(entries[status.Hash], status, algorithmName)
+		record.RecoveredBy = username
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	provenancePath := filepath.Join(cmd.config.Paths.OutputFolder, backup.ProvenanceFilename)
+	if err := backup.AppendProvenance(provenancePath, records); err != nil {
+		return err
+	}
+	fmt.Printf("📜 Provenance of %d recovered files appended to: %s\n", len(records), provenancePath)
+	return nil
+}
+
+// printPlan prints the files the secondary server will supply
+func (cmd *RecoverCommand) printPlan(plan *backup.RestorePlan, server string) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("📋 RECOVERY PLAN")
+	fmt.Println(strings.Repeat("=", 60))
+
+	for _, entry := range plan.Entries {
+		fmt.Printf("[%s] %s (canvas %s)\n", entry.Action, entry.Hash, entry.Source.CanvasID)
+		fmt.Printf("    To:   %s\n", entry.TargetPath)
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("🌐 Secondary Server: %s\n", server)
+	fmt.Printf("📁 Assets Folder: %s\n", plan.AssetsFolder)
+	fmt.Printf("📄 Files to download: %d (%.2f MB)\n", plan.CountByAction(backup.ActionCopy), float64(plan.TotalBytes())/(1024*1024))
+	fmt.Printf("⏭️  Already present: %d\n", plan.CountByAction(backup.ActionSkip))
+	fmt.Printf("❌ Not on this server: %d\n", len(plan.Unresolved))
+	fmt.Println(strings.Repeat("=", 60))
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/config"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/report"
+)
+
+// ReportOptions controls how the report command runs
+type ReportOptions struct {
+	From   string         // Discovery manifest to report from (defaults to the output folder)
+	Report report.Options // Filters and sort order
+}
+
+// ReportCommand regenerates reports from a saved discovery manifest without network access
+type ReportCommand struct {
+	config  *config.Config
+	options ReportOptions
+}
+
+// NewReportCommand creates a new report command
+func NewReportCommand(cfg *config.Config, opts ReportOptions) *ReportCommand {
+	return &ReportCommand{
+		config:  cfg,
+		options: opts,
+	}
+}
+
+// Execute loads the manifest and writes the filtered reports to the output folder
+func (cmd *ReportCommand) Execute() error {
+	logger := logging.GetLogger()
+
+	if err := cmd.options.Report.Validate(); err != nil {
+		return err
+	}
+
+	manifestPath := cmd.options.From
+	if manifestPath == "" {
+		manifestPath = manifest.DefaultPath(cmd.config.Paths.OutputFolder)
+	}
+
+	logger.Info("📂 Loading discovery manifest: %s", manifestPath)
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		logger.Error("Failed to load discovery manifest: %v", err)
+		return fmt.Errorf("failed to load discovery manifest (run discover first): %w", err)
+	}
+
+	if !m.Complete() {
+		logger.Warn("Manifest is from a run interrupted during %s - reports cover the partial results only", m.InterruptedStage)
+	}
+
+	if m.Discovery == nil {
+		return fmt.Errorf("manifest %s contains no discovery results", manifestPath)
+	}
+
+	logger.Info("📈 Manifest from %s: %d canvases, %d assets, %d missing",
+		m.CreatedAt.Format("2006-01-02 15:04:05"), len(m.Discovery.Canvases), len(m.Discovery.Assets), len(m.MissingHashes))
+
+	opts := cmd.options.Report
+	opts.SourceManifest = fmt.Sprintf("%s (created %s)", manifestPath, m.CreatedAt.Format("2006-01-02 15:04:05"))
+
+	generator := report.NewGenerator(cmd.config.Paths.OutputFolder, opts)
+	classification := report.ClassifyAssets(m.Discovery.Assets, m.Scan, m.Discovery.ServerValidation)
+	generator.SetClassification(classification)
+	generator.SetDegraded(m.Degraded)
+	selected := generator.SelectMissingAssets(m.Discovery.Assets, m.MissingHashes, m.Backup)
+	logger.Info("📋 %d missing assets match the report filters", len(selected))
+
+	if err := generator.GenerateDetailedReport(selected, m.Backup); err != nil {
+		return fmt.Errorf("failed to generate detailed report: %w", err)
+	}
+	if err := generator.GenerateCSVReport(selected, m.Backup); err != nil {
+		return fmt.Errorf("failed to generate CSV report: %w", err)
+	}
+	if err := generator.GenerateClassificationCSV(m.Discovery.Assets); err != nil {
+		return fmt.Errorf("failed to generate classification report: %w", err)
+	}
+
+	return nil
+}
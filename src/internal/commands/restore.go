@@ -0,0 +1,464 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/config"
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+)
+
+// RestoreOptions controls how the restore command runs
+type RestoreOptions struct {
+	From            string // Discovery manifest to restore from (defaults to the output folder)
+	DryRun          bool   // Show the restore plan without copying anything
+	Yes             bool   // Skip the confirmation prompt before copying
+	Undo            string // Restore journal whose restored files should be deleted again
+	Run             string // Only undo the files of this restore run
+	EmitScript      bool   // Export the plan as a PowerShell script and robocopy jobs instead of copying
+	AllowIncomplete bool   // Restore from the checkpoint of an interrupted run
+}
+
+// RestoreCommand handles the restore command
+type RestoreCommand struct {
+	config  *config.Config
+	options RestoreOptions
+}
+
+// NewRestoreCommand creates a new restore command
+func NewRestoreCommand(cfg *config.Config, opts RestoreOptions) *RestoreCommand {
+	return &RestoreCommand{
+		config:  cfg,
+		options: opts,
+	}
+}
+
+// Execute builds a restore plan from a previous discovery and applies it.
+// Cancelling ctx (Ctrl+C) stops after the file being copied; the partial results are still written.
+func (cmd *RestoreCommand) Execute(ctx context.Context) error {
+	logger := logging.GetLogger()
+
+	if cmd.options.Undo != "" {
+		return cmd.undo()
+	}
+
+	manifestPath := cmd.options.From
+	if manifestPath == "" {
+		manifestPath = manifest.DefaultPath(cmd.config.Paths.OutputFolder)
+	}
+
+	logger.Info("📂 Loading discovery manifest: %s", manifestPath)
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		logger.Error("Failed to load discovery manifest: %v", err)
+		return fmt.Errorf("failed to load discovery manifest (run discover first): %w", err)
+	}
+
+	if !m.Complete() {
+		if !cmd.options.AllowIncomplete {
+			return fmt.Errorf("manifest %s is from a run interrupted during %s; run discover again or pass --allow-incomplete to restore from the partial results",
+				manifestPath, m.InterruptedStage)
+		}
+		logger.Warn("Manifest is from a run interrupted during %s - the restore will be incomplete", m.InterruptedStage)
+	}
+
+	if m.AssetsFolder != "" && m.AssetsFolder != cmd.config.Paths.AssetsFolder {
+		logger.Warn("Manifest was created for assets folder %s but restoring into %s", m.AssetsFolder, cmd.config.Paths.AssetsFolder)
+	}
+
+	searchResult := m.Backup
+	if searchResult == nil {
+		logger.Info("✅ Manifest has no backup search results - nothing to restore")
+		return nil
+	}
+
+	// Build the plan
+	restorer := backup.NewRestorer(cmd.config.Paths.AssetsFolder)
+	algorithm, err := manifestHashAlgorithm(cmd.config, m)
+	if err != nil {
+		return err
+	}
+	if algorithm != nil {
+		logger.Info("🔑 Restored files will be checked against their hash (%s)", algorithm)
+		restorer.SetHashAlgorithm(algorithm)
+	} else {
+		logger.Warn("⚠️  Asset hash algorithm unknown - restored files will not be checked against their hash")
+	}
+	plan := restorer.PlanRestore(searchResult)
+
+	if err := cmd.savePlan(plan); err != nil {
+		logger.Warn("Failed to save restore plan: %v", err)
+	}
+
+	cmd.printPlan(plan)
+
+	if cmd.options.EmitScript {
+		return cmd.emitScript(ctx, restorer, plan)
+	}
+
+	if cmd.options.DryRun {
+		fmt.Println("🧪 Dry run: no files were copied")
+		return nil
+	}
+
+	if plan.CountByAction(backup.ActionCopy) == 0 {
+		logger.Info("✅ Nothing to restore")
+		return nil
+	}
+
+	if !cmd.options.Yes {
+		prompts := config.NewInteractivePrompts()
+		message := fmt.Sprintf("Copy %d files into %s", plan.CountByAction(backup.ActionCopy), plan.AssetsFolder)
+		confirmed := prompts.PromptForConfirmation(message)
+		prompts.Close()
+		if !confirmed {
+			fmt.Println("❎ Restore cancelled")
+			return nil
+		}
+	}
+
+	// Record every step so an interrupted restore resumes cleanly
+	journal, err := openRestoreJournal(cmd.config)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+	restorer.SetJournal(journal)
+
+	// Files found on the server are downloaded while the plan is applied
+	if plan.CountBySource(canvus.SourceCanvusAPI) > 0 {
+		session, rateLimiter := newRateLimitedSession(cmd.config, cmd.config.GetCanvusAPIURL(), cmd.config.CanvusServer.InsecureTLS)
+		defer rateLimiter.Stop()
+
+		logger.Info("🔐 Authenticating with Canvus Server to download %d assets...", plan.CountBySource(canvus.SourceCanvusAPI))
+		if err := session.Login(ctx, cmd.config.CanvusServer.Username, cmd.config.CanvusServer.Password); err != nil {
+			logger.Error("Authentication failed: %v", err)
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+		defer logout(session)
+
+		restorer.RegisterSource(canvus.NewAPISource(session, canvus.SourceCanvusAPI, cmd.config.CanvusServer.URL, 0, nil, cmd.config.Performance.MaxConcurrentAPI))
+	}
+
+	// Apply the plan
+	result, err := restorer.ApplyPlan(ctx, plan)
+	if err != nil && result == nil {
+		logger.Error("Restore failed: %v", err)
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	if err := cmd.writeResultsCSV(result); err != nil {
+		logger.Warn("Failed to write restore results: %v", err)
+	}
+
+	printRestoreResult(result)
+
+	if result.Interrupted {
+		return err
+	}
+
+	if len(result.FailedFiles) > 0 {
+		return fmt.Errorf("%d of %d files failed to restore", len(result.FailedFiles), len(plan.Entries))
+	}
+
+	return nil
+}
+
+// emitScript exports the plan for an account that can write to the assets folder
+func (cmd *RestoreCommand) emitScript(ctx context.Context, restorer *backup.Restorer, plan *backup.RestorePlan) error {
+	logger := logging.GetLogger()
+
+	folder := filepath.Join(cmd.config.Paths.OutputFolder, backup.ScriptFolderName)
+	logger.Info("📝 Checking %d source files and writing the restore script to %s...", plan.CountByAction(backup.ActionCopy), folder)
+	script, err := restorer.EmitScript(ctx, plan, folder)
+	if err != nil {
+		logger.Error("Script export failed: %v", err)
+		return fmt.Errorf("script export failed: %w", err)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("📝 RESTORE SCRIPT")
+	fmt.Println(strings.Repeat("=", 60))
+
+	hashes := make([]string, 0, len(script.Excluded))
+	for hash := range script.Excluded {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	for _, hash := range hashes {
+		fmt.Printf("⛔ %s left out: %s\n", hash, script.Excluded[hash])
+	}
+
+	fmt.Printf("📄 PowerShell script: %s\n", script.Script)
+	fmt.Printf("🔑 Hash manifest: %s\n", script.Manifest)
+	for _, job := range script.JobFiles {
+		fmt.Printf("📦 Robocopy job: %s\n", job)
+	}
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("📄 Files exported: %d\n", len(script.Entries))
+	fmt.Printf("📝 Script only (renamed on copy): %d\n", len(script.ScriptOnly))
+	fmt.Printf("⛔ Left out: %d\n", len(script.Excluded))
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("Review the script, then have an administrator run it (try -WhatIf first), or run the\n")
+	fmt.Printf("robocopy jobs followed by %s -VerifyOnly.\n", backup.ScriptFilename)
+	return nil
+}
+
+// undo deletes the files a journal records as restored, leaving any that were changed since
+func (cmd *RestoreCommand) undo() error {
+	logger := logging.GetLogger()
+
+	if _, err := os.Stat(cmd.options.Undo); err != nil {
+		return fmt.Errorf("failed to open restore journal: %w", err)
+	}
+	// Check every file first, so the confirmation shows exactly what will be deleted. The preview
+	// only reads the journal; it is opened for writing once the undo is confirmed.
+	journal, err := backup.LoadRestoreJournal(cmd.options.Undo)
+	if err != nil {
+		return fmt.Errorf("failed to open restore journal: %w", err)
+	}
+	preview, err := backup.Undo(journal, cmd.options.Run, true)
+	if err != nil {
+		return err
+	}
+	if len(preview.Runs) == 0 && len(preview.Cleaned) == 0 {
+		if cmd.options.Run != "" {
+			return fmt.Errorf("restore journal %s has no restored files for run %s", journal.Path(), cmd.options.Run)
+		}
+		logger.Info("✅ Restore journal %s has no restored files left to undo", journal.Path())
+		return nil
+	}
+
+	if cmd.options.DryRun {
+		printUndoResult(preview, true)
+		fmt.Println("🧪 Dry run: no files were deleted")
+		return nil
+	}
+
+	if len(preview.Deleted)+len(preview.Cleaned) > 0 && !cmd.options.Yes {
+		prompts := config.NewInteractivePrompts()
+		message := fmt.Sprintf("Delete %d restored files (runs %s)", len(preview.Deleted), strings.Join(preview.Runs, ", "))
+		confirmed := prompts.PromptForConfirmation(message)
+		prompts.Close()
+		if !confirmed {
+			fmt.Println("❎ Undo cancelled")
+			return nil
+		}
+	}
+
+	journal, err = backup.OpenRestoreJournal(cmd.options.Undo)
+	if err != nil {
+		return fmt.Errorf("failed to open restore journal: %w", err)
+	}
+	defer journal.Close()
+
+	logger.Info("↩️  Undoing restore runs %s from %s", strings.Join(preview.Runs, ", "), journal.Path())
+	result, err := backup.Undo(journal, cmd.options.Run, false)
+	if err != nil {
+		logger.Error("Undo failed: %v", err)
+		return fmt.Errorf("undo failed: %w", err)
+	}
+
+	if err := cmd.writeUndoCSV(result); err != nil {
+		logger.Warn("Failed to write undo results: %v", err)
+	}
+
+	printUndoResult(result, false)
+
+	if len(result.Refused) > 0 {
+		return fmt.Errorf("%d restored files were changed or could not be checked and were left in place", len(result.Refused))
+	}
+	return nil
+}
+
+// printUndoResult prints what an undo deleted and what it refused to touch
+func printUndoResult(result *backup.UndoResult, dryRun bool) {
+	deleted := "🗑️  Deleted"
+	if dryRun {
+		deleted = "🗑️  Would delete"
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("↩️  UNDO SUMMARY")
+	fmt.Println(strings.Repeat("=", 60))
+
+	for _, file := range result.Deleted {
+		fmt.Printf("%s %s (run %s)\n", deleted, file.Target, file.Run)
+	}
+	for _, file := range result.Refused {
+		fmt.Printf("⛔ Left %s: %s\n", file.Target, file.Reason)
+	}
+	for _, file := range result.Gone {
+		fmt.Printf("⏭️  %s already deleted\n", file.Target)
+	}
+	for _, path := range result.Cleaned {
+		fmt.Printf("🧹 Removed unfinished copy %s\n", path)
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("%s: %d\n", deleted, len(result.Deleted))
+	fmt.Printf("⛔ Refused: %d\n", len(result.Refused))
+	fmt.Printf("⏭️  Already deleted: %d\n", len(result.Gone))
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+// writeUndoCSV writes the per-file undo outcome to the output folder
+func (cmd *RestoreCommand) writeUndoCSV(result *backup.UndoResult) error {
+	reportPath := filepath.Join(cmd.config.Paths.OutputFolder, "undo_results.csv")
+
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", reportPath, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"Run", "Hash", "TargetPath", "Outcome", "Reason"})
+	for _, f := range result.Deleted {
+		writer.Write([]string{f.Run, f.Hash, f.Target, "deleted", ""})
+	}
+	for _, f := range result.Refused {
+		writer.Write([]string{f.Run, f.Hash, f.Target, "refused", f.Reason})
+	}
+	for _, f := range result.Gone {
+		writer.Write([]string{f.Run, f.Hash, f.Target, "already deleted", ""})
+	}
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", reportPath, err)
+	}
+
+	fmt.Printf("📊 Undo results saved to: %s\n", reportPath)
+	return nil
+}
+
+// openRestoreJournal opens the restore journal in the output folder, shared by every command that
+// writes into the assets folder
+func openRestoreJournal(cfg *config.Config) (*backup.RestoreJournal, error) {
+	journal, err := backup.OpenRestoreJournal(filepath.Join(cfg.Paths.OutputFolder, backup.RestoreJournalFilename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open restore journal: %w", err)
+	}
+	logging.GetLogger().Info("📓 Restore journal: %s", journal.Path())
+	return journal, nil
+}
+
+// manifestHashAlgorithm returns the configured asset hash algorithm, else the one detected during
+// the discovery that wrote the manifest (nil if neither is known)
+func manifestHashAlgorithm(cfg *config.Config, m *manifest.Manifest) (*digest.Algorithm, error) {
+	name := cfg.Assets.HashAlgorithm
+	if name == "" && m.Scan != nil {
+		name = m.Scan.HashAlgorithm
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	algorithm, err := digest.Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset hash algorithm: %w", err)
+	}
+	return &algorithm, nil
+}
+
+// savePlan writes the restore plan to the output folder so it can be reviewed
+func (cmd *RestoreCommand) savePlan(plan *backup.RestorePlan) error {
+	planPath := filepath.Join(cmd.config.Paths.OutputFolder, "restore_plan.json")
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode restore plan: %w", err)
+	}
+
+	if err := os.WriteFile(planPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write restore plan %s: %w", planPath, err)
+	}
+
+	fmt.Printf("📄 Restore plan saved to: %s\n", planPath)
+	return nil
+}
+
+// printPlan prints the restore plan
+func (cmd *RestoreCommand) printPlan(plan *backup.RestorePlan) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("📋 RESTORE PLAN")
+	fmt.Println(strings.Repeat("=", 60))
+
+	for _, entry := range plan.Entries {
+		fmt.Printf("[%s] %s\n", entry.Action, entry.Hash)
+		fmt.Printf("    From: [%s] %s (preferred of %d copies)\n", entry.Source.Root, entry.Source.Path, entry.Candidates)
+		fmt.Printf("    Generation: %s\n", entry.Source.DescribeGeneration())
+		fmt.Printf("    To:   %s\n", entry.TargetPath)
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("📁 Assets Folder: %s\n", plan.AssetsFolder)
+	fmt.Printf("📄 Files to copy: %d (%.2f MB)\n", plan.CountByAction(backup.ActionCopy), float64(plan.TotalBytes())/(1024*1024))
+	fmt.Printf("⏭️  Already present: %d\n", plan.CountByAction(backup.ActionSkip))
+	fmt.Printf("❌ No backup available: %d\n", len(plan.Unresolved))
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+// printRestoreResult prints the per-file status of an applied restore
+func printRestoreResult(result *backup.RestoreResult) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("📊 RESTORE SUMMARY")
+	fmt.Println(strings.Repeat("=", 60))
+
+	for _, file := range result.Files {
+		switch file.Status {
+		case backup.StatusRestored:
+			if file.Verified {
+				fmt.Printf("✅ %s -> %s (hash verified)\n", file.Hash, file.TargetPath)
+			} else {
+				fmt.Printf("✅ %s -> %s\n", file.Hash, file.TargetPath)
+			}
+		case backup.StatusSkipped:
+			fmt.Printf("⏭️  %s already present\n", file.Hash)
+		case backup.StatusFailed:
+			fmt.Printf("❌ %s: %s\n", file.Hash, file.Error)
+		}
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("✅ Restored: %d (%.2f MB)\n", len(result.RestoredFiles), float64(result.TotalBytes)/(1024*1024))
+	fmt.Printf("⏭️  Skipped: %d\n", len(result.SkippedFiles))
+	fmt.Printf("❌ Failed: %d\n", len(result.FailedFiles))
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+// writeResultsCSV writes the per-file restore status to the output folder
+func (cmd *RestoreCommand) writeResultsCSV(result *backup.RestoreResult) error {
+	reportPath := filepath.Join(cmd.config.Paths.OutputFolder, "restore_results.csv")
+
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", reportPath, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"Hash", "Status", "SourcePath", "TargetPath", "Bytes", "Error", "Verified"})
+	for _, f := range result.Files {
+		writer.Write([]string{f.Hash, string(f.Status), f.SourcePath, f.TargetPath, fmt.Sprintf("%d", f.Bytes), f.Error, fmt.Sprintf("%t", f.Verified)})
+	}
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", reportPath, err)
+	}
+
+	fmt.Printf("📊 Restore results saved to: %s\n", reportPath)
+	return nil
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+)
+
+func TestRestoreRefusesIncompleteManifest(t *testing.T) {
+	cfg := newTestConfig(t)
+	checkpointPath := manifest.CheckpointPath(cfg.Paths.OutputFolder)
+
+	m := manifest.New()
+	m.AssetsFolder = cfg.Paths.AssetsFolder
+	m.Discovery = &canvus.DiscoveryResult{}
+	m.InterruptedStage = manifest.StageBackupSearch
+	if err := m.Save(checkpointPath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	err := NewRestoreCommand(cfg, RestoreOptions{From: checkpointPath, Yes: true}).Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "--allow-incomplete") {
+		t.Fatalf("expected an incomplete manifest to be refused, got %v", err)
+	}
+
+	// The checkpoint has no backup results, so an allowed restore has nothing to do
+	err = NewRestoreCommand(cfg, RestoreOptions{From: checkpointPath, Yes: true, AllowIncomplete: true}).Execute(context.Background())
+	if err != nil {
+		t.Errorf("expected --allow-incomplete to accept the checkpoint, got %v", err)
+	}
+}
+
+func TestUndoDryRunDoesNotWriteJournal(t *testing.T) {
+	cfg := newTestConfig(t)
+	target := filepath.Join(cfg.Paths.AssetsFolder, "aaaaaaaa.jpg")
+	if err := os.WriteFile(target, []byte("restored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("restored"))
+	entry, err := json.Marshal(backup.RestoreJournalEntry{
+		Run: "20250907-101500", Hash: "aaaaaaaa", Target: target, Step: backup.StepDone,
+		Bytes: int64(len("restored")), SHA256: hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A read-only journal whose last line has no newline, as a killed run leaves it
+	journalPath := filepath.Join(t.TempDir(), backup.RestoreJournalFilename)
+	if err := os.WriteFile(journalPath, entry, 0444); err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewRestoreCommand(cfg, RestoreOptions{Undo: journalPath, DryRun: true}).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("dry run undo: %v", err)
+	}
+	if got, err := os.ReadFile(journalPath); err != nil || string(got) != string(entry) {
+		t.Errorf("expected the journal to be left unchanged, got %q, %v", got, err)
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("dry run deleted %s: %v", target, err)
+	}
+}
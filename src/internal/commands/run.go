@@ -4,30 +4,30 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/spf13/cobra"
-
-	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
-	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
 	"github.com/jaypaulb/kpmg-db-solver/internal/config"
 	"github.com/jaypaulb/kpmg-db-solver/internal/filesystem"
 	"github.com/jaypaulb/kpmg-db-solver/internal/logging"
-	canvussdk "canvus-go-api/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/manifest"
+	"github.com/jaypaulb/kpmg-db-solver/internal/report"
 )
 
 // RunCommand handles the run command (complete workflow)
 type RunCommand struct {
-	config *config.Config
+	config  *config.Config
+	options DiscoverOptions
 }
 
 // NewRunCommand creates a new run command
-func NewRunCommand(cfg *config.Config) *RunCommand {
+func NewRunCommand(cfg *config.Config, opts DiscoverOptions) *RunCommand {
 	return &RunCommand{
-		config: cfg,
+		config:  cfg,
+		options: opts,
 	}
 }
 
-// Execute runs the complete workflow sequentially
-func (cmd *RunCommand) Execute(cobraCmd *cobra.Command, args []string) error {
+// Execute runs the complete workflow sequentially.
+// Cancelling ctx (Ctrl+C) stops the run and saves a checkpoint manifest with the partial results.
+func (cmd *RunCommand) Execute(ctx context.Context) error {
 	logger := logging.GetLogger()
 
 	logger.Info("🚀 Starting KPMG DB Solver - Complete Workflow")
@@ -38,29 +38,31 @@ func (cmd *RunCommand) Execute(cobraCmd *cobra.Command, args []string) error {
 	logger.Info("📡 Connecting to Canvus Server: %s", cmd.config.CanvusServer.URL)
 	logger.Info("📁 Scanning assets folder: %s", cmd.config.Paths.AssetsFolder)
 
-	// Create Canvus session
-	ctx := context.Background()
-	var session *canvussdk.Session
-	if cmd.config.CanvusServer.InsecureTLS {
-		session = canvussdk.NewSession(cmd.config.GetCanvusAPIURL(), canvussdk.WithInsecureTLS())
-	} else {
-		session = canvussdk.NewSession(cmd.config.GetCanvusAPIURL())
+	discoverCmd := NewDiscoverCommand(cmd.config, cmd.options)
+
+	// Check the backup settings before the long discovery
+	searcher, err := discoverCmd.newSearcher()
+	if err != nil {
+		return fmt.Errorf("invalid backup settings: %w", err)
 	}
 
+	// Create Canvus session
+	session := discoverCmd.newSession()
+	defer discoverCmd.rateLimiter.Stop()
+
 	// Authenticate
 	logger.Info("🔐 Authenticating with Canvus Server...")
-	err := session.Login(ctx, cmd.config.CanvusServer.Username, cmd.config.CanvusServer.Password)
+	err = session.Login(ctx, cmd.config.CanvusServer.Username, cmd.config.CanvusServer.Password)
 	if err != nil {
 		logger.Error("Authentication failed: %v", err)
 		return fmt.Errorf("authentication failed: %w", err)
 	}
-	defer session.Logout(ctx)
+	defer logout(session)
 
 	// Discover assets
-	discoveryResult, err := canvus.DiscoverAllAssets(session, cmd.config.Performance.MaxConcurrentAPI)
+	discoveryResult, err := discoverCmd.discoverAssets(ctx, session)
 	if err != nil {
-		logger.Error("Asset discovery failed: %v", err)
-		return fmt.Errorf("asset discovery failed: %w", err)
+		return err
 	}
 
 	logger.Info("📈 Found %d canvases with %d total media assets",
@@ -81,7 +83,11 @@ func (cmd *RunCommand) Execute(cobraCmd *cobra.Command, args []string) error {
 	}
 
 	// Scan filesystem
-	scanResult, err := filesystem.ScanAssetsFolder(cmd.config.Paths.AssetsFolder)
+	scanResult, err := filesystem.ScanAssetsFolder(ctx, cmd.config.Paths.AssetsFolder)
+	if ctx.Err() != nil {
+		discoverCmd.saveCheckpoint(manifest.StageScan, discoveryResult, nil, nil)
+		return fmt.Errorf("filesystem scan interrupted: %w", err)
+	}
 	if err != nil {
 		logger.Error("Filesystem scan failed: %v", err)
 		return fmt.Errorf("filesystem scan failed: %w", err)
@@ -90,38 +96,49 @@ func (cmd *RunCommand) Execute(cobraCmd *cobra.Command, args []string) error {
 	logger.Info("📂 Found %d files in assets folder (%.2f MB total)",
 		len(scanResult.Files), float64(scanResult.TotalSize)/(1024*1024))
 
+	// Work out the asset hash algorithm and check existing files against it
+	if err := discoverCmd.checkContent(ctx, scanResult); err != nil {
+		discoverCmd.saveCheckpoint(manifest.StageScan, discoveryResult, nil, nil)
+		return err
+	}
+
 	// Find missing assets
 	missingAssets := filesystem.FindMissingAssets(assetHashes, scanResult)
 	logger.Info("❌ Missing assets: %d", len(missingAssets))
 
 	if len(missingAssets) == 0 {
+		discoverCmd.saveManifest(discoveryResult, scanResult, missingAssets, nil)
 		logger.Info("")
 		logger.Info("✅ No missing assets found! All assets are present.")
 		logger.Info("🎉 Workflow completed successfully!")
+		discoverCmd.finishJournal(discoveryResult)
 		return nil
 	}
 
 	// Step 3: Backup Search
 	logger.Info("")
-	logger.Info("🔍 Step 3: Searching for missing assets in backup folder...")
+	logger.Info("🔍 Step 3: Searching recovery sources for missing assets...")
 
-	searcher := backup.NewSearcher(cmd.config.Paths.BackupRootFolder)
-	backupSearchResult, err := searcher.SearchForAssets(missingAssets)
+	backupSearchResult, err := discoverCmd.findCandidates(ctx, searcher, session, discoveryResult, missingAssets)
+	if ctx.Err() != nil {
+		discoverCmd.saveCheckpoint(manifest.StageBackupSearch, discoveryResult, scanResult, missingAssets)
+		return err
+	}
 	if err != nil {
 		logger.Error("Backup search failed: %v", err)
 		return fmt.Errorf("backup search failed: %w", err)
 	}
 
-	// Sort backup files by modification time (newest first)
-	searcher.SortBackupFiles(backupSearchResult)
+	// Persist the run so report and restore can work without querying the server again
+	discoverCmd.saveManifest(discoveryResult, scanResult, missingAssets, backupSearchResult)
 
-	// Step 4: Asset Discovery Summary (restoration disabled in non-admin version)
+	// Step 4: Asset Discovery Summary (restoration is a separate step)
 	if len(backupSearchResult.FoundFiles) > 0 {
 		logger.Info("")
 		logger.Info("💾 Step 4: Asset Discovery Summary...")
-		logger.Info("💾 Found %d missing assets in backup folder", len(backupSearchResult.FoundFiles))
+		logger.Info("💾 Found %d missing assets in recovery sources", len(backupSearchResult.FoundFiles))
 		logger.Info("📋 Asset locations will be included in the detailed report")
-		logger.Info("⚠️  Note: Asset restoration requires administrator privileges and is disabled in this version")
+		logger.Info("⚠️  Note: Run the restore command (requires write access to the assets folder) to copy them back")
 	} else {
 		logger.Info("")
 		logger.Info("❌ Step 4: No backup assets found")
@@ -131,23 +148,11 @@ func (cmd *RunCommand) Execute(cobraCmd *cobra.Command, args []string) error {
 	logger.Info("")
 	logger.Info("📋 Step 5: Generating reports...")
 
-	// Create missing assets map for quick lookup
-	missingMap := make(map[string]bool)
-	for _, hash := range missingAssets {
-		missingMap[hash] = true
-	}
-
-	// Filter unique assets to only include missing ones
-	var missingAssetInfos []canvus.AssetInfo
-	for _, asset := range uniqueAssets {
-		if missingMap[asset.Hash] {
-			missingAssetInfos = append(missingAssetInfos, asset)
-		}
-	}
+	// Classify every asset by filesystem and server state
+	classification := report.ClassifyAssets(discoveryResult.Assets, scanResult, discoveryResult.ServerValidation)
 
 	// Generate reports
-	discoverCmd := NewDiscoverCommand(cmd.config)
-	err = discoverCmd.generateReports(discoveryResult, missingAssets, missingAssetInfos, backupSearchResult)
+	err = discoverCmd.generateReports(discoveryResult, missingAssets, backupSearchResult, classification)
 	if err != nil {
 		logger.Error("Report generation failed: %v", err)
 		return fmt.Errorf("report generation failed: %w", err)
@@ -158,6 +163,9 @@ func (cmd *RunCommand) Execute(cobraCmd *cobra.Command, args []string) error {
 	logger.Info("📊 Step 6: Workflow Summary")
 	logger.Info("========================================")
 	logger.Info("📈 Total canvases: %d", len(discoveryResult.Canvases))
+	if discoveryResult.RateLimit != nil {
+		logger.Info("🚦 API rate: %s", describeRateLimit(discoveryResult.RateLimit))
+	}
 	logger.Info("🔗 Total unique assets: %d", len(uniqueAssets))
 	logger.Info("📂 Local assets found: %d", len(scanResult.Files))
 	logger.Info("❌ Missing assets: %d", len(missingAssets))
@@ -171,11 +179,20 @@ func (cmd *RunCommand) Execute(cobraCmd *cobra.Command, args []string) error {
 		logger.Info("🔍 Server validation:")
 		logger.Info("   ✅ Assets exist on server: %d", discoveryResult.ServerValidation.ExistingAssets)
 		logger.Info("   ❌ Assets missing from server: %d", discoveryResult.ServerValidation.MissingAssets)
+		logger.Info("   ⚠️  Assets that could not be validated: %d", discoveryResult.ServerValidation.ErrorAssets)
+		logger.Info("🧭 Asset classification:")
+		for _, class := range report.AllClasses {
+			if count := classification.Counts[class]; count > 0 {
+				logger.Info("   %s: %d (%s)", class.Description(), count, class.RecommendedAction())
+			}
+		}
 	}
 
 	logger.Info("")
 	logger.Info("🎉 Complete workflow finished successfully!")
-	logger.Info("📄 Reports generated: missing_assets_report.txt, missing_assets.csv")
+	logger.Info("📄 Reports generated: %s, %s, %s, %s", report.DetailedReportFilename, report.CSVReportFilename, report.ClassificationFilename, manifest.Filename)
+
+	discoverCmd.finishJournal(discoveryResult)
 
 	return nil
 }
@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
 	"github.com/spf13/viper"
 )
 
@@ -15,6 +19,11 @@ type Config struct {
 	Paths        PathsConfig        `mapstructure:"paths"`
 	Logging      LoggingConfig      `mapstructure:"logging"`
 	Performance  PerformanceConfig  `mapstructure:"performance"`
+	Backup       BackupConfig       `mapstructure:"backup"`
+	Assets       AssetsConfig       `mapstructure:"assets"`
+	Harvest      HarvestConfig      `mapstructure:"harvest"`
+	Recovery     RecoveryConfig     `mapstructure:"recovery"`
+	Replica      ReplicaConfig      `mapstructure:"replica"`
 }
 
 // CanvusServerConfig contains Canvus Server connection settings
@@ -28,11 +37,57 @@ type CanvusServerConfig struct {
 
 // PathsConfig contains file system paths
 type PathsConfig struct {
-	AssetsFolder     string `mapstructure:"assets_folder"`
-	BackupRootFolder string `mapstructure:"backup_root_folder"`
-	OutputFolder     string `mapstructure:"output_folder"`
+	AssetsFolder     string             `mapstructure:"assets_folder"`
+	BackupRootFolder string             `mapstructure:"backup_root_folder"` // Used when no backup_roots are configured
+	BackupRoots      []BackupRootConfig `mapstructure:"backup_roots"`
+	OutputFolder     string             `mapstructure:"output_folder"`
 }
 
+// BackupRootConfig is one folder containing backup generations (local folder, NAS mount, archive volume)
+type BackupRootConfig struct {
+	Path           string   `mapstructure:"path"`
+	Label          string   `mapstructure:"label"`           // Shown in reports (default: the folder name)
+	Priority       int      `mapstructure:"priority"`        // Lower numbers are preferred when a file is in several roots
+	FolderPatterns []string `mapstructure:"folder_patterns"` // Overrides backup.folder_patterns for this root
+}
+
+// BackupConfig controls which backup generations are searched
+type BackupConfig struct {
+	FolderPatterns []string `mapstructure:"folder_patterns"` // Regular expressions for backup folder names, with optional epoch/date/version groups
+	Since          string   `mapstructure:"since"`           // Only search backups taken on or after this date (YYYY-MM-DD)
+	Until          string   `mapstructure:"until"`           // Only search backups taken on or before this date (YYYY-MM-DD)
+	Versions       []string `mapstructure:"versions"`        // Only search backups written by these Canvus versions (e.g. 3.3 or 3.3.0)
+}
+
+// AssetsConfig controls how asset file contents are checked against their hashes
+type AssetsConfig struct {
+	HashAlgorithm string `mapstructure:"hash_algorithm"` // Digest used for asset file names, e.g. sha256 or sha256/32 (default: detected from the assets folder)
+	VerifyContent bool   `mapstructure:"verify_content"` // Hash every existing asset during discovery to find corrupt files
+}
+
+// HarvestConfig lists folders searched by content for missing assets (user shares, client caches)
+type HarvestConfig struct {
+	Directories   []string `mapstructure:"directories"`      // Folders whose files are hashed and matched against missing hashes
+	MaxFileSizeMB int      `mapstructure:"max_file_size_mb"` // Skip files larger than this (0 = no limit)
+}
+
+// RecoveryConfig selects where missing assets are recovered from
+type RecoveryConfig struct {
+	Sources []string `mapstructure:"sources"` // Recovery sources in priority order: backup, archive, harvest, canvus-api
+}
+
+// ReplicaConfig is a secondary Canvus Server that still holds assets the main server lost,
+// such as a staging server restored from an older snapshot (used by the recover command)
+type ReplicaConfig struct {
+	URL         string `mapstructure:"url"`
+	Username    string `mapstructure:"username"`
+	Password    string `mapstructure:"password"` // Prompted for when empty
+	InsecureTLS bool   `mapstructure:"insecure_tls"`
+}
+
+// RecoverySources are the recovery source names accepted in recovery.sources
+var RecoverySources = []string{"backup", "archive", "harvest", "canvus-api"}
+
 // LoggingConfig contains logging settings
 type LoggingConfig struct {
 	Level      string `mapstructure:"level"`       // debug, info, warn, error
@@ -47,6 +102,8 @@ type PerformanceConfig struct {
 	MaxConcurrentFiles  int `mapstructure:"max_concurrent_files"`
 	APIRequestTimeout   int `mapstructure:"api_request_timeout"`   // seconds
 	FileOperationTimeout int `mapstructure:"file_operation_timeout"` // seconds
+	RequestsPerSecond    int `mapstructure:"requests_per_second"`     // Starting API request rate
+	MinRequestsPerSecond int `mapstructure:"min_requests_per_second"` // Floor the rate backs off to on 429/5xx/slow responses
 }
 
 // DefaultConfig returns a configuration with default values
@@ -75,6 +132,11 @@ func DefaultConfig() *Config {
 			MaxConcurrentFiles:   20,
 			APIRequestTimeout:    30,
 			FileOperationTimeout: 60,
+			RequestsPerSecond:    100,
+			MinRequestsPerSecond: 25,
+		},
+		Recovery: RecoveryConfig{
+			Sources: []string{"backup", "archive"},
 		},
 	}
 }
@@ -174,6 +236,17 @@ func (c *Config) preserveDefaults() {
 	if c.Performance.FileOperationTimeout == 0 {
 		c.Performance.FileOperationTimeout = defaults.Performance.FileOperationTimeout
 	}
+	if c.Performance.RequestsPerSecond == 0 {
+		c.Performance.RequestsPerSecond = defaults.Performance.RequestsPerSecond
+	}
+	if c.Performance.MinRequestsPerSecond == 0 {
+		c.Performance.MinRequestsPerSecond = defaults.Performance.MinRequestsPerSecond
+	}
+
+	// Preserve default recovery sources if none are listed
+	if len(c.Recovery.Sources) == 0 {
+		c.Recovery.Sources = defaults.Recovery.Sources
+	}
 }
 
 // ValidateConfig validates the configuration
@@ -194,7 +267,7 @@ func (c *Config) Validate() error {
 	if c.Paths.AssetsFolder == "" {
 		return fmt.Errorf("assets folder path is required")
 	}
-	if c.Paths.BackupRootFolder == "" {
+	if c.Paths.BackupRootFolder == "" && len(c.Paths.BackupRoots) == 0 {
 		return fmt.Errorf("backup root folder path is required")
 	}
 
@@ -202,8 +275,8 @@ func (c *Config) Validate() error {
 	if !pathExists(c.Paths.AssetsFolder) {
 		return fmt.Errorf("assets folder does not exist or is not accessible: %s", c.Paths.AssetsFolder)
 	}
-	if !pathExists(c.Paths.BackupRootFolder) {
-		return fmt.Errorf("backup root folder does not exist or is not accessible: %s", c.Paths.BackupRootFolder)
+	if err := c.validateBackupRoots(); err != nil {
+		return err
 	}
 
 	// Create output folder if it doesn't exist
@@ -225,10 +298,113 @@ func (c *Config) Validate() error {
 	if c.Performance.MaxConcurrentFiles < 1 {
 		return fmt.Errorf("max concurrent file operations must be at least 1")
 	}
+	if c.Performance.MinRequestsPerSecond < 1 || c.Performance.MinRequestsPerSecond > c.Performance.RequestsPerSecond {
+		return fmt.Errorf("min requests per second must be between 1 and requests per second (%d)", c.Performance.RequestsPerSecond)
+	}
+
+	// Validate backup generation settings
+	for _, pattern := range c.Backup.FolderPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid backup folder pattern %q: %w", pattern, err)
+		}
+	}
+	for _, date := range []string{c.Backup.Since, c.Backup.Until} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return fmt.Errorf("invalid backup date %q (expected YYYY-MM-DD)", date)
+		}
+	}
+
+	// Validate harvest settings
+	for _, directory := range c.Harvest.Directories {
+		if directory == "" {
+			return fmt.Errorf("harvest directories must not be empty")
+		}
+	}
+	if c.Harvest.MaxFileSizeMB < 0 {
+		return fmt.Errorf("harvest max file size must not be negative")
+	}
+
+	// Validate recovery sources
+	seen := make(map[string]bool)
+	for _, source := range c.Recovery.Sources {
+		if !contains(RecoverySources, source) {
+			return fmt.Errorf("invalid recovery source: %s (must be one of: %s)",
+				source, strings.Join(RecoverySources, ", "))
+		}
+		if seen[source] {
+			return fmt.Errorf("recovery source %s is listed twice", source)
+		}
+		seen[source] = true
+	}
+
+	// Validate asset content settings
+	if c.Assets.HashAlgorithm != "" {
+		if _, err := digest.Parse(c.Assets.HashAlgorithm); err != nil {
+			return fmt.Errorf("invalid assets hash algorithm: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateBackupRoots checks the backup roots. Individual roots may be offline (e.g. a NAS),
+// but at least one must be accessible and labels must tell them apart in reports.
+func (c *Config) validateBackupRoots() error {
+	roots := c.GetBackupRoots()
+
+	labels := make(map[string]bool)
+	accessible := 0
+	for _, root := range roots {
+		if root.Path == "" {
+			return fmt.Errorf("backup root %q has no path", root.Label)
+		}
+		if labels[root.Label] {
+			return fmt.Errorf("backup root label %q is used more than once", root.Label)
+		}
+		labels[root.Label] = true
+
+		for _, pattern := range root.FolderPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid folder pattern %q for backup root %q: %w", pattern, root.Label, err)
+			}
+		}
+
+		if pathExists(root.Path) {
+			accessible++
+		}
+	}
+
+	if accessible == 0 {
+		if len(roots) == 1 {
+			return fmt.Errorf("backup root folder does not exist or is not accessible: %s", roots[0].Path)
+		}
+		return fmt.Errorf("none of the %d backup roots exist or are accessible", len(roots))
+	}
 
 	return nil
 }
 
+// GetBackupRoots returns the configured backup roots, or the single backup root folder
+// if none are configured. Roots without a label are labelled as by backup.LabelRoots.
+func (c *Config) GetBackupRoots() []BackupRootConfig {
+	roots := c.Paths.BackupRoots
+	if len(roots) == 0 {
+		roots = []BackupRootConfig{{Path: c.Paths.BackupRootFolder}}
+	}
+
+	backupRoots := make([]backup.BackupRoot, len(roots))
+	for i, root := range roots {
+		backupRoots[i] = backup.BackupRoot{Path: root.Path, Label: root.Label}
+	}
+
+	labelled := make([]BackupRootConfig, len(roots))
+	for i, root := range backup.LabelRoots(backupRoots) {
+		labelled[i] = roots[i]
+		labelled[i].Label = root.Label
+	}
+	return labelled
+}
+
 // SaveConfig saves the configuration to a file
 func (c *Config) SaveConfig(filename string) error {
 	// Ensure directory exists
@@ -242,6 +418,11 @@ func (c *Config) SaveConfig(filename string) error {
 	viper.Set("paths", c.Paths)
 	viper.Set("logging", c.Logging)
 	viper.Set("performance", c.Performance)
+	viper.Set("backup", c.Backup)
+	viper.Set("assets", c.Assets)
+	viper.Set("harvest", c.Harvest)
+	viper.Set("recovery", c.Recovery)
+	viper.Set("replica", c.Replica)
 
 	// Write to file
 	return viper.WriteConfigAs(filename)
@@ -249,7 +430,12 @@ func (c *Config) SaveConfig(filename string) error {
 
 // GetCanvusAPIURL returns the full API URL
 func (c *Config) GetCanvusAPIURL() string {
-	url := strings.TrimSuffix(c.CanvusServer.URL, "/")
+	return APIURL(c.CanvusServer.URL)
+}
+
+// APIURL returns the API base URL of a Canvus Server URL
+func APIURL(serverURL string) string {
+	url := strings.TrimSuffix(serverURL, "/")
 	if !strings.HasSuffix(url, "/api/v1") {
 		url += "/api/v1"
 	}
@@ -14,7 +14,8 @@ import (
 
 // InteractivePrompts handles user input for configuration
 type InteractivePrompts struct {
-	reader *bufio.Reader
+	reader  *bufio.Reader
+	signals chan os.Signal
 }
 
 // NewInteractivePrompts creates a new interactive prompts instance
@@ -31,11 +32,13 @@ func NewInteractivePrompts() *InteractivePrompts {
 
 // setupSignalHandler sets up a signal handler to restore terminal state on interrupt
 func (p *InteractivePrompts) setupSignalHandler() {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	p.signals = make(chan os.Signal, 1)
+	signal.Notify(p.signals, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-c
+		if _, ok := <-p.signals; !ok {
+			return // Closed: prompting is over
+		}
 		// Restore terminal state
 		if term.IsTerminal(int(syscall.Stdin)) {
 			fmt.Print("\n")
@@ -44,6 +47,13 @@ func (p *InteractivePrompts) setupSignalHandler() {
 	}()
 }
 
+// Close removes the interrupt handler once prompting is done, so Ctrl+C during the
+// following work cancels it gracefully instead of exiting immediately
+func (p *InteractivePrompts) Close() {
+	signal.Stop(p.signals)
+	close(p.signals)
+}
+
 // PromptForConfig prompts the user for all required configuration
 func (p *InteractivePrompts) PromptForConfig() (*Config, error) {
 	fmt.Println("🔧 KPMG DB Solver Configuration")
@@ -296,6 +306,11 @@ func (p *InteractivePrompts) PromptForSaveConfig(config *Config) error {
 	return nil
 }
 
+// PromptForPassword asks for a password without echoing it
+func (p *InteractivePrompts) PromptForPassword(prompt string) string {
+	return p.promptPassword(prompt)
+}
+
 // PromptForConfirmation asks for user confirmation before proceeding
 func (p *InteractivePrompts) PromptForConfirmation(message string) bool {
 	return p.promptBool(message, false)
@@ -0,0 +1,134 @@
+// Package digest knows the content digests that may have produced Canvus asset file names
+package digest
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// Algorithm is a content digest, optionally truncated to its first Length hex characters
+type Algorithm struct {
+	Name   string           // Base digest name, e.g. "sha256"
+	New    func() hash.Hash // Creates a new hasher
+	Length int              // Hex characters kept in the file name
+}
+
+// standard lists the digests tried when the algorithm is unknown, most likely first
+var standard = []struct {
+	name string
+	new  func() hash.Hash
+}{
+	{"sha256", sha256.New},
+	{"sha1", sha1.New},
+	{"md5", md5.New},
+	{"sha512", sha512.New},
+}
+
+// String returns the algorithm name, with the kept length for truncated digests (e.g. "sha256/32")
+func (a Algorithm) String() string {
+	if a.Length == a.New().Size()*2 {
+		return a.Name
+	}
+	return fmt.Sprintf("%s/%d", a.Name, a.Length)
+}
+
+// Truncated reports whether only part of the digest is kept
+func (a Algorithm) Truncated() bool {
+	return a.Length < a.New().Size()*2
+}
+
+// Matches reports whether a full hex digest produced by this algorithm matches an asset hash
+func (a Algorithm) Matches(sum, assetHash string) bool {
+	return len(assetHash) == a.Length && len(sum) >= a.Length && strings.EqualFold(sum[:a.Length], assetHash)
+}
+
+// Sum returns the full hex digest of r
+func (a Algorithm) Sum(r io.Reader) (string, error) {
+	h := a.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyFile reports whether the content of the file at path hashes to assetHash
+func (a Algorithm) VerifyFile(path, assetHash string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	sum, err := a.Sum(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return a.Matches(sum, assetHash), nil
+}
+
+// Candidates returns every standard digest that could have produced an asset hash of the given
+// length: digests of exactly that length, and longer digests truncated to it
+func Candidates(hashLength int) []Algorithm {
+	candidates := make([]Algorithm, 0)
+	for _, s := range standard {
+		if s.new().Size()*2 >= hashLength && hashLength > 0 {
+			candidates = append(candidates, Algorithm{Name: s.name, New: s.new, Length: hashLength})
+		}
+	}
+	return candidates
+}
+
+// Parse returns the algorithm named like String does, e.g. "sha1" or "sha256/32"
+func Parse(name string) (Algorithm, error) {
+	base, length := name, 0
+	if i := strings.Index(name, "/"); i >= 0 {
+		base = name[:i]
+		if _, err := fmt.Sscanf(name[i+1:], "%d", &length); err != nil {
+			return Algorithm{}, fmt.Errorf("invalid digest length in %q", name)
+		}
+	}
+
+	for _, s := range standard {
+		if s.name != strings.ToLower(base) {
+			continue
+		}
+		full := s.new().Size() * 2
+		if length == 0 {
+			length = full
+		}
+		if length < 1 || length > full {
+			return Algorithm{}, fmt.Errorf("digest %s has %d hex characters, cannot keep %d", s.name, full, length)
+		}
+		return Algorithm{Name: s.name, New: s.new, Length: length}, nil
+	}
+
+	return Algorithm{}, fmt.Errorf("unknown digest %q (supported: sha256, sha1, md5, sha512)", base)
+}
+
+// MultiSum computes several digests of r in one pass and returns their full hex sums, in order
+func MultiSum(r io.Reader, algorithms []Algorithm) ([]string, error) {
+	hashers := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, len(algorithms))
+	for i, algorithm := range algorithms {
+		hashers[i] = algorithm.New()
+		writers[i] = hashers[i]
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+
+	sums := make([]string, len(hashers))
+	for i, h := range hashers {
+		sums[i] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
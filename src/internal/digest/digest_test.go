@@ -0,0 +1,155 @@
+package digest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Digests of "abc"
+const (
+	abcSHA256 = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	abcSHA1   = "a9993e364706816aba3e25717850c26c9cd0d89d"
+	abcMD5    = "900150983cd24fb0d6963f7d28e17f72"
+	abcSHA512 = "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"
+)
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		length int
+		want   []string
+	}{
+		{0, nil},
+		{16, []string{"sha256/16", "sha1/16", "md5/16", "sha512/16"}},
+		{32, []string{"sha256/32", "sha1/32", "md5", "sha512/32"}},
+		{40, []string{"sha256/40", "sha1", "sha512/40"}},
+		{64, []string{"sha256", "sha512/64"}},
+		{128, []string{"sha512"}},
+		{129, nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, algorithm := range Candidates(tt.length) {
+			if algorithm.Length != tt.length {
+				t.Errorf("length %d: %s keeps %d characters", tt.length, algorithm, algorithm.Length)
+			}
+			got = append(got, algorithm.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("length %d: expected %v, got %v", tt.length, tt.want, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		want      string // String() of the parsed algorithm
+		truncated bool
+		err       string
+	}{
+		{name: "sha256", want: "sha256"},
+		{name: "SHA1", want: "sha1"},
+		{name: "md5", want: "md5"},
+		{name: "sha512", want: "sha512"},
+		{name: "sha256/32", want: "sha256/32", truncated: true},
+		{name: "sha512/64", want: "sha512/64", truncated: true},
+		{name: "sha1/40", want: "sha1"},
+		{name: "sha1/41", err: "cannot keep 41"},
+		{name: "md5/x", err: "invalid digest length"},
+		{name: "sha3", err: "unknown digest"},
+	}
+
+	for _, tt := range tests {
+		algorithm, err := Parse(tt.name)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if algorithm.String() != tt.want || algorithm.Truncated() != tt.truncated {
+			t.Errorf("%s: expected %s (truncated=%v), got %s (truncated=%v)", tt.name, tt.want, tt.truncated, algorithm, algorithm.Truncated())
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		assetHash string
+		want      bool
+	}{
+		{"sha256", abcSHA256, true},
+		{"sha256", strings.ToUpper(abcSHA256), true},
+		{"sha1", abcSHA1, true},
+		{"md5", abcMD5, true},
+		{"md5", strings.ToUpper(abcMD5), true},
+		{"sha512", abcSHA512, true},
+		{"sha256/32", abcSHA256[:32], true},
+		{"sha256/32", strings.ToUpper(abcSHA256[:32]), true},
+		{"sha512/64", abcSHA512[:64], true},
+		{"sha1/16", abcSHA1[:16], true},
+		{"sha256/32", abcSHA256[32:], false}, // The wrong half
+		{"sha256/32", abcSHA256, false},      // Full digest for a truncated algorithm
+		{"sha256", abcSHA256[:32], false},    // Truncated digest for a full algorithm
+		{"sha256", abcSHA1, false},
+		{"md5", abcSHA256[:32], false},
+	}
+
+	for _, tt := range tests {
+		algorithm, err := Parse(tt.algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum, err := algorithm.Sum(strings.NewReader("abc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := algorithm.Matches(sum, tt.assetHash); got != tt.want {
+			t.Errorf("%s matching %s: expected %v, got %v", tt.algorithm, tt.assetHash, tt.want, got)
+		}
+	}
+}
+
+func TestMultiSum(t *testing.T) {
+	algorithms := Candidates(32)
+	sums, err := MultiSum(strings.NewReader("abc"), algorithms)
+	if err != nil {
+		t.Fatalf("MultiSum: %v", err)
+	}
+
+	// Full sums, in the order of the algorithms, whatever length is kept
+	want := []string{abcSHA256, abcSHA1, abcMD5, abcSHA512}
+	if !reflect.DeepEqual(sums, want) {
+		t.Errorf("expected %v, got %v", want, sums)
+	}
+	for i, algorithm := range algorithms {
+		if !algorithm.Matches(sums[i], want[i][:32]) {
+			t.Errorf("%s: expected its sum to match the truncated digest", algorithm)
+		}
+	}
+}
+
+// failingReader fails after returning some data
+type failingReader struct{ sent bool }
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.sent {
+		return 0, errors.New("disk error")
+	}
+	r.sent = true
+	return copy(p, "abc"), nil
+}
+
+func TestMultiSumReturnsReadErrors(t *testing.T) {
+	if _, err := MultiSum(&failingReader{}, Candidates(64)); err == nil || err.Error() != "disk error" {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/digest"
+)
+
+// DefaultHashSampleSize is how many asset files are hashed to detect the file name digest
+const DefaultHashSampleSize = 50
+
+// minDetectionMatches is how many sampled files must agree before a digest is trusted
+const minDetectionMatches = 3
+
+// HashDetection is the outcome of sampling the assets folder for the file name digest
+type HashDetection struct {
+	Algorithm *digest.Algorithm // nil if no digest consistently matched
+	Sampled   int               // Files hashed
+	Matched   int               // Sampled files whose content hashed to their name with Algorithm
+}
+
+// DetectHashAlgorithm hashes an evenly spread sample of files with the common digests (SHA-256, SHA-1,
+// MD5, SHA-512 and truncated variants) and returns the one that matches their file names.
+// A digest is only accepted if it matches at least 90% of the sample and at least three files
+// (or every file, when fewer are sampled), so a few coincidences or copied files cannot decide it.
+func DetectHashAlgorithm(ctx context.Context, files []FileInfo, sampleSize int) (*HashDetection, error) {
+	if sampleSize < 1 {
+		sampleSize = DefaultHashSampleSize
+	}
+
+	detection := &HashDetection{}
+	matches := make(map[string]int)
+	algorithms := make(map[string]digest.Algorithm)
+
+	for _, file := range sampleFiles(files, sampleSize) {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("hash detection interrupted: %w", err)
+		}
+
+		candidates := digest.Candidates(len(file.Hash))
+		sums, err := sumFile(file.Path, candidates)
+		if err != nil {
+			continue // Unreadable files say nothing about the digest
+		}
+		detection.Sampled++
+
+		for i, algorithm := range candidates {
+			if algorithm.Matches(sums[i], file.Hash) {
+				matches[algorithm.String()]++
+				algorithms[algorithm.String()] = algorithm
+			}
+		}
+	}
+
+	// Prefer the digest with most matches; ties go to the name that sorts first for a stable result
+	best := ""
+	for name, count := range matches {
+		if count > matches[best] || count == matches[best] && name < best {
+			best = name
+		}
+	}
+	if best == "" {
+		return detection, nil
+	}
+
+	matched := matches[best]
+	required := minDetectionMatches
+	if detection.Sampled < required {
+		required = detection.Sampled
+	}
+	if matched >= required && matched*10 >= detection.Sampled*9 {
+		algorithm := algorithms[best]
+		detection.Algorithm = &algorithm
+		detection.Matched = matched
+	}
+
+	return detection, nil
+}
+
+// sampleFiles picks up to n non-empty files spread evenly over the list
+func sampleFiles(files []FileInfo, n int) []FileInfo {
+	nonEmpty := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		if file.Size > 0 {
+			nonEmpty = append(nonEmpty, file)
+		}
+	}
+	if len(nonEmpty) <= n {
+		return nonEmpty
+	}
+
+	sample := make([]FileInfo, n)
+	for i := range sample {
+		sample[i] = nonEmpty[i*len(nonEmpty)/n]
+	}
+	return sample
+}
+
+// sumFile computes several digests of a file in one pass
+func sumFile(path string, algorithms []digest.Algorithm) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return digest.MultiSum(file, algorithms)
+}
+
+// VerifyContent hashes every scanned file with the detected digest and sets ContentVerified on the
+// files whose content matches their name. The hashes of files that do not match (or could not be read)
+// are recorded in ContentMismatches. The scan result is left unchanged if ctx is cancelled.
+func VerifyContent(ctx context.Context, scanResult *ScanResult, algorithm digest.Algorithm, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	verified := make([]bool, len(scanResult.Files))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if ctx.Err() != nil {
+					continue // Drain the queue without reading
+				}
+				file := scanResult.Files[i]
+				ok, err := algorithm.VerifyFile(file.Path, file.Hash)
+				verified[i] = ok && err == nil
+			}
+		}()
+	}
+
+feed:
+	for i := range scanResult.Files {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("content verification interrupted: %w", err)
+	}
+
+	scanResult.HashAlgorithm = algorithm.String()
+	scanResult.ContentMismatches = make([]string, 0)
+	for i := range scanResult.Files {
+		scanResult.Files[i].ContentVerified = verified[i]
+		if !verified[i] {
+			scanResult.ContentMismatches = append(scanResult.ContentMismatches, scanResult.Files[i].Hash)
+		}
+	}
+	sort.Strings(scanResult.ContentMismatches)
+	scanResult.BuildHashMap()
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeAsset writes content to dir under the given name and returns its FileInfo
+func writeAsset(t *testing.T, dir, hash, ext, content string) FileInfo {
+	t.Helper()
+	path := filepath.Join(dir, hash+ext)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return FileInfo{Path: path, Hash: hash, Filename: hash + ext, Size: int64(len(content)), RelativePath: hash + ext}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDetectHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		name     string
+		hash     func(content string) string
+		expected string
+	}{
+		{"sha256", sha256Hex, "sha256"},
+		{"truncated sha256", func(content string) string { return sha256Hex(content)[:32] }, "sha256/32"},
+		{"md5", func(content string) string {
+			sum := md5.Sum([]byte(content))
+			return hex.EncodeToString(sum[:])
+		}, "md5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := make([]FileInfo, 0)
+			for i := 0; i < 10; i++ {
+				content := fmt.Sprintf("asset %d", i)
+				files = append(files, writeAsset(t, dir, tt.hash(content), ".png", content))
+			}
+			// A file copied in by hand under the wrong name must not prevent detection
+			files = append(files, writeAsset(t, dir, sha256Hex("other")[:len(files[0].Hash)], ".png", "renamed"))
+
+			detection, err := DetectHashAlgorithm(context.Background(), files, 0)
+			if err != nil {
+				t.Fatalf("DetectHashAlgorithm: %v", err)
+			}
+			if detection.Algorithm == nil || detection.Algorithm.String() != tt.expected {
+				t.Fatalf("expected %s, got %+v", tt.expected, detection)
+			}
+			if detection.Sampled != 11 || detection.Matched != 10 {
+				t.Errorf("expected 10 of 11 sampled files to match, got %d of %d", detection.Matched, detection.Sampled)
+			}
+		})
+	}
+}
+
+func TestDetectHashAlgorithmNeedsConsistentMatches(t *testing.T) {
+	dir := t.TempDir()
+	files := []FileInfo{
+		writeAsset(t, dir, sha256Hex("a"), ".png", "a"),
+		writeAsset(t, dir, "0123456789abcdef0123456789abcdef", ".png", "b"),
+		writeAsset(t, dir, "fedcba9876543210fedcba9876543210", ".png", "c"),
+	}
+
+	detection, err := DetectHashAlgorithm(context.Background(), files, 0)
+	if err != nil {
+		t.Fatalf("DetectHashAlgorithm: %v", err)
+	}
+	if detection.Algorithm != nil {
+		t.Errorf("expected no algorithm from a single match, got %s", detection.Algorithm)
+	}
+}
+
+func TestVerifyContent(t *testing.T) {
+	dir := t.TempDir()
+	good := writeAsset(t, dir, sha256Hex("intact"), ".jpg", "intact")
+	bad := writeAsset(t, dir, sha256Hex("original"), ".jpg", "damaged")
+	scanResult := &ScanResult{Files: []FileInfo{good, bad}}
+
+	detection, err := DetectHashAlgorithm(context.Background(), []FileInfo{good, good, good}, 0)
+	if err != nil || detection.Algorithm == nil {
+		t.Fatalf("DetectHashAlgorithm: %+v, %v", detection, err)
+	}
+
+	if err := VerifyContent(context.Background(), scanResult, *detection.Algorithm, 2); err != nil {
+		t.Fatalf("VerifyContent: %v", err)
+	}
+	if !scanResult.HashMap[good.Hash].ContentVerified || scanResult.HashMap[bad.Hash].ContentVerified {
+		t.Errorf("unexpected verification flags: %+v", scanResult.Files)
+	}
+	if len(scanResult.ContentMismatches) != 1 || scanResult.ContentMismatches[0] != bad.Hash {
+		t.Errorf("expected %s to be reported, got %v", bad.Hash, scanResult.ContentMismatches)
+	}
+	if scanResult.HashAlgorithm != "sha256" {
+		t.Errorf("expected sha256 to be recorded, got %q", scanResult.HashAlgorithm)
+	}
+}
@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,23 +11,35 @@ import (
 
 // FileInfo represents information about a file in the assets folder
 type FileInfo struct {
-	Path         string `json:"path"`
-	Hash         string `json:"hash"`
-	Filename     string `json:"filename"`
-	Size         int64  `json:"size"`
-	RelativePath string `json:"relative_path"` // Relative path from assets root (preserves folder structure)
+	Path            string `json:"path"`
+	Hash            string `json:"hash"`
+	Filename        string `json:"filename"`
+	Size            int64  `json:"size"`
+	RelativePath    string `json:"relative_path"`              // Relative path from assets root (preserves folder structure)
+	ContentVerified bool   `json:"content_verified,omitempty"` // Content hashes to the file name with the detected digest
 }
 
 // ScanResult represents the result of filesystem scanning
 type ScanResult struct {
-	Files    []FileInfo `json:"files"`
-	HashMap  map[string]FileInfo `json:"hash_map"`
-	TotalSize int64     `json:"total_size"`
-	Error    error      `json:"error,omitempty"`
+	Files             []FileInfo          `json:"files"`
+	HashMap           map[string]FileInfo `json:"-"` // Rebuilt from Files with BuildHashMap
+	TotalSize         int64               `json:"total_size"`
+	HashAlgorithm     string              `json:"hash_algorithm,omitempty"`     // Digest used for asset file names, e.g. "sha256" (empty if unknown)
+	ContentMismatches []string            `json:"content_mismatches,omitempty"` // Hashes of files whose content does not match their name
+	Error             error               `json:"-"`
 }
 
-// ScanAssetsFolder scans the assets folder and builds a hash map
-func ScanAssetsFolder(assetsPath string) (*ScanResult, error) {
+// BuildHashMap rebuilds the hash lookup map from the file list, e.g. after loading a saved scan
+func (r *ScanResult) BuildHashMap() {
+	r.HashMap = make(map[string]FileInfo, len(r.Files))
+	for _, file := range r.Files {
+		r.HashMap[file.Hash] = file
+	}
+}
+
+// ScanAssetsFolder scans the assets folder and builds a hash map.
+// The walk stops with the context error if ctx is cancelled.
+func ScanAssetsFolder(ctx context.Context, assetsPath string) (*ScanResult, error) {
 	result := &ScanResult{
 		Files:    make([]FileInfo, 0),
 		HashMap:  make(map[string]FileInfo),
@@ -43,6 +56,9 @@ func ScanAssetsFolder(assetsPath string) (*ScanResult, error) {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		// Skip directories
 		if info.IsDir() {
@@ -125,7 +141,7 @@ func FindMissingAssets(discoveredAssets []string, scanResult *ScanResult) []stri
 }
 
 // ParallelScanAssetsFolder scans the assets folder using parallel processing
-func ParallelScanAssetsFolder(assetsPath string, numWorkers int) (*ScanResult, error) {
+func ParallelScanAssetsFolder(ctx context.Context, assetsPath string, numWorkers int) (*ScanResult, error) {
 	result := &ScanResult{
 		Files:    make([]FileInfo, 0),
 		HashMap:  make(map[string]FileInfo),
@@ -204,6 +220,9 @@ func ParallelScanAssetsFolder(assetsPath string, numWorkers int) (*ScanResult, e
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		// Send file paths to workers
 		if !info.IsDir() {
@@ -0,0 +1,143 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/filesystem"
+)
+
+// SchemaVersion is the manifest format version written by this build.
+// Bump it whenever a field is removed or changes meaning.
+//
+// Version 1 has since gained backup_roots, harvest, degraded and interrupted_stage. They are all
+// optional additions: a manifest without them loads with their zero values, and older builds ignore
+// them, so the version was not bumped. interrupted_stage is only ever set in checkpoint files.
+const SchemaVersion = 1
+
+// Filename is the default manifest file name in the output folder
+const Filename = "discovery_manifest.json"
+
+// CheckpointFilename holds the partial results of an interrupted run, so the manifest of the
+// last complete run is never overwritten with them
+const CheckpointFilename = "discovery_checkpoint.json"
+
+// Manifest is the machine-readable record of a discovery run.
+// It holds everything the report and restore commands need to work offline.
+type Manifest struct {
+	SchemaVersion    int                     `json:"schema_version"`
+	CreatedAt        time.Time               `json:"created_at"`
+	CanvusServer     string                  `json:"canvus_server"`
+	AssetsFolder     string                  `json:"assets_folder"`
+	BackupRootFolder string                  `json:"backup_root_folder"`
+	BackupRoots      []backup.BackupRoot     `json:"backup_roots,omitempty"`
+	Discovery        *canvus.DiscoveryResult `json:"discovery"`      // Canvases, assets, server validation
+	Scan             *filesystem.ScanResult  `json:"scan"`           // Files found in the assets folder
+	MissingHashes    []string                `json:"missing_hashes"` // Referenced hashes not found in the assets folder
+	Backup           *backup.SearchResult    `json:"backup,omitempty"`
+	Harvest          *backup.HarvestResult   `json:"harvest,omitempty"` // Missing assets matched by content in harvest directories
+	Degraded         *canvus.DegradedResult  `json:"degraded,omitempty"` // Lossy reconstructions from server-side mipmaps
+	Errors           []string                `json:"errors"`
+	InterruptedStage string                  `json:"interrupted_stage,omitempty"` // Stage that was cancelled; empty for a complete run
+}
+
+// New creates an empty manifest stamped with the current schema version
+func New() *Manifest {
+	return &Manifest{
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     time.Now(),
+		MissingHashes: make([]string, 0),
+		Errors:        make([]string, 0),
+	}
+}
+
+// Stages recorded in InterruptedStage
+const (
+	StageDiscovery    = "discovery"
+	StageScan         = "scan"
+	StageBackupSearch = "backup search"
+)
+
+// Complete reports whether the manifest was written by a run that finished every stage
+func (m *Manifest) Complete() bool {
+	return m.InterruptedStage == ""
+}
+
+// DefaultPath returns the manifest path inside an output folder
+func DefaultPath(outputFolder string) string {
+	return filepath.Join(outputFolder, Filename)
+}
+
+// CheckpointPath returns the checkpoint path inside an output folder
+func CheckpointPath(outputFolder string) string {
+	return filepath.Join(outputFolder, CheckpointFilename)
+}
+
+// Save writes the manifest to a JSON file.
+// The file is written to a temporary name first so a crash never leaves a half-written manifest.
+func (m *Manifest) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create manifest directory: %w", err)
+		}
+	}
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest %s: %w", tmpPath, err)
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(m); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write manifest %s: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move manifest into place: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a manifest from a JSON file and checks its schema version
+func Load(path string) (*Manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var m Manifest
+	if err := json.NewDecoder(file).Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest %s: %w", path, err)
+	}
+
+	if m.SchemaVersion < 1 || m.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("manifest %s has unsupported schema version %d (this build supports up to %d)",
+			path, m.SchemaVersion, SchemaVersion)
+	}
+
+	if m.Scan != nil {
+		m.Scan.BuildHashMap()
+	}
+	if m.Backup != nil && m.Backup.FoundFiles == nil {
+		m.Backup.FoundFiles = make(map[string][]backup.BackupFile)
+	}
+
+	return &m, nil
+}
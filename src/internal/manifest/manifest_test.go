@@ -0,0 +1,136 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/filesystem"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := DefaultPath(filepath.Join(t.TempDir(), "output"))
+
+	found := backup.BackupFile{
+		Path:         "/backups/2024-01-01/assets/aaaa.png",
+		Hash:         "aaaa",
+		Extension:    ".png",
+		ModifiedTime: time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC),
+		Size:         42,
+		Generation:   "2024-01-01",
+		Root:         "nas",
+		Verified:     true,
+	}
+
+	m := New()
+	m.CreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.CanvusServer = "https://canvus.example.com"
+	m.AssetsFolder = "/var/lib/canvus/assets"
+	m.BackupRoots = []backup.BackupRoot{{Path: "/mnt/nas", Label: "nas", Priority: 1}}
+	m.Discovery = &canvus.DiscoveryResult{
+		Assets: []canvus.AssetInfo{{Hash: "aaaa", WidgetType: "Image", CanvasID: "c1"}},
+		Errors: []string{},
+	}
+	m.Scan = &filesystem.ScanResult{
+		Files:         []filesystem.FileInfo{{Path: "/var/lib/canvus/assets/bbbb.pdf", Hash: "bbbb", Filename: "bbbb.pdf", Size: 7}},
+		TotalSize:     7,
+		HashAlgorithm: "sha256",
+	}
+	m.MissingHashes = []string{"aaaa"}
+	m.Backup = &backup.SearchResult{
+		FoundFiles:    map[string][]backup.BackupFile{"aaaa": {found}},
+		MissingHashes: []string{},
+		TotalFiles:    1,
+	}
+	m.Harvest = &backup.HarvestResult{Directories: []string{"/shares"}, Matches: map[string][]backup.BackupFile{}}
+	m.Degraded = &canvus.DegradedResult{ReviewFolder: "review", Failed: map[string]string{"cccc": "no mipmap"}}
+	m.Errors = []string{"canvas c2: timeout"}
+
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to be gone after Save, stat returned %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if loaded.SchemaVersion != SchemaVersion || !loaded.CreatedAt.Equal(m.CreatedAt) {
+		t.Errorf("unexpected header: version %d, created %v", loaded.SchemaVersion, loaded.CreatedAt)
+	}
+	if loaded.CanvusServer != m.CanvusServer || loaded.AssetsFolder != m.AssetsFolder {
+		t.Errorf("unexpected server/assets folder: %q, %q", loaded.CanvusServer, loaded.AssetsFolder)
+	}
+	if !reflect.DeepEqual(loaded.BackupRoots, m.BackupRoots) {
+		t.Errorf("backup roots changed: %+v", loaded.BackupRoots)
+	}
+	if !reflect.DeepEqual(loaded.Discovery.Assets, m.Discovery.Assets) {
+		t.Errorf("discovered assets changed: %+v", loaded.Discovery.Assets)
+	}
+	if !reflect.DeepEqual(loaded.MissingHashes, m.MissingHashes) || !reflect.DeepEqual(loaded.Errors, m.Errors) {
+		t.Errorf("unexpected missing hashes %v or errors %v", loaded.MissingHashes, loaded.Errors)
+	}
+	if got := loaded.Backup.FoundFiles["aaaa"]; len(got) != 1 || !got[0].ModifiedTime.Equal(found.ModifiedTime) || got[0].Root != "nas" || !got[0].Verified {
+		t.Errorf("backup candidates changed: %+v", got)
+	}
+	if loaded.Harvest == nil || loaded.Harvest.Directories[0] != "/shares" {
+		t.Errorf("harvest result lost: %+v", loaded.Harvest)
+	}
+	if loaded.Degraded == nil || loaded.Degraded.Failed["cccc"] != "no mipmap" {
+		t.Errorf("degraded result lost: %+v", loaded.Degraded)
+	}
+
+	// The hash map is not serialised and must be rebuilt on load
+	if file, ok := loaded.Scan.HashMap["bbbb"]; !ok || file.Size != 7 {
+		t.Errorf("expected the scan hash map to be rebuilt, got %+v", loaded.Scan.HashMap)
+	}
+	if !loaded.Complete() {
+		t.Error("expected a manifest without an interrupted stage to be complete")
+	}
+}
+
+func TestLoadMinimalVersion1Manifest(t *testing.T) {
+	// Written before backup_roots, harvest, degraded and interrupted_stage existed
+	path := filepath.Join(t.TempDir(), Filename)
+	data := `{"schema_version": 1, "created_at": "2024-01-02T03:04:05Z", "assets_folder": "/assets",
+		"backup": {"found_files": null, "missing_hashes": ["aaaa"]}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.BackupRoots != nil || loaded.Harvest != nil || loaded.Degraded != nil || !loaded.Complete() {
+		t.Errorf("expected the later fields to load as zero values, got %+v", loaded)
+	}
+	if loaded.Backup.FoundFiles == nil {
+		t.Error("expected a null found_files to load as an empty map")
+	}
+}
+
+func TestLoadRejectsUnsupportedSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []int{0, SchemaVersion + 1} {
+		path := filepath.Join(dir, Filename)
+		m := New()
+		m.SchemaVersion = version
+		if err := m.Save(path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		_, err := Load(path)
+		if err == nil || !strings.Contains(err.Error(), "unsupported schema version") {
+			t.Errorf("version %d: expected an unsupported schema version error, got %v", version, err)
+		}
+	}
+}
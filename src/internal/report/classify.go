@@ -0,0 +1,131 @@
+package report
+
+import (
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/filesystem"
+)
+
+// AssetClass is the combined state of a database-referenced asset on disk and on the server
+type AssetClass string
+
+const (
+	ClassHealthy         AssetClass = "on-disk-served"         // File present and served
+	ClassServerError     AssetClass = "on-disk-server-error"   // File present but the server cannot serve it
+	ClassServedNotOnDisk AssetClass = "missing-on-disk-served" // Server serves it from somewhere else
+	ClassMissing         AssetClass = "missing-everywhere"     // Neither on disk nor served
+	ClassProbeFailed     AssetClass = "probe-failed"           // The server gave no definite answer
+	ClassUnvalidated     AssetClass = "not-validated"          // No server result for this hash
+)
+
+// AllClasses lists the classes in report order
+var AllClasses = []AssetClass{ClassMissing, ClassServedNotOnDisk, ClassServerError, ClassProbeFailed, ClassUnvalidated, ClassHealthy}
+
+// Description returns a short human-readable label for the class
+func (c AssetClass) Description() string {
+	switch c {
+	case ClassHealthy:
+		return "On disk and served"
+	case ClassServerError:
+		return "On disk but the server returns an error"
+	case ClassServedNotOnDisk:
+		return "Missing on disk but served (cache or second storage location)"
+	case ClassMissing:
+		return "Missing on disk and not served"
+	case ClassProbeFailed:
+		return "Server probe failed (timeout, connection error or server error), so its state is unknown"
+	default:
+		return "Not validated against the server"
+	}
+}
+
+// RecommendedAction returns what an operator should do about assets in this class
+func (c AssetClass) RecommendedAction() string {
+	switch c {
+	case ClassHealthy:
+		return "None"
+	case ClassServerError:
+		return "Check file permissions and integrity; compare with a backup copy and replace if corrupt"
+	case ClassServedNotOnDisk:
+		return "Locate the copy the server is serving (see dual asset storage investigation) and copy it into the assets folder"
+	case ClassMissing:
+		return "Restore from backup if a copy was found, otherwise re-upload the original file"
+	case ClassProbeFailed:
+		return "Re-run discovery to probe again; check the server's health and logs if it keeps failing"
+	default:
+		return "Re-run discovery with server validation enabled"
+	}
+}
+
+// Classification holds the class of every unique asset hash
+type Classification struct {
+	Classes map[string]AssetClass `json:"classes"` // Hash -> class
+	Counts  map[AssetClass]int    `json:"counts"`
+}
+
+// ClassifyAssets joins the assets folder scan and the server validation per hash.
+// validation may be nil if the server was not probed.
+//
+// Only a 404 counts as the server saying an asset is missing. A probe that got no response, or
+// an error response for a file that is not on disk, proves nothing and is classed as probe-failed.
+// An error response for a file that is on disk means the server fails to serve it.
+func ClassifyAssets(assets []canvus.AssetInfo, scanResult *filesystem.ScanResult, validation *canvus.ServerValidationResult) *Classification {
+	classification := &Classification{
+		Classes: make(map[string]AssetClass),
+		Counts:  make(map[AssetClass]int),
+	}
+
+	for _, asset := range assets {
+		if _, done := classification.Classes[asset.Hash]; done || asset.Hash == "" {
+			continue
+		}
+
+		onDisk := false
+		if scanResult != nil {
+			_, onDisk = scanResult.HashMap[asset.Hash]
+		}
+
+		class := ClassUnvalidated
+		if validation != nil {
+			if status, probed := validation.Assets[asset.Hash]; probed {
+				class = classify(onDisk, status)
+			}
+		}
+
+		classification.Classes[asset.Hash] = class
+		classification.Counts[class]++
+	}
+
+	return classification
+}
+
+// classify combines the disk state of one asset with its probe result
+func classify(onDisk bool, status canvus.AssetValidation) AssetClass {
+	switch status.Status {
+	case canvus.ServerStatusServed:
+		if onDisk {
+			return ClassHealthy
+		}
+		return ClassServedNotOnDisk
+	case canvus.ServerStatusMissing:
+		if onDisk {
+			return ClassServerError
+		}
+		return ClassMissing
+	default:
+		if onDisk && status.StatusCode != 0 {
+			return ClassServerError
+		}
+		return ClassProbeFailed
+	}
+}
+
+// ClassOf returns the class of a hash, or ClassUnvalidated if the classification is nil
+func (c *Classification) ClassOf(hash string) AssetClass {
+	if c == nil {
+		return ClassUnvalidated
+	}
+	if class, ok := c.Classes[hash]; ok {
+		return class
+	}
+	return ClassUnvalidated
+}
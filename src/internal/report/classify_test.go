@@ -0,0 +1,115 @@
+package report
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+	"github.com/jaypaulb/kpmg-db-solver/internal/filesystem"
+)
+
+func TestClassifyAssets(t *testing.T) {
+	tests := []struct {
+		name       string
+		onDisk     bool
+		validation *canvus.AssetValidation // nil = not probed
+		want       AssetClass
+	}{
+		{"on disk, served", true, &canvus.AssetValidation{Status: canvus.ServerStatusServed}, ClassHealthy},
+		{"on disk, 404", true, &canvus.AssetValidation{Status: canvus.ServerStatusMissing, StatusCode: 404}, ClassServerError},
+		{"on disk, 500", true, &canvus.AssetValidation{Status: canvus.ServerStatusError, StatusCode: 500}, ClassServerError},
+		{"on disk, timeout", true, &canvus.AssetValidation{Status: canvus.ServerStatusError, Error: "context deadline exceeded"}, ClassProbeFailed},
+		{"on disk, not probed", true, nil, ClassUnvalidated},
+		{"missing, served", false, &canvus.AssetValidation{Status: canvus.ServerStatusServed}, ClassServedNotOnDisk},
+		{"missing, 404", false, &canvus.AssetValidation{Status: canvus.ServerStatusMissing, StatusCode: 404}, ClassMissing},
+		{"missing, 500", false, &canvus.AssetValidation{Status: canvus.ServerStatusError, StatusCode: 500}, ClassProbeFailed},
+		{"missing, connection refused", false, &canvus.AssetValidation{Status: canvus.ServerStatusError, Error: "connection refused"}, ClassProbeFailed},
+		{"missing, not probed", false, nil, ClassUnvalidated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := []canvus.AssetInfo{{Hash: "h1", WidgetType: "Image", CanvasID: "c1"}}
+
+			scan := &filesystem.ScanResult{}
+			if tt.onDisk {
+				scan.Files = []filesystem.FileInfo{{Hash: "h1", Filename: "h1.png"}}
+			}
+			scan.BuildHashMap()
+
+			validation := &canvus.ServerValidationResult{Assets: map[string]canvus.AssetValidation{}}
+			if tt.validation != nil {
+				tt.validation.Hash = "h1"
+				validation.Assets["h1"] = *tt.validation
+			}
+
+			classification := ClassifyAssets(assets, scan, validation)
+			if got := classification.ClassOf("h1"); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			if classification.Counts[tt.want] != 1 {
+				t.Errorf("expected a count of 1 for %s, got %v", tt.want, classification.Counts)
+			}
+		})
+	}
+}
+
+func TestClassifyAssetsWithoutValidation(t *testing.T) {
+	assets := []canvus.AssetInfo{{Hash: "h1"}, {Hash: "h1"}, {Hash: ""}}
+
+	classification := ClassifyAssets(assets, nil, nil)
+	if len(classification.Classes) != 1 || classification.Counts[ClassUnvalidated] != 1 {
+		t.Errorf("expected one unvalidated hash, got %+v", classification)
+	}
+}
+
+func TestEveryClassIsDescribed(t *testing.T) {
+	for _, class := range AllClasses {
+		if class != ClassUnvalidated && class.Description() == ClassUnvalidated.Description() {
+			t.Errorf("class %s has no description of its own", class)
+		}
+		if class != ClassUnvalidated && class.RecommendedAction() == ClassUnvalidated.RecommendedAction() {
+			t.Errorf("class %s has no recommended action of its own", class)
+		}
+	}
+}
+
+func TestClassificationCSVQuotesFields(t *testing.T) {
+	assets := []canvus.AssetInfo{
+		{Hash: "h1", WidgetType: "Image", CanvasID: "c1", CanvasName: "Budget, Q3", WidgetID: "w1"},
+		{Hash: "h2", WidgetType: "PDF", CanvasID: "c2", CanvasName: "Plain", WidgetID: "w2"},
+	}
+	validation := &canvus.ServerValidationResult{Assets: map[string]canvus.AssetValidation{
+		"h1": {Hash: "h1", Status: canvus.ServerStatusMissing, StatusCode: 404},
+	}}
+	folder := t.TempDir()
+	generator := NewGenerator(folder, Options{})
+	generator.SetClassification(ClassifyAssets(assets, &filesystem.ScanResult{}, validation))
+
+	if err := generator.GenerateClassificationCSV(assets); err != nil {
+		t.Fatalf("GenerateClassificationCSV: %v", err)
+	}
+
+	file, err := os.Open(filepath.Join(folder, ClassificationFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse the classification report: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d records", len(records))
+	}
+	for _, record := range records {
+		if len(record) != 7 {
+			t.Errorf("expected 7 fields, got %d: %q", len(record), record)
+		}
+	}
+	if records[1][1] != string(ClassMissing) || records[1][4] != "Budget, Q3" || records[1][6] != ClassMissing.RecommendedAction() {
+		t.Errorf("unexpected row for h1: %q", records[1])
+	}
+}
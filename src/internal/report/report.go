@@ -0,0 +1,565 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaypaulb/kpmg-db-solver/internal/backup"
+	"github.com/jaypaulb/kpmg-db-solver/internal/canvus"
+)
+
+// Report file names written to the output folder
+const (
+	DetailedReportFilename = "missing_assets_report.txt"
+	CSVReportFilename      = "missing_assets.csv"
+	ClassificationFilename = "asset_classification.csv"
+)
+
+// Backup status filter values
+const (
+	BackupStatusAny      = ""
+	BackupStatusFound    = "found"
+	BackupStatusNotFound = "not-found"
+)
+
+// Sort keys
+const (
+	SortByCanvas = "canvas"
+	SortByHash   = "hash"
+	SortByType   = "type"
+	SortByWidget = "widget"
+)
+
+// Options controls which assets are reported and in what order
+type Options struct {
+	Canvases       []string // Canvas names or IDs to include (empty = all)
+	WidgetTypes    []string // Widget types to include, case-insensitive (empty = all)
+	BackupStatus   string   // BackupStatusFound, BackupStatusNotFound or BackupStatusAny
+	Classes        []string // Asset classes to include, e.g. missing-everywhere (empty = all)
+	SortBy         string   // SortByCanvas (default), SortByHash, SortByType or SortByWidget
+	SourceManifest string   // Manifest the report was regenerated from, shown in the header
+}
+
+// Validate checks the filter and sort values
+func (o Options) Validate() error {
+	switch o.BackupStatus {
+	case BackupStatusAny, BackupStatusFound, BackupStatusNotFound:
+	default:
+		return fmt.Errorf("invalid backup status %q (must be %s or %s)", o.BackupStatus, BackupStatusFound, BackupStatusNotFound)
+	}
+
+	switch o.SortBy {
+	case "", SortByCanvas, SortByHash, SortByType, SortByWidget:
+	default:
+		return fmt.Errorf("invalid sort key %q (must be one of: %s)", o.SortBy,
+			strings.Join([]string{SortByCanvas, SortByHash, SortByType, SortByWidget}, ", "))
+	}
+
+	for _, class := range o.Classes {
+		valid := false
+		for _, known := range AllClasses {
+			if AssetClass(class) == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid asset class %q", class)
+		}
+	}
+
+	return nil
+}
+
+// Generator writes the detailed and CSV missing asset reports
+type Generator struct {
+	outputFolder   string
+	options        Options
+	classification *Classification
+	degraded       *canvus.DegradedResult
+}
+
+// NewGenerator creates a new report generator
+func NewGenerator(outputFolder string, opts Options) *Generator {
+	return &Generator{
+		outputFolder: outputFolder,
+		options:      opts,
+	}
+}
+
+// SetClassification attaches the per-hash asset classification to the reports
+func (g *Generator) SetClassification(classification *Classification) {
+	g.classification = classification
+}
+
+// SetDegraded attaches the lossy mipmap reconstructions to the reports
+func (g *Generator) SetDegraded(degraded *canvus.DegradedResult) {
+	g.degraded = degraded
+}
+
+// degradedAsset returns the mipmap reconstruction of a hash, if any
+func (g *Generator) degradedAsset(hash string) *canvus.DegradedAsset {
+	if g.degraded == nil {
+		return nil
+	}
+	return g.degraded.Assets[hash]
+}
+
+// Generate selects the missing assets matching the options and writes the reports
+func (g *Generator) Generate(assets []canvus.AssetInfo, missingHashes []string, backupSearchResult *backup.SearchResult) error {
+	missingAssets := g.SelectMissingAssets(assets, missingHashes, backupSearchResult)
+
+	// Generate detailed report
+	err := g.GenerateDetailedReport(missingAssets, backupSearchResult)
+	if err != nil {
+		return fmt.Errorf("failed to generate detailed report: %w", err)
+	}
+
+	// Generate CSV report
+	err = g.GenerateCSVReport(missingAssets, backupSearchResult)
+	if err != nil {
+		return fmt.Errorf("failed to generate CSV report: %w", err)
+	}
+
+	// Generate classification CSV covering every asset, not only the missing ones
+	if g.classification != nil {
+		err = g.GenerateClassificationCSV(assets)
+		if err != nil {
+			return fmt.Errorf("failed to generate classification report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SelectMissingAssets filters all discovered assets down to the missing ones that match the options,
+// deduplicated by hash and sorted
+func (g *Generator) SelectMissingAssets(assets []canvus.AssetInfo, missingHashes []string, backupSearchResult *backup.SearchResult) []canvus.AssetInfo {
+	missingMap := make(map[string]bool, len(missingHashes))
+	for _, hash := range missingHashes {
+		missingMap[hash] = true
+	}
+
+	seen := make(map[string]bool)
+	selected := make([]canvus.AssetInfo, 0)
+	for _, asset := range assets {
+		if !missingMap[asset.Hash] || seen[asset.Hash] {
+			continue
+		}
+		if !g.matches(asset, backupSearchResult) {
+			continue
+		}
+		seen[asset.Hash] = true
+		selected = append(selected, asset)
+	}
+
+	g.sortAssets(selected)
+	return selected
+}
+
+// matches reports whether an asset passes the canvas, widget type and backup status filters
+func (g *Generator) matches(asset canvus.AssetInfo, backupSearchResult *backup.SearchResult) bool {
+	if len(g.options.Canvases) > 0 {
+		found := false
+		for _, canvas := range g.options.Canvases {
+			if canvas == asset.CanvasID || strings.EqualFold(canvas, asset.CanvasName) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(g.options.WidgetTypes) > 0 {
+		found := false
+		for _, widgetType := range g.options.WidgetTypes {
+			if strings.EqualFold(widgetType, asset.WidgetType) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(g.options.Classes) > 0 {
+		found := false
+		for _, class := range g.options.Classes {
+			if AssetClass(class) == g.classification.ClassOf(asset.Hash) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	switch g.options.BackupStatus {
+	case BackupStatusFound:
+		return len(bestBackups(backupSearchResult, asset.Hash)) > 0
+	case BackupStatusNotFound:
+		return len(bestBackups(backupSearchResult, asset.Hash)) == 0
+	}
+
+	return true
+}
+
+// sortAssets orders assets by the configured sort key, falling back to canvas then hash
+func (g *Generator) sortAssets(assets []canvus.AssetInfo) {
+	key := func(asset canvus.AssetInfo) string {
+		switch g.options.SortBy {
+		case SortByHash:
+			return asset.Hash
+		case SortByType:
+			return asset.WidgetType
+		case SortByWidget:
+			return asset.WidgetName
+		default:
+			return asset.CanvasName
+		}
+	}
+
+	sort.SliceStable(assets, func(i, j int) bool {
+		if ki, kj := key(assets[i]), key(assets[j]); ki != kj {
+			return ki < kj
+		}
+		if assets[i].CanvasName != assets[j].CanvasName {
+			return assets[i].CanvasName < assets[j].CanvasName
+		}
+		return assets[i].Hash < assets[j].Hash
+	})
+}
+
+// GenerateDetailedReport generates a detailed text report grouped by canvas
+func (g *Generator) GenerateDetailedReport(missingAssets []canvus.AssetInfo, backupSearchResult *backup.SearchResult) error {
+	reportPath := filepath.Join(g.outputFolder, DetailedReportFilename)
+
+	// Group assets by canvas, keeping canvases in the order they first appear
+	canvasOrder := make([]string, 0)
+	canvasMap := make(map[string][]canvus.AssetInfo)
+	for _, asset := range missingAssets {
+		if _, exists := canvasMap[asset.CanvasName]; !exists {
+			canvasOrder = append(canvasOrder, asset.CanvasName)
+		}
+		canvasMap[asset.CanvasName] = append(canvasMap[asset.CanvasName], asset)
+	}
+
+	// Generate report content
+	content := fmt.Sprintf("KPMG DB Solver - Missing Assets Report (Non-Admin Version)\n")
+	content += fmt.Sprintf("Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	if g.options.SourceManifest != "" {
+		content += fmt.Sprintf("Source Manifest: %s\n", g.options.SourceManifest)
+	}
+	if filters := g.describeFilters(); filters != "" {
+		content += fmt.Sprintf("Filters: %s\n", filters)
+	}
+	content += fmt.Sprintf("Total Missing Assets: %d\n", len(missingAssets))
+
+	// Add backup summary
+	if backupSearchResult != nil {
+		content += fmt.Sprintf("Assets Found in Backup: %d\n", len(backupSearchResult.FoundFiles))
+		content += fmt.Sprintf("Assets Still Missing: %d\n", len(backupSearchResult.MissingHashes))
+		if backupSearchResult.Verified {
+			content += fmt.Sprintf("Corrupt Backups Rejected: %d\n", countRejected(backupSearchResult))
+		} else {
+			content += "Backup Verification: skipped (candidates were not checked for corruption)\n"
+		}
+	}
+
+	// Add classification summary with recommended actions
+	if g.classification != nil {
+		content += "\nAsset Classification (database reference vs assets folder vs server):\n"
+		for _, class := range AllClasses {
+			count := g.classification.Counts[class]
+			if count == 0 {
+				continue
+			}
+			content += fmt.Sprintf("  %s: %d - %s\n", class, count, class.Description())
+			content += fmt.Sprintf("    Recommended Action: %s\n", class.RecommendedAction())
+		}
+		content += fmt.Sprintf("  Full per-asset classification: %s\n", ClassificationFilename)
+	}
+	content += fmt.Sprintf("\nNote: This is a read-only version. Asset restoration requires administrator privileges.\n\n")
+
+	for _, canvasName := range canvasOrder {
+		assets := canvasMap[canvasName]
+		content += fmt.Sprintf("Canvas: %s (ID: %s)\n", canvasName, assets[0].CanvasID)
+		for _, asset := range assets {
+			content += fmt.Sprintf("  Widget: %s (ID: %s, Type: %s)\n", asset.WidgetName, asset.WidgetID, asset.WidgetType)
+			content += fmt.Sprintf("    Hash: %s\n", asset.Hash)
+			if asset.OriginalFilename != "" {
+				content += fmt.Sprintf("    Original Filename: %s\n", asset.OriginalFilename)
+			}
+			if g.classification != nil {
+				content += fmt.Sprintf("    Classification: %s\n", g.classification.ClassOf(asset.Hash).Description())
+			}
+
+			// Add backup status with enhanced information
+			if backupSearchResult != nil {
+				if backupFiles := bestBackups(backupSearchResult, asset.Hash); len(backupFiles) > 0 {
+					bestBackup := backupFiles[0] // From the newest backup
+					content += fmt.Sprintf("    Backup Status: ✅ Found in backup\n")
+					content += fmt.Sprintf("    Backup Path: %s\n", bestBackup.Path)
+					content += fmt.Sprintf("    Backup Root: %s\n", bestBackup.Root)
+					if bestBackup.Source != "" {
+						content += fmt.Sprintf("    Recovery Source: %s\n", bestBackup.Source)
+					}
+					content += fmt.Sprintf("    Backup Generation: %s\n", bestBackup.DescribeGeneration())
+					content += fmt.Sprintf("    Backup Size: %d bytes (%.2f MB)\n", bestBackup.Size, float64(bestBackup.Size)/(1024*1024))
+					content += fmt.Sprintf("    Backup Modified: %s\n", bestBackup.ModifiedTime.Format("2006-01-02 15:04:05"))
+					content += fmt.Sprintf("    Backup Count: %d versions found\n", len(backupFiles))
+					if len(backupFiles) > 1 {
+						content += fmt.Sprintf("    All Backup Locations:\n")
+						for i, backupFile := range backupFiles {
+							content += fmt.Sprintf("      %d. [%s] %s (Generation: %s, Size: %d bytes)\n",
+								i+1, backupFile.Root, backupFile.Path,
+								backupFile.DescribeGeneration(),
+								backupFile.Size)
+						}
+					}
+				} else if len(rejectedBackups(backupSearchResult, asset.Hash)) > 0 {
+					content += fmt.Sprintf("    Backup Status: ⚠️  Only corrupt backups found\n")
+					content += fmt.Sprintf("    Action Required: Manual investigation needed\n")
+				} else {
+					content += fmt.Sprintf("    Backup Status: ❌ Not found in any backup\n")
+					content += fmt.Sprintf("    Action Required: Manual investigation needed\n")
+				}
+				if rejected := rejectedBackups(backupSearchResult, asset.Hash); len(rejected) > 0 {
+					content += fmt.Sprintf("    Rejected Backups (corrupt, will not be restored):\n")
+					for _, backupFile := range rejected {
+						content += fmt.Sprintf("      - [%s] %s: %s\n",
+							backupFile.Root, backupFile.Path, strings.Join(backupFile.Problems, "; "))
+					}
+				}
+			}
+			if degraded := g.degradedAsset(asset.Hash); degraded != nil {
+				content += fmt.Sprintf("    Degraded Recovery: ⚠️  LOSSY - rebuilt from server mipmaps, not the original file\n")
+				content += fmt.Sprintf("    Review Before Use: %d of %d pages in %s\n", len(degraded.Pages), degraded.PageCount, filepath.Dir(degraded.Pages[0].Path))
+				for _, page := range degraded.Pages {
+					content += fmt.Sprintf("      - Page %d: %s (mipmap level %d, about %dx%d)\n", page.Page, page.Path, page.Level, page.Width, page.Height)
+				}
+			}
+			content += "\n"
+		}
+	}
+
+	// Write report to file
+	err := writeFile(reportPath, content)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📄 Detailed report saved to: %s\n", reportPath)
+	return nil
+}
+
+// GenerateCSVReport generates a CSV report
+func (g *Generator) GenerateCSVReport(missingAssets []canvus.AssetInfo, backupSearchResult *backup.SearchResult) error {
+	reportPath := filepath.Join(g.outputFolder, CSVReportFilename)
+
+	// Generate CSV content with enhanced backup information
+	content := "Hash,WidgetType,OriginalFilename,CanvasID,CanvasName,WidgetID,WidgetName,BackupStatus,BackupPath,BackupSize,BackupModified,BackupCount,AllBackupPaths,Classification,BackupGeneration,BackupVersion,BackupTime,BackupRoot,RejectedBackups,RecoverySource,DegradedRecovery\n"
+
+	for _, asset := range missingAssets {
+		backupStatus := "Not Found"
+		backupPath := ""
+		backupSize := ""
+		backupModified := ""
+		backupCount := "0"
+		allBackupPaths := ""
+		backupGeneration := ""
+		backupVersion := ""
+		backupTime := ""
+		backupRoot := ""
+		rejectedPaths := ""
+		recoverySource := ""
+		degradedRecovery := ""
+
+		if backupFiles := bestBackups(backupSearchResult, asset.Hash); len(backupFiles) > 0 {
+			bestBackup := backupFiles[0] // From the newest backup
+			backupStatus = "Found"
+			backupPath = bestBackup.Path
+			backupSize = fmt.Sprintf("%d", bestBackup.Size)
+			backupModified = bestBackup.ModifiedTime.Format("2006-01-02 15:04:05")
+			backupCount = fmt.Sprintf("%d", len(backupFiles))
+			backupGeneration = bestBackup.Generation
+			backupVersion = bestBackup.Version
+			backupRoot = bestBackup.Root
+			recoverySource = bestBackup.Source
+			if !bestBackup.BackupTime.IsZero() {
+				backupTime = bestBackup.BackupTime.Format("2006-01-02 15:04:05")
+			}
+
+			// Create semicolon-separated list of all backup paths
+			allPaths := make([]string, len(backupFiles))
+			for i, backupFile := range backupFiles {
+				allPaths[i] = backupFile.Path
+			}
+			allBackupPaths = strings.Join(allPaths, ";")
+		}
+
+		// Create semicolon-separated list of corrupt backups with their problems
+		if rejected := rejectedBackups(backupSearchResult, asset.Hash); len(rejected) > 0 {
+			if len(bestBackups(backupSearchResult, asset.Hash)) == 0 {
+				backupStatus = "Corrupt"
+			}
+			entries := make([]string, len(rejected))
+			for i, backupFile := range rejected {
+				entries[i] = backupFile.Path + ": " + strings.Join(backupFile.Problems, "|")
+			}
+			rejectedPaths = strings.Join(entries, ";")
+		}
+
+		// Lossy reconstructions are marked so they are never mistaken for the original
+		if degraded := g.degradedAsset(asset.Hash); degraded != nil {
+			paths := make([]string, len(degraded.Pages))
+			for i, page := range degraded.Pages {
+				paths[i] = page.Path
+			}
+			degradedRecovery = "LOSSY: " + strings.Join(paths, ";")
+		}
+
+		content += fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s\n",
+			asset.Hash,
+			asset.WidgetType,
+			asset.OriginalFilename,
+			asset.CanvasID,
+			asset.CanvasName,
+			asset.WidgetID,
+			asset.WidgetName,
+			backupStatus,
+			backupPath,
+			backupSize,
+			backupModified,
+			backupCount,
+			allBackupPaths,
+			g.classification.ClassOf(asset.Hash),
+			backupGeneration,
+			backupVersion,
+			backupTime,
+			backupRoot,
+			rejectedPaths,
+			recoverySource,
+			degradedRecovery,
+		)
+	}
+
+	// Write CSV to file
+	err := writeFile(reportPath, content)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📊 CSV report saved to: %s\n", reportPath)
+	return nil
+}
+
+// GenerateClassificationCSV writes the class and recommended action of every unique asset
+func (g *Generator) GenerateClassificationCSV(assets []canvus.AssetInfo) error {
+	reportPath := filepath.Join(g.outputFolder, ClassificationFilename)
+
+	// Recommended actions and canvas names contain commas, so fields are quoted as needed
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"Hash", "Classification", "WidgetType", "CanvasID", "CanvasName", "WidgetID", "RecommendedAction"})
+
+	seen := make(map[string]bool)
+	for _, asset := range assets {
+		if seen[asset.Hash] || asset.Hash == "" {
+			continue
+		}
+		seen[asset.Hash] = true
+
+		class := g.classification.ClassOf(asset.Hash)
+		writer.Write([]string{
+			asset.Hash,
+			string(class),
+			asset.WidgetType,
+			asset.CanvasID,
+			asset.CanvasName,
+			asset.WidgetID,
+			class.RecommendedAction(),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to encode classification report: %w", err)
+	}
+
+	err := writeFile(reportPath, buf.String())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧭 Classification report saved to: %s\n", reportPath)
+	return nil
+}
+
+// describeFilters returns a one-line summary of the active filters, or "" if none are set
+func (g *Generator) describeFilters() string {
+	var parts []string
+	if len(g.options.Canvases) > 0 {
+		parts = append(parts, "canvas="+strings.Join(g.options.Canvases, "|"))
+	}
+	if len(g.options.WidgetTypes) > 0 {
+		parts = append(parts, "type="+strings.Join(g.options.WidgetTypes, "|"))
+	}
+	if g.options.BackupStatus != BackupStatusAny {
+		parts = append(parts, "backup="+g.options.BackupStatus)
+	}
+	if len(g.options.Classes) > 0 {
+		parts = append(parts, "class="+strings.Join(g.options.Classes, "|"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// bestBackups returns the backup files for a hash (preferred first), or nil if there are none
+func bestBackups(backupSearchResult *backup.SearchResult, hash string) []backup.BackupFile {
+	if backupSearchResult == nil {
+		return nil
+	}
+	return backupSearchResult.FoundFiles[hash]
+}
+
+// rejectedBackups returns the corrupt backup files found for a hash, or nil if there are none
+func rejectedBackups(backupSearchResult *backup.SearchResult, hash string) []backup.BackupFile {
+	if backupSearchResult == nil {
+		return nil
+	}
+	return backupSearchResult.Rejected[hash]
+}
+
+// countRejected returns the number of corrupt backup files rejected during verification
+func countRejected(backupSearchResult *backup.SearchResult) int {
+	count := 0
+	for _, files := range backupSearchResult.Rejected {
+		count += len(files)
+	}
+	return count
+}
+
+// writeFile writes content to a file
+func writeFile(filename, content string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(content)
+	if err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", filename, err)
+	}
+
+	return nil
+}
@@ -3,6 +3,9 @@ package canvus
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 )
 
 // GetMipmapInfo retrieves mipmap information for a given asset hash and page.
@@ -48,3 +51,47 @@ func (s *Session) GetAssetByHash(ctx context.Context, canvasID, publicHashHex st
 	}
 	return data, nil
 }
+
+// OpenAssetByHash streams an asset file by its hash, for files too large to hold in memory.
+// It uses the same endpoint as GetAssetByHash; the caller must close the returned body.
+// Requires 'canvas-id' and 'Private-Token' headers.
+func (s *Session) OpenAssetByHash(ctx context.Context, canvasID, publicHashHex string) (io.ReadCloser, *AssetStat, error) {
+	path := fmt.Sprintf("assets/%s", publicHashHex)
+	resp, err := s.openRequestWithHeaders(ctx, "GET", path, map[string]string{"canvas-id": canvasID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("OpenAssetByHash: %w", err)
+	}
+	return resp.Body, &AssetStat{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+}
+
+// AssetStat describes an asset file on the server without its content.
+type AssetStat struct {
+	Size        int64  // Total file size in bytes, or -1 if the server did not report it
+	ContentType string // MIME type reported by the server
+}
+
+// StatAssetByHash checks that an asset file is served without downloading it.
+// It sends a ranged GET for the first byte and closes the response without reading the body,
+// so it is safe to use on multi-GB videos. Requires 'canvas-id' and 'Private-Token' headers.
+func (s *Session) StatAssetByHash(ctx context.Context, canvasID, publicHashHex string) (*AssetStat, error) {
+	path := fmt.Sprintf("assets/%s", publicHashHex)
+	resp, err := s.openRequestWithHeaders(ctx, "GET", path, map[string]string{
+		"canvas-id": canvasID,
+		"Range":     "bytes=0-0",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("StatAssetByHash: %w", err)
+	}
+	resp.Body.Close()
+
+	stat := &AssetStat{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}
+	// A 206 response carries the full size in Content-Range: bytes 0-0/12345
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
+		if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+			if total, err := strconv.ParseInt(contentRange[i+1:], 10, 64); err == nil {
+				stat.Size = total
+			}
+		}
+	}
+	return stat, nil
+}
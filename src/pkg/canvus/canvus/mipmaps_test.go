@@ -0,0 +1,44 @@
+package canvus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMipmapResponsesAreDecodedFromBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("canvas-id") != "c1" {
+			http.Error(w, "missing canvas-id", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/mipmaps/abc":
+			w.Write([]byte(`{"resolution":{"width":640,"height":480},"max_level":3,"pages":1}`))
+		case "/mipmaps/abc/0":
+			w.Write([]byte("RIFF webp"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	session := NewSession(server.URL)
+
+	info, err := session.GetMipmapInfo(context.Background(), "c1", "abc", nil)
+	if err != nil {
+		t.Fatalf("GetMipmapInfo: %v", err)
+	}
+	if info.MaxLevel != 3 || info.Resolution.Width != 640 {
+		t.Errorf("unexpected mipmap info: %+v", info)
+	}
+
+	data, err := session.GetMipmapLevel(context.Background(), "c1", "abc", 0, nil)
+	if err != nil {
+		t.Fatalf("GetMipmapLevel: %v", err)
+	}
+	if string(data) != "RIFF webp" {
+		t.Errorf("expected the level's bytes, got %q", data)
+	}
+}
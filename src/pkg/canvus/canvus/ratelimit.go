@@ -0,0 +1,133 @@
+package canvus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestLimiter paces the requests a Session sends.
+// Wait is called before every HTTP request (including retries) and Observe after its response,
+// so adaptive implementations can slow down when the server struggles.
+type RequestLimiter interface {
+	// Wait blocks until the next request may be sent or ctx is done.
+	Wait(ctx context.Context) error
+	// Observe reports the outcome of a request. statusCode is 0 if no response was received.
+	Observe(statusCode int, latency time.Duration, err error)
+}
+
+// WithRateLimit limits the session to requestsPerSecond requests, spread evenly over each second.
+func WithRateLimit(requestsPerSecond float64) SessionOption {
+	return func(s *Session) {
+		if requestsPerSecond > 0 {
+			s.limiter = &fixedRateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+		}
+	}
+}
+
+// WithRequestLimiter paces the session's requests with a custom limiter, e.g. one that adapts to server load.
+func WithRequestLimiter(limiter RequestLimiter) SessionOption {
+	return func(s *Session) {
+		s.limiter = limiter
+	}
+}
+
+// WithMaxInFlight limits the number of requests the session has open at the same time.
+// A streamed download holds its slot until its body is closed.
+func WithMaxInFlight(n int) SessionOption {
+	return func(s *Session) {
+		if n > 0 {
+			s.inFlight = make(chan struct{}, n)
+		}
+	}
+}
+
+// fixedRateLimiter spaces requests a fixed interval apart.
+type fixedRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// Wait reserves the next send time and sleeps until it.
+func (l *fixedRateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Observe is a no-op; the rate is fixed.
+func (l *fixedRateLimiter) Observe(int, time.Duration, error) {}
+
+// send performs a request under the session's rate limit and in-flight cap.
+// The returned release function must be called once the response body has been consumed.
+func (s *Session) send(req *http.Request) (*http.Response, func(), error) {
+	ctx := req.Context()
+
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, func() {}, err
+		}
+	}
+
+	release := func() {}
+	if s.inFlight != nil {
+		select {
+		case s.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, release, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-s.inFlight }) }
+	}
+
+	start := time.Now()
+	resp, err := s.HTTPClient.Do(req)
+
+	// A request cancelled by the caller says nothing about the server's health
+	if s.limiter != nil && ctx.Err() == nil {
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		s.limiter.Observe(statusCode, time.Since(start), err)
+	}
+
+	if err != nil {
+		release()
+		return nil, func() {}, err
+	}
+	return resp, release, nil
+}
+
+// releasingBody frees the request's in-flight slot when a streamed body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,147 @@
+package canvus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithMaxInFlight(t *testing.T) {
+	var current, peak atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	session := NewSession(server.URL, WithMaxInFlight(2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out map[string]interface{}
+			if err := session.doRequest(context.Background(), http.MethodGet, "ping", nil, &out, nil, false); err != nil {
+				t.Errorf("doRequest: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("expected at most 2 requests in flight, saw %d", got)
+	}
+}
+
+func TestWithMaxInFlightHoldsSlotUntilStreamClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	session := NewSession(server.URL, WithMaxInFlight(1))
+
+	resp, err := session.openRequestWithHeaders(context.Background(), http.MethodGet, "assets/x", nil)
+	if err != nil {
+		t.Fatalf("openRequestWithHeaders: %v", err)
+	}
+
+	// The only slot is held by the open stream
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := session.openRequestWithHeaders(ctx, http.MethodGet, "assets/y", nil); err == nil {
+		t.Fatal("expected the second request to wait for the open stream")
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	resp, err = session.openRequestWithHeaders(context.Background(), http.MethodGet, "assets/z", nil)
+	if err != nil {
+		t.Fatalf("expected a free slot after closing the stream: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestWithRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	session := NewSession(server.URL, WithRateLimit(20))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		var out map[string]interface{}
+		if err := session.doRequest(context.Background(), http.MethodGet, "ping", nil, &out, nil, false); err != nil {
+			t.Fatalf("doRequest: %v", err)
+		}
+	}
+
+	// Five requests at 20/sec are spaced 50ms apart
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("expected at least 200ms for 5 requests at 20/sec, took %v", elapsed)
+	}
+}
+
+// recordingLimiter counts waits and records observed status codes
+type recordingLimiter struct {
+	mu       sync.Mutex
+	waits    int
+	statuses []int
+}
+
+func (l *recordingLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waits++
+	return nil
+}
+
+func (l *recordingLimiter) Observe(statusCode int, latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.statuses = append(l.statuses, statusCode)
+}
+
+func TestWithRequestLimiterSeesRetries(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	limiter := &recordingLimiter{}
+	session := NewSession(server.URL, WithRequestLimiter(limiter))
+
+	var out map[string]interface{}
+	if err := session.doRequest(context.Background(), http.MethodGet, "ping", nil, &out, nil, false); err != nil {
+		t.Fatalf("doRequest: %v", err)
+	}
+
+	if limiter.waits != 3 {
+		t.Errorf("expected every attempt to wait for the limiter, got %d waits", limiter.waits)
+	}
+	if len(limiter.statuses) != 3 || limiter.statuses[0] != 503 || limiter.statuses[2] != 200 {
+		t.Errorf("unexpected observed statuses: %v", limiter.statuses)
+	}
+}
@@ -85,7 +85,9 @@ type Session struct {
 	BaseURL       string
 	HTTPClient    *http.Client
 	authenticator Authenticator
-	userID        int64 // ID of the authenticated user, if available
+	userID        int64          // ID of the authenticated user, if available
+	limiter       RequestLimiter // Paces requests (nil = unlimited)
+	inFlight      chan struct{}  // Caps concurrent requests (nil = unlimited)
 }
 
 // NewSession creates a new Canvus API session.
@@ -159,10 +161,10 @@ func (s *Session) doRequest(ctx context.Context, method, endpoint string, body i
 			req.Header.Set("Content-Type", ct)
 		}
 
-		resp, err := s.HTTPClient.Do(req)
+		resp, release, err := s.send(req)
 		if err != nil {
 			lastErr = err
-			if attempt < maxRetries-1 {
+			if attempt < maxRetries-1 && ctx.Err() == nil {
 				time.Sleep(time.Duration(1<<attempt) * 100 * time.Millisecond)
 				continue
 			}
@@ -171,6 +173,7 @@ func (s *Session) doRequest(ctx context.Context, method, endpoint string, body i
 		defer resp.Body.Close()
 
 		respBody, _ := io.ReadAll(resp.Body)
+		release()
 
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			lastErr = &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
@@ -425,32 +428,30 @@ func (s *Session) doRequestWithHeaders(ctx context.Context, method, endpoint str
 		req.Header.Set(k, v)
 	}
 
-	resp, err := s.HTTPClient.Do(req)
+	resp, release, err := s.send(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	respBody, _ := io.ReadAll(resp.Body)
+	release()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
 	}
 
 	if out != nil {
+		// The body has already been read above; decode from that buffer
 		if rawResponse {
 			// out must be *[]byte
-			b, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return err
-			}
 			if ptr, ok := out.(*[]byte); ok {
-				*ptr = b
+				*ptr = respBody
 			} else {
 				return errors.New("out must be *[]byte when rawResponse is true")
 			}
 		} else {
-			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			if err := json.NewDecoder(bytes.NewReader(respBody)).Decode(out); err != nil {
 				return err
 			}
 		}
@@ -458,6 +459,44 @@ func (s *Session) doRequestWithHeaders(ctx context.Context, method, endpoint str
 	return nil
 }
 
+// openRequestWithHeaders sends a request and returns the response without reading its body,
+// so large downloads can be streamed or abandoned early. The caller must close resp.Body.
+// Non-2xx responses are returned as *APIError with the body already closed.
+func (s *Session) openRequestWithHeaders(ctx context.Context, method, endpoint string, headers map[string]string) (*http.Response, error) {
+	u, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.authenticator != nil {
+		s.authenticator.Authenticate(req)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, release, err := s.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer release()
+		defer resp.Body.Close()
+		// Error bodies are short; cap the read in case the server sends a file anyway
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
 // toString converts an interface{} to string for query param values.
 func toString(v interface{}) string {
 	switch val := v.(type) {
@@ -37,7 +37,11 @@ func main() {
 	}
 
 	// Create a new Canvus session
-	session := canvus.NewSession(apiBaseURL, canvus.WithAPIKey(apiKey))
+	session := canvus.NewSession(apiBaseURL,
+		canvus.WithAPIKey(apiKey),
+		canvus.WithRateLimit(25),
+		canvus.WithMaxInFlight(4),
+	)
 	ctx := context.Background()
 
 	log.Printf("Connecting to Canvus API at %s", apiBaseURL)